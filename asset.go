@@ -0,0 +1,148 @@
+package github
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/google/go-github/v35/github"
+	"github.com/spf13/afero"
+)
+
+// maxAssetNameLength is the maximum length of a file name on most filesystems.
+const maxAssetNameLength = 255
+
+var (
+	// ErrInvalidAssetName indicates that the name of the asset is not safe to be used as a file name.
+	ErrInvalidAssetName = errors.New("invalid asset name")
+	// ErrAssetTooLarge indicates that the asset is larger than the maximum size.
+	ErrAssetTooLarge = errors.New("asset too large")
+	// ErrInsufficientSpace indicates that there is not enough free space to download the asset.
+	ErrInsufficientSpace = errors.New("insufficient space")
+)
+
+// assetNamePattern matches the names that are safe to be used as a file name. A name must not start with a dot or a
+// dash, and must not contain a path separator.
+var assetNamePattern = regexp.MustCompile(`^[A-Za-z0-9_+~@][A-Za-z0-9._+~@-]*$`)
+
+// validateAssetName checks whether the name of the asset is safe to be used as a file name.
+func validateAssetName(name string) error {
+	if len(name) > maxAssetNameLength || !assetNamePattern.MatchString(name) {
+		return fmt.Errorf("%w %q", ErrInvalidAssetName, name)
+	}
+
+	return nil
+}
+
+// checkAsset checks the asset before writing it into the directories.
+func (i *Installer) checkAsset(asset *github.ReleaseAsset, dirs ...string) error {
+	if err := validateAssetName(asset.GetName()); err != nil {
+		return err
+	}
+
+	size := int64(asset.GetSize())
+
+	if i.maxAssetSize > 0 && size > i.maxAssetSize {
+		return fmt.Errorf("%w: %d bytes, the maximum is %d bytes", ErrAssetTooLarge, size, i.maxAssetSize)
+	}
+
+	for _, dir := range dirs {
+		if free, ok := availableSpace(i.fs, dir); ok && size > 0 && uint64(size) > free {
+			return fmt.Errorf("%w: %d bytes needed, %d bytes available in %s", ErrInsufficientSpace, size, free, dir)
+		}
+	}
+
+	return nil
+}
+
+// downloadCopier returns the copyFunc for downloading an asset.
+func (i *Installer) downloadCopier(copyFn copyFunc) copyFunc {
+	if i.maxAssetSize <= 0 {
+		return copyFn
+	}
+
+	return func(dst io.Writer, src io.Reader) (int64, error) {
+		return copyFn(&sizeLimitWriter{w: dst, max: i.maxAssetSize, remaining: i.maxAssetSize}, src)
+	}
+}
+
+// decompressCopier returns the copyFunc for decompressing an asset. The compressed size says nothing about the
+// decompressed size, so it has its own limit.
+func (i *Installer) decompressCopier(copyFn copyFunc) copyFunc {
+	if i.maxDecompressedSize <= 0 {
+		return copyFn
+	}
+
+	return func(dst io.Writer, src io.Reader) (int64, error) {
+		return copyFn(&sizeLimitWriter{w: dst, max: i.maxDecompressedSize, remaining: i.maxDecompressedSize, decompressed: true}, src)
+	}
+}
+
+// availableSpace returns the free space of the directory if the filesystem is on the disk. A directory that does not
+// exist yet, for example the destination, is created on the filesystem of its nearest parent that exists.
+func availableSpace(fs afero.Fs, dir string) (uint64, bool) {
+	path, ok := realPath(fs, dir)
+	if !ok {
+		return 0, false
+	}
+
+	for {
+		if _, err := os.Stat(path); err == nil {
+			return diskFree(path)
+		}
+
+		parent := filepath.Dir(path)
+		if parent == path {
+			return 0, false
+		}
+
+		path = parent
+	}
+}
+
+// realPath returns the path on the disk if the filesystem is on the disk.
+func realPath(fs afero.Fs, path string) (string, bool) {
+	switch fs := fs.(type) {
+	case *afero.OsFs:
+		return path, true
+
+	case *afero.BasePathFs:
+		path, err := fs.RealPath(path)
+		if err != nil {
+			return "", false
+		}
+
+		return path, true
+	}
+
+	return "", false
+}
+
+// sizeLimitWriter is an io.Writer that fails with ErrAssetTooLarge when more than the maximum is written. The declared
+// size of an asset is not trusted, so the limit is enforced while streaming.
+type sizeLimitWriter struct {
+	w            io.Writer
+	max          int64
+	remaining    int64
+	decompressed bool
+}
+
+// Write satisfies io.Writer.
+func (w *sizeLimitWriter) Write(p []byte) (int, error) {
+	if int64(len(p)) > w.remaining {
+		if w.decompressed {
+			return 0, fmt.Errorf("%w: more than %d bytes once decompressed", ErrAssetTooLarge, w.max)
+		}
+
+		return 0, fmt.Errorf("%w: more than %d bytes", ErrAssetTooLarge, w.max)
+	}
+
+	n, err := w.w.Write(p)
+
+	w.remaining -= int64(n)
+
+	return n, err
+}
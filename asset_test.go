@@ -0,0 +1,132 @@
+package github
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v35/github"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateAssetName(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		expectedError string
+	}{
+		{name: "my-plugin-1.4.2-linux-amd64.tar.gz"},
+		{name: "my_plugin+linux~amd64@1"},
+		{name: "", expectedError: `invalid asset name ""`},
+		{name: ".", expectedError: `invalid asset name "."`},
+		{name: "..", expectedError: `invalid asset name ".."`},
+		{name: ".plugin.registry.yaml", expectedError: `invalid asset name ".plugin.registry.yaml"`},
+		{name: "-rf", expectedError: `invalid asset name "-rf"`},
+		{name: "../my-plugin", expectedError: `invalid asset name "../my-plugin"`},
+		{name: "dir/my-plugin", expectedError: `invalid asset name "dir/my-plugin"`},
+		{name: `dir\my-plugin`, expectedError: `invalid asset name "dir\\my-plugin"`},
+		{name: "my plugin", expectedError: `invalid asset name "my plugin"`},
+		{name: "my-plugin\x00", expectedError: `invalid asset name "my-plugin\x00"`},
+		{name: strings.Repeat("a", 256), expectedError: `invalid asset name "` + strings.Repeat("a", 256) + `"`},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateAssetName(tc.name)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+				assert.True(t, errors.Is(err, ErrInvalidAssetName))
+			}
+		})
+	}
+}
+
+func TestInstaller_CheckAsset(t *testing.T) {
+	t.Parallel()
+
+	newAsset := func(size int) *github.ReleaseAsset {
+		return &github.ReleaseAsset{Name: github.String("my-plugin"), Size: github.Int(size)}
+	}
+
+	i := &Installer{fs: afero.NewMemMapFs(), maxAssetSize: 1024}
+
+	assert.NoError(t, i.checkAsset(newAsset(1024), "/tmp"))
+
+	err := i.checkAsset(newAsset(1025), "/tmp")
+
+	assert.EqualError(t, err, "asset too large: 1025 bytes, the maximum is 1024 bytes")
+	assert.True(t, errors.Is(err, ErrAssetTooLarge))
+
+	if runtime.GOOS != "linux" && runtime.GOOS != "darwin" {
+		return
+	}
+
+	i = &Installer{fs: afero.NewOsFs()}
+
+	err = i.checkAsset(newAsset(int(^uint(0)>>1)), t.TempDir())
+
+	assert.True(t, errors.Is(err, ErrInsufficientSpace))
+
+	// The destination does not exist yet, the space of its nearest parent on the base path is checked.
+	i = &Installer{fs: afero.NewBasePathFs(afero.NewOsFs(), t.TempDir())}
+
+	assert.NoError(t, i.checkAsset(newAsset(1024), "/tmp", "/plugins/my-plugin"))
+
+	err = i.checkAsset(newAsset(int(^uint(0)>>1)), "/plugins/my-plugin")
+
+	assert.True(t, errors.Is(err, ErrInsufficientSpace))
+	assert.True(t, strings.HasSuffix(err.Error(), " bytes available in /plugins/my-plugin"))
+}
+
+func TestInstaller_DownloadCopier(t *testing.T) {
+	t.Parallel()
+
+	i := &Installer{maxAssetSize: 4}
+	copyFn := i.downloadCopier(io.Copy)
+
+	var buf bytes.Buffer
+
+	n, err := copyFn(&buf, strings.NewReader("1234"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), n)
+
+	buf.Reset()
+
+	_, err = copyFn(&buf, strings.NewReader("12345"))
+
+	assert.EqualError(t, err, "asset too large: more than 4 bytes")
+	assert.True(t, errors.Is(err, ErrAssetTooLarge))
+}
+
+func TestInstaller_DecompressCopier(t *testing.T) {
+	t.Parallel()
+
+	i := &Installer{maxAssetSize: 2, maxDecompressedSize: 4}
+	copyFn := i.decompressCopier(io.Copy)
+
+	var buf bytes.Buffer
+
+	n, err := copyFn(&buf, strings.NewReader("1234"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), n)
+
+	buf.Reset()
+
+	_, err = copyFn(&buf, strings.NewReader("12345"))
+
+	assert.EqualError(t, err, "asset too large: more than 4 bytes once decompressed")
+	assert.True(t, errors.Is(err, ErrAssetTooLarge))
+}
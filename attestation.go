@@ -0,0 +1,468 @@
+package github
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/bool64/ctxd"
+	"github.com/google/go-github/v35/github"
+)
+
+// maxAttestationSize is the maximum size of an attestation file.
+const maxAttestationSize = 16 << 20
+
+// Predicate types of the SLSA provenance.
+const (
+	slsaProvenanceV1   = "https://slsa.dev/provenance/v1"
+	slsaProvenanceV0_2 = "https://slsa.dev/provenance/v0.2"
+)
+
+var (
+	// ErrAttestationNotFound indicates that there is no attestation for the asset in the release.
+	ErrAttestationNotFound = errors.New("attestation not found")
+	// ErrAttestationFailed indicates that the attestation of the asset could not be verified.
+	ErrAttestationFailed = errors.New("attestation verification failed")
+)
+
+// Extensions of the certificates issued by Fulcio, see
+// https://github.com/sigstore/fulcio/blob/main/docs/oid-info.md.
+var (
+	oidFulcioWorkflowRepository = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 5}
+	oidFulcioWorkflowRef        = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 6}
+	oidFulcioSourceRepoURI      = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 12}
+	oidFulcioSourceRepoRef      = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 14}
+	oidFulcioBuildConfigURI     = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 18}
+)
+
+// AttestationPolicy is the policy of the build provenance of the assets.
+//
+// The attestation of an asset is looked up in the release, as `<asset>.sigstore.json`, `<asset>.sigstore`,
+// `<asset>.intoto.jsonl` or `multiple.intoto.jsonl`. It is verified offline: the signing certificate must chain up to
+// the Roots, or the signature must be made by one of the PublicKeys. A signing certificate is short-lived, so it is
+// checked at the time of the signature, that is the integrated time of an entry of a trusted transparency log, or the
+// time of a timestamp of a trusted timestamp authority. The log entry must be the one of the signature and of the
+// certificate. An attestation that is signed with a certificate but has neither is rejected: the in-toto files have
+// neither, so the `.intoto.jsonl` of the slsa-github-generator, that are signed with a certificate, are rejected, and
+// only the in-toto files that are signed with one of the PublicKeys are accepted. Use the Sigstore bundles for the
+// attestations that are signed with a certificate.
+type AttestationPolicy struct {
+	// Roots are the trusted root certificates, for example the Sigstore public good Fulcio root.
+	Roots *x509.CertPool
+	// Intermediates are the intermediate certificates that are not in the attestation bundle.
+	Intermediates *x509.CertPool
+	// PublicKeys are the trusted keys of the attestations that are signed without a certificate.
+	PublicKeys []crypto.PublicKey
+	// TransparencyLogKeys are the public keys of the trusted transparency logs, for example the Sigstore public good
+	// Rekor. The signed entry timestamps of the log entries of a bundle are verified with them.
+	TransparencyLogKeys []crypto.PublicKey
+	// TimestampAuthorities are the trusted root certificates of the RFC 3161 timestamp authorities, for example the
+	// one of the Sigstore instance of GitHub.
+	TimestampAuthorities *x509.CertPool
+	// WorkflowPath is the path of the workflow that must have built the asset, for example
+	// `.github/workflows/release.yml`. Any workflow of the repository is accepted if it is empty.
+	WorkflowPath string
+}
+
+// WithAttestationPolicy requires an attestation of the build provenance for every downloaded asset. The asset is only
+// accepted if the attestation is signed by a trusted signer, its subject is the asset, and it was built from the tag of
+// the plugin repository, by the workflow of the policy.
+func WithAttestationPolicy(policy AttestationPolicy) Option {
+	return func(i *Installer) {
+		i.attestationPolicy = &policy
+	}
+}
+
+// buildIdentity is where an asset is built from.
+type buildIdentity struct {
+	repository   string
+	ref          string
+	workflowPath string
+}
+
+// verifyAttestation verifies the attestation of the downloaded asset, if it is required.
+func (i *Installer) verifyAttestation(ctx context.Context, plan *Plan, d *download) error {
+	if i.attestationPolicy == nil {
+		return nil
+	}
+
+	ctx, span := i.startSpan(ctx, "verify attestation", assetAttributes(plan.asset)...)
+
+	err := i.checkAttestation(ctx, plan, d.sha256)
+
+	endSpan(span, err)
+
+	if err != nil {
+		return ctxd.WrapError(ctx, err, "could not verify attestation")
+	}
+
+	i.logger.Debug(ctx, "verified attestation", "asset", plan.AssetName, "sha256", d.sha256)
+
+	return nil
+}
+
+func (i *Installer) checkAttestation(ctx context.Context, plan *Plan, digest string) error {
+	data, err := i.fetchAttestation(ctx, plan)
+	if err != nil {
+		return err
+	}
+
+	attestations, err := parseAttestations(data)
+	if err != nil {
+		return fmt.Errorf("%w: could not parse attestation: %s", ErrAttestationFailed, err.Error())
+	}
+
+	expected := buildIdentity{
+		repository:   fmt.Sprintf("https://%s/%s/%s", i.webHost(), plan.Owner, plan.Repository),
+		ref:          "refs/tags/" + plan.Tag,
+		workflowPath: i.attestationPolicy.WorkflowPath,
+	}
+
+	err = fmt.Errorf("%w: no attestation in %s", ErrAttestationNotFound, plan.AssetName)
+
+	// An attestation file could have the attestations of other assets, one of them must be valid for this asset.
+	for _, a := range attestations {
+		if err = i.attestationPolicy.verify(a, digest, expected); err == nil {
+			return nil
+		}
+	}
+
+	return err
+}
+
+// fetchAttestation downloads the attestation of the asset from the release.
+func (i *Installer) fetchAttestation(ctx context.Context, plan *Plan) ([]byte, error) {
+	names := []string{
+		plan.AssetName + ".sigstore.json",
+		plan.AssetName + ".sigstore",
+		plan.AssetName + ".intoto.jsonl",
+		"multiple.intoto.jsonl",
+	}
+
+	for _, name := range names {
+		asset, err := findAsset(plan.release, name)
+		if err != nil {
+			continue
+		}
+
+		if asset.GetSize() > maxAttestationSize {
+			return nil, fmt.Errorf("%w: %s has %d bytes", ErrAssetTooLarge, name, asset.GetSize())
+		}
+
+		return i.downloadAttestation(ctx, plan, asset)
+	}
+
+	return nil, fmt.Errorf("%w: no attestation for %s in release %s", ErrAttestationNotFound, plan.AssetName, plan.Tag)
+}
+
+func (i *Installer) downloadAttestation(ctx context.Context, plan *Plan, asset *github.ReleaseAsset) ([]byte, error) {
+	downloadCtx, cancel := i.phaseContext(ctx, PhaseDownload)
+	defer cancel()
+
+	r, _, err := i.service.DownloadReleaseAsset(downloadCtx, plan.Owner, plan.Repository, asset.GetID(), i.downloadClient)
+
+	i.observeAPICall(EndpointDownloadReleaseAsset, nil)
+
+	if err != nil {
+		return nil, ctxd.WrapError(ctx, i.phaseError(ctx, downloadCtx, PhaseDownload, err), "could not download attestation")
+	}
+
+	data, err := readAllContext(downloadCtx, io.LimitReader(r, maxAttestationSize+1))
+	if err != nil {
+		return nil, ctxd.WrapError(ctx, i.phaseError(ctx, downloadCtx, PhaseDownload, err), "could not read attestation")
+	}
+
+	if len(data) > maxAttestationSize {
+		return nil, fmt.Errorf("%w: %s has more than %d bytes", ErrAssetTooLarge, asset.GetName(), maxAttestationSize)
+	}
+
+	return data, nil
+}
+
+// verify verifies an attestation of the asset with the digest.
+func (p *AttestationPolicy) verify(a signedAttestation, digest string, expected buildIdentity) error {
+	if a.envelope.PayloadType != inTotoPayloadType {
+		return fmt.Errorf("%w: unsupported payload type %q", ErrAttestationFailed, a.envelope.PayloadType)
+	}
+
+	payload, err := decodeBase64(a.envelope.Payload)
+	if err != nil {
+		return fmt.Errorf("%w: could not decode payload: %s", ErrAttestationFailed, err.Error())
+	}
+
+	leaf, err := p.verifySignatures(a, payload)
+	if err != nil {
+		return err
+	}
+
+	var s inTotoStatement
+
+	if err := json.Unmarshal(payload, &s); err != nil {
+		return fmt.Errorf("%w: could not parse statement: %s", ErrAttestationFailed, err.Error())
+	}
+
+	if !s.hasSubject(digest) {
+		return fmt.Errorf("%w: asset with sha256 %s is not a subject of the attestation", ErrAttestationFailed, digest)
+	}
+
+	identity, err := s.buildIdentity()
+	if err != nil {
+		return err
+	}
+
+	if err := identity.match(expected, "provenance"); err != nil {
+		return err
+	}
+
+	// The claims of the statement must be backed by the identity of the signer, if it is known.
+	if leaf != nil {
+		if err := certificateIdentity(leaf).match(expected, "certificate"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// verifySignatures verifies that at least one signature of the envelope is made by a trusted signer, and returns its
+// certificate, if any.
+func (p *AttestationPolicy) verifySignatures(a signedAttestation, payload []byte) (*x509.Certificate, error) {
+	if len(a.envelope.Signatures) == 0 {
+		return nil, fmt.Errorf("%w: attestation is not signed", ErrAttestationFailed)
+	}
+
+	data := pae(a.envelope.PayloadType, payload)
+	err := fmt.Errorf("%w: no trusted signature", ErrAttestationFailed)
+
+	for _, s := range a.envelope.Signatures {
+		sig, decodeErr := decodeBase64(s.Sig)
+		if decodeErr != nil {
+			err = fmt.Errorf("%w: could not decode signature: %s", ErrAttestationFailed, decodeErr.Error())
+
+			continue
+		}
+
+		chain, chainErr := a.signatureChain(s)
+		if chainErr != nil {
+			err = fmt.Errorf("%w: %s", ErrAttestationFailed, chainErr.Error())
+
+			continue
+		}
+
+		if len(chain) == 0 {
+			if p.verifyWithKeys(data, sig) {
+				return nil, nil
+			}
+
+			continue
+		}
+
+		signedAt, timeErr := p.signingTime(a, payload, sig, chain[0])
+		if timeErr != nil {
+			err = fmt.Errorf("%w: unverified signing time: %s", ErrAttestationFailed, timeErr.Error())
+
+			continue
+		}
+
+		if chainErr := p.verifyChain(chain, signedAt); chainErr != nil {
+			err = fmt.Errorf("%w: untrusted certificate: %s", ErrAttestationFailed, chainErr.Error())
+
+			continue
+		}
+
+		if sigErr := verifySignature(chain[0].PublicKey, data, sig); sigErr != nil {
+			err = fmt.Errorf("%w: %s", ErrAttestationFailed, sigErr.Error())
+
+			continue
+		}
+
+		return chain[0], nil
+	}
+
+	return nil, err
+}
+
+func (p *AttestationPolicy) verifyWithKeys(data, sig []byte) bool {
+	for _, k := range p.PublicKeys {
+		if verifySignature(k, data, sig) == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// verifyChain verifies the certificate chain against the roots at the time of the signature.
+func (p *AttestationPolicy) verifyChain(chain []*x509.Certificate, signedAt time.Time) error {
+	if p.Roots == nil {
+		return errors.New("no trusted roots") // nolint: goerr113
+	}
+
+	leaf := chain[0]
+
+	intermediates := x509.NewCertPool()
+	if p.Intermediates != nil {
+		intermediates = p.Intermediates.Clone()
+	}
+
+	for _, c := range chain[1:] {
+		intermediates.AddCert(c)
+	}
+
+	_, err := leaf.Verify(x509.VerifyOptions{
+		Roots:         p.Roots,
+		Intermediates: intermediates,
+		CurrentTime:   signedAt,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+	})
+
+	return err
+}
+
+// inTotoStatement is an in-toto statement with a SLSA provenance predicate.
+type inTotoStatement struct {
+	Subject []struct {
+		Name   string            `json:"name"`
+		Digest map[string]string `json:"digest"`
+	} `json:"subject"`
+	PredicateType string `json:"predicateType"`
+	Predicate     struct {
+		// SLSA v1.
+		BuildDefinition struct {
+			ExternalParameters struct {
+				Workflow struct {
+					Ref        string `json:"ref"`
+					Repository string `json:"repository"`
+					Path       string `json:"path"`
+				} `json:"workflow"`
+			} `json:"externalParameters"`
+		} `json:"buildDefinition"`
+		// SLSA v0.2.
+		Invocation struct {
+			ConfigSource struct {
+				URI        string `json:"uri"`
+				EntryPoint string `json:"entryPoint"`
+			} `json:"configSource"`
+		} `json:"invocation"`
+	} `json:"predicate"`
+}
+
+func (s inTotoStatement) hasSubject(digest string) bool {
+	for _, sub := range s.Subject {
+		if strings.EqualFold(sub.Digest["sha256"], digest) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s inTotoStatement) buildIdentity() (buildIdentity, error) {
+	switch s.PredicateType {
+	case slsaProvenanceV1:
+		w := s.Predicate.BuildDefinition.ExternalParameters.Workflow
+
+		return buildIdentity{repository: w.Repository, ref: w.Ref, workflowPath: w.Path}, nil
+
+	case slsaProvenanceV0_2:
+		c := s.Predicate.Invocation.ConfigSource
+		repository, ref := splitRef(strings.TrimPrefix(c.URI, "git+"))
+
+		return buildIdentity{repository: repository, ref: ref, workflowPath: c.EntryPoint}, nil
+	}
+
+	return buildIdentity{}, fmt.Errorf("%w: unsupported predicate type %q", ErrAttestationFailed, s.PredicateType)
+}
+
+// match checks the identity against the expected one, the workflow path is not checked if it is not expected.
+func (b buildIdentity) match(expected buildIdentity, from string) error {
+	switch {
+	case !strings.EqualFold(strings.TrimSuffix(b.repository, ".git"), expected.repository):
+		return fmt.Errorf("%w: %s repository %q does not match %q", ErrAttestationFailed, from, b.repository, expected.repository)
+
+	case b.ref != expected.ref:
+		return fmt.Errorf("%w: %s ref %q does not match %q", ErrAttestationFailed, from, b.ref, expected.ref)
+
+	case expected.workflowPath != "" && b.workflowPath != expected.workflowPath:
+		return fmt.Errorf("%w: %s workflow %q does not match %q", ErrAttestationFailed, from, b.workflowPath, expected.workflowPath)
+	}
+
+	return nil
+}
+
+// certificateIdentity reads the build identity from the extensions of a certificate issued by Fulcio.
+func certificateIdentity(cert *x509.Certificate) buildIdentity {
+	var (
+		b                          buildIdentity
+		legacyRepository, buildURI string
+	)
+
+	for _, ext := range cert.Extensions {
+		switch {
+		case ext.Id.Equal(oidFulcioSourceRepoURI):
+			b.repository = derString(ext.Value)
+
+		case ext.Id.Equal(oidFulcioSourceRepoRef):
+			b.ref = derString(ext.Value)
+
+		case ext.Id.Equal(oidFulcioBuildConfigURI):
+			buildURI = derString(ext.Value)
+
+		case ext.Id.Equal(oidFulcioWorkflowRepository):
+			legacyRepository = string(ext.Value)
+
+		case ext.Id.Equal(oidFulcioWorkflowRef):
+			if b.ref == "" {
+				b.ref = string(ext.Value)
+			}
+		}
+	}
+
+	if b.repository == "" && legacyRepository != "" {
+		b.repository = "https://github.com/" + legacyRepository
+	}
+
+	// The build config uri is `https://github.com/owner/repo/.github/workflows/release.yml@refs/tags/v1.0.0`.
+	if uri, _ := splitRef(buildURI); b.repository != "" && strings.HasPrefix(uri, b.repository+"/") {
+		b.workflowPath = strings.TrimPrefix(uri, b.repository+"/")
+	}
+
+	return b
+}
+
+// derString decodes a DER encoded string, the value of a certificate extension.
+func derString(value []byte) string {
+	var s string
+
+	if _, err := asn1.Unmarshal(value, &s); err != nil {
+		return ""
+	}
+
+	return s
+}
+
+// splitRef splits an uri like `https://github.com/owner/repo@refs/tags/v1.0.0`.
+func splitRef(uri string) (string, string) {
+	idx := strings.LastIndex(uri, "@")
+	if idx < 0 {
+		return uri, ""
+	}
+
+	return uri[:idx], uri[idx+1:]
+}
+
+// webHost returns the host of the github website.
+func (i *Installer) webHost() string {
+	if i.baseURL == nil || i.baseURL.Host == "api.github.com" {
+		return githubHostname
+	}
+
+	return i.baseURL.Host
+}
@@ -0,0 +1,719 @@
+package github_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	github "github.com/nhatthm/plugin-registry-github"
+	"github.com/nhatthm/plugin-registry-github/mock/service"
+)
+
+const (
+	attestedAsset    = "#!/bin/sh\necho my-plugin\n"
+	attestedWorkflow = ".github/workflows/release.yml"
+)
+
+type testSigner struct {
+	key  *ecdsa.PrivateKey
+	cert *x509.Certificate
+}
+
+type testIdentity struct {
+	repository string
+	ref        string
+	workflow   string
+}
+
+var defaultTestIdentity = testIdentity{
+	repository: "https://github.com/owner/my-plugin",
+	ref:        "refs/tags/v1.4.2",
+	workflow:   attestedWorkflow,
+}
+
+func newTestKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	return key
+}
+
+func newTestRoot(t *testing.T, name string) testSigner {
+	t.Helper()
+
+	key := newTestKey(t)
+	tpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tpl, tpl, key.Public(), key)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return testSigner{key: key, cert: cert}
+}
+
+func newTestLeaf(t *testing.T, root testSigner, id testIdentity) testSigner {
+	t.Helper()
+
+	ext := func(oid int, value string) pkix.Extension {
+		v, err := asn1.MarshalWithParams(value, "utf8")
+		require.NoError(t, err)
+
+		return pkix.Extension{Id: asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, oid}, Value: v}
+	}
+
+	key := newTestKey(t)
+	tpl := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(10 * time.Minute),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+		ExtraExtensions: []pkix.Extension{
+			ext(12, id.repository),
+			ext(14, id.ref),
+			ext(18, fmt.Sprintf("%s/%s@%s", id.repository, id.workflow, id.ref)),
+		},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tpl, root.cert, key.Public(), root.key)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return testSigner{key: key, cert: cert}
+}
+
+func newTestTSA(t *testing.T, root testSigner) testSigner {
+	t.Helper()
+
+	key := newTestKey(t)
+	tpl := &x509.Certificate{
+		SerialNumber: big.NewInt(3),
+		Subject:      pkix.Name{CommonName: "test tsa"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageTimeStamping},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tpl, root.cert, key.Public(), root.key)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return testSigner{key: key, cert: cert}
+}
+
+func newSLSAv1Statement(digest string, id testIdentity) []byte {
+	return []byte(fmt.Sprintf(`{
+	"_type": "https://in-toto.io/Statement/v1",
+	"subject": [{"name": "my-plugin", "digest": {"sha256": %q}}],
+	"predicateType": "https://slsa.dev/provenance/v1",
+	"predicate": {"buildDefinition": {"externalParameters": {"workflow": {"ref": %q, "repository": %q, "path": %q}}}}
+}`, digest, id.ref, id.repository, id.workflow))
+}
+
+func newSLSAv02Statement(digest string, id testIdentity) []byte {
+	return []byte(fmt.Sprintf(`{
+	"_type": "https://in-toto.io/Statement/v0.1",
+	"subject": [{"name": "my-plugin", "digest": {"sha256": %q}}],
+	"predicateType": "https://slsa.dev/provenance/v0.2",
+	"predicate": {"invocation": {"configSource": {"uri": %q, "entryPoint": %q}}}
+}`, digest, "git+"+id.repository+"@"+id.ref, id.workflow))
+}
+
+func signDSSE(t *testing.T, key crypto.Signer, payload []byte) string {
+	t.Helper()
+
+	const payloadType = "application/vnd.in-toto+json"
+
+	digest := sha256.Sum256([]byte(fmt.Sprintf("DSSEv1 %d %s %d %s", len(payloadType), payloadType, len(payload), payload)))
+
+	sig, err := key.Sign(rand.Reader, digest[:], crypto.SHA256)
+	require.NoError(t, err)
+
+	return base64.StdEncoding.EncodeToString(sig)
+}
+
+// testBundle is what a proof of the signing time is about.
+type testBundle struct {
+	payload []byte
+	sig     []byte
+	cert    *x509.Certificate
+}
+
+// bundleOption adds a proof of the signing time to the verification material of a bundle.
+type bundleOption func(t *testing.T, material map[string]interface{}, b testBundle)
+
+func newSigstoreBundle(t *testing.T, signer testSigner, payload []byte, options ...bundleOption) []byte {
+	t.Helper()
+
+	sig := signDSSE(t, signer.key, payload)
+	material := map[string]interface{}{
+		"certificate": map[string]interface{}{"rawBytes": base64.StdEncoding.EncodeToString(signer.cert.Raw)},
+	}
+
+	rawSig, err := base64.StdEncoding.DecodeString(sig)
+	require.NoError(t, err)
+
+	for _, o := range options {
+		o(t, material, testBundle{payload: payload, sig: rawSig, cert: signer.cert})
+	}
+
+	data, err := json.Marshal(map[string]interface{}{
+		"mediaType":            "application/vnd.dev.sigstore.bundle.v0.3+json",
+		"verificationMaterial": material,
+		"dsseEnvelope": map[string]interface{}{
+			"payloadType": "application/vnd.in-toto+json",
+			"payload":     base64.StdEncoding.EncodeToString(payload),
+			"signatures":  []interface{}{map[string]interface{}{"sig": sig}},
+		},
+	})
+	require.NoError(t, err)
+
+	return data
+}
+
+// withTlogEntry adds a dsse entry of the transparency log that is integrated at the time.
+func withTlogEntry(log *ecdsa.PrivateKey, integratedTime time.Time) bundleOption {
+	return withTlogEntryOf(log, integratedTime, testBundle{})
+}
+
+// withTlogEntryOf adds a dsse entry of the transparency log for another payload, signature or certificate. The ones
+// that are empty are those of the bundle.
+func withTlogEntryOf(log *ecdsa.PrivateKey, integratedTime time.Time, other testBundle) bundleOption {
+	return func(t *testing.T, material map[string]interface{}, b testBundle) {
+		t.Helper()
+
+		b = other.or(b)
+		payloadHash := sha256.Sum256(b.payload)
+
+		addTlogEntry(t, material, log, integratedTime, fmt.Sprintf(
+			`{"apiVersion":"0.0.1","kind":"dsse","spec":{"payloadHash":{"algorithm":"sha256","value":%q},"signatures":[{"signature":%q,"verifier":%q}]}}`,
+			hex.EncodeToString(payloadHash[:]),
+			base64.StdEncoding.EncodeToString(b.sig),
+			base64.StdEncoding.EncodeToString(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: b.cert.Raw})),
+		))
+	}
+}
+
+// withIntotoTlogEntry adds an intoto entry of the transparency log, that logs the public key of the certificate, and
+// encodes the signature twice.
+func withIntotoTlogEntry(log *ecdsa.PrivateKey, integratedTime time.Time) bundleOption {
+	return func(t *testing.T, material map[string]interface{}, b testBundle) {
+		t.Helper()
+
+		payloadHash := sha256.Sum256(b.payload)
+		sig := base64.StdEncoding.EncodeToString(b.sig)
+
+		addTlogEntry(t, material, log, integratedTime, fmt.Sprintf(
+			`{"apiVersion":"0.0.2","kind":"intoto","spec":{"content":{"envelope":{"signatures":[{"sig":%q,"publicKey":%q}]},"payloadHash":{"algorithm":"sha256","value":%q}}}}`,
+			base64.StdEncoding.EncodeToString([]byte(sig)),
+			base64.StdEncoding.EncodeToString(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: b.cert.RawSubjectPublicKeyInfo})),
+			hex.EncodeToString(payloadHash[:]),
+		))
+	}
+}
+
+func (b testBundle) or(other testBundle) testBundle {
+	if b.payload == nil {
+		b.payload = other.payload
+	}
+
+	if b.sig == nil {
+		b.sig = other.sig
+	}
+
+	if b.cert == nil {
+		b.cert = other.cert
+	}
+
+	return b
+}
+
+// addTlogEntry adds an entry with the body, and its signed entry timestamp.
+func addTlogEntry(t *testing.T, material map[string]interface{}, log *ecdsa.PrivateKey, integratedTime time.Time, body string) {
+	t.Helper()
+
+	pub, err := x509.MarshalPKIXPublicKey(log.Public())
+	require.NoError(t, err)
+
+	logID := sha256.Sum256(pub)
+	encodedBody := base64.StdEncoding.EncodeToString([]byte(body))
+
+	set := sha256.Sum256([]byte(fmt.Sprintf(`{"body":%q,"integratedTime":%d,"logID":%q,"logIndex":7}`,
+		encodedBody, integratedTime.Unix(), hex.EncodeToString(logID[:]))))
+
+	sig, err := log.Sign(rand.Reader, set[:], crypto.SHA256)
+	require.NoError(t, err)
+
+	material["tlogEntries"] = []interface{}{map[string]interface{}{
+		"logIndex":          "7",
+		"logId":             map[string]interface{}{"keyId": base64.StdEncoding.EncodeToString(logID[:])},
+		"integratedTime":    fmt.Sprintf("%d", integratedTime.Unix()),
+		"inclusionPromise":  map[string]interface{}{"signedEntryTimestamp": base64.StdEncoding.EncodeToString(sig)},
+		"canonicalizedBody": encodedBody,
+	}}
+}
+
+// withTimestamp adds a RFC 3161 timestamp of the signature.
+func withTimestamp(tsa testSigner, genTime time.Time) bundleOption {
+	return func(t *testing.T, material map[string]interface{}, b testBundle) {
+		t.Helper()
+
+		withTimestampOf(tsa, genTime, b.sig)(t, material, b)
+	}
+}
+
+// withTimestampOf adds a RFC 3161 timestamp of another signature.
+func withTimestampOf(tsa testSigner, genTime time.Time, sig []byte) bundleOption {
+	return func(t *testing.T, material map[string]interface{}, _ testBundle) {
+		t.Helper()
+
+		material["timestampVerificationData"] = map[string]interface{}{
+			"rfc3161Timestamps": []interface{}{
+				map[string]interface{}{"signedTimestamp": base64.StdEncoding.EncodeToString(newTestTimestamp(t, tsa, genTime, sig))},
+			},
+		}
+	}
+}
+
+// newTestTimestamp returns a RFC 3161 timestamp response of the data, signed by the timestamp authority.
+func newTestTimestamp(t *testing.T, tsa testSigner, genTime time.Time, data []byte) []byte {
+	t.Helper()
+
+	type algorithm struct {
+		Algorithm asn1.ObjectIdentifier
+	}
+
+	type attribute struct {
+		Type   asn1.ObjectIdentifier
+		Values []interface{} `asn1:"set"`
+	}
+
+	var (
+		oidSHA256  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+		oidTSTInfo = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 1, 4}
+		imprint    = sha256.Sum256(data)
+		marshal    = func(v interface{}, params string) []byte {
+			der, err := asn1.MarshalWithParams(v, params)
+			require.NoError(t, err)
+
+			return der
+		}
+		unmarshal = func(der []byte) asn1.RawValue {
+			var v asn1.RawValue
+
+			_, err := asn1.Unmarshal(der, &v)
+			require.NoError(t, err)
+
+			return v
+		}
+	)
+
+	info := marshal(struct {
+		Version        int
+		Policy         asn1.ObjectIdentifier
+		MessageImprint struct {
+			HashAlgorithm algorithm
+			HashedMessage []byte
+		}
+		SerialNumber *big.Int
+		GenTime      time.Time `asn1:"generalized"`
+	}{
+		Version: 1,
+		Policy:  asn1.ObjectIdentifier{1, 2, 3},
+		MessageImprint: struct {
+			HashAlgorithm algorithm
+			HashedMessage []byte
+		}{HashAlgorithm: algorithm{oidSHA256}, HashedMessage: imprint[:]},
+		SerialNumber: big.NewInt(1),
+		GenTime:      genTime.UTC().Truncate(time.Second),
+	}, "")
+
+	infoDigest := sha256.Sum256(info)
+	attrs := marshal([]attribute{
+		{Type: asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 3}, Values: []interface{}{oidTSTInfo}},
+		{Type: asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}, Values: []interface{}{infoDigest[:]}},
+	}, "set")
+
+	attrsDigest := sha256.Sum256(attrs)
+
+	sig, err := tsa.key.Sign(rand.Reader, attrsDigest[:], crypto.SHA256)
+	require.NoError(t, err)
+
+	signedData := marshal(struct {
+		Version          int
+		DigestAlgorithms []algorithm `asn1:"set"`
+		EncapContentInfo struct {
+			EContentType asn1.ObjectIdentifier
+			EContent     []byte `asn1:"explicit,tag:0"`
+		}
+		Certificates asn1.RawValue
+		SignerInfos  []struct {
+			Version            int
+			SID                asn1.RawValue
+			DigestAlgorithm    algorithm
+			SignedAttrs        asn1.RawValue
+			SignatureAlgorithm algorithm
+			Signature          []byte
+		} `asn1:"set"`
+	}{
+		Version:          3,
+		DigestAlgorithms: []algorithm{{oidSHA256}},
+		EncapContentInfo: struct {
+			EContentType asn1.ObjectIdentifier
+			EContent     []byte `asn1:"explicit,tag:0"`
+		}{EContentType: oidTSTInfo, EContent: info},
+		Certificates: asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: tsa.cert.Raw},
+		SignerInfos: []struct {
+			Version            int
+			SID                asn1.RawValue
+			DigestAlgorithm    algorithm
+			SignedAttrs        asn1.RawValue
+			SignatureAlgorithm algorithm
+			Signature          []byte
+		}{{
+			Version: 1,
+			SID: asn1.RawValue{FullBytes: marshal(struct {
+				Issuer       asn1.RawValue
+				SerialNumber *big.Int
+			}{Issuer: asn1.RawValue{FullBytes: tsa.cert.RawIssuer}, SerialNumber: tsa.cert.SerialNumber}, "")},
+			DigestAlgorithm:    algorithm{oidSHA256},
+			SignedAttrs:        asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: unmarshal(attrs).Bytes},
+			SignatureAlgorithm: algorithm{asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 2}},
+			Signature:          sig,
+		}},
+	}, "")
+
+	token := marshal(struct {
+		ContentType asn1.ObjectIdentifier
+		Content     asn1.RawValue
+	}{
+		ContentType: asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2},
+		Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: signedData},
+	}, "")
+
+	return marshal(struct {
+		Status struct {
+			Status int
+		}
+		TimeStampToken asn1.RawValue
+	}{TimeStampToken: asn1.RawValue{FullBytes: token}}, "")
+}
+
+func newIntotoJSONL(t *testing.T, signer testSigner, payloads ...[]byte) []byte {
+	t.Helper()
+
+	var data []byte
+
+	for _, payload := range payloads {
+		sig := map[string]interface{}{"keyid": "", "sig": signDSSE(t, signer.key, payload)}
+
+		if signer.cert != nil {
+			sig["cert"] = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: signer.cert.Raw}))
+		}
+
+		line, err := json.Marshal(map[string]interface{}{
+			"payloadType": "application/vnd.in-toto+json",
+			"payload":     base64.StdEncoding.EncodeToString(payload),
+			"signatures":  []interface{}{sig},
+		})
+		require.NoError(t, err)
+
+		data = append(append(data, line...), '\n')
+	}
+
+	return data
+}
+
+func mockAttestedPlugin(attestationName string, attestation []byte) service.RepositoryServiceMocker {
+	return service.MockRepositoryService(mockPlugin(testPlugin{
+		assetName:      "my-plugin",
+		asset:          []byte(attestedAsset),
+		extraAssetName: attestationName,
+		extraAsset:     attestation,
+	}))
+}
+
+func TestInstaller_Install_Attestation(t *testing.T) {
+	t.Parallel()
+
+	sum := sha256.Sum256([]byte(attestedAsset))
+	digest := hex.EncodeToString(sum[:])
+
+	root := newTestRoot(t, "test root")
+	untrustedRoot := newTestRoot(t, "untrusted root")
+	leaf := newTestLeaf(t, root, defaultTestIdentity)
+	key := testSigner{key: newTestKey(t)}
+	logKey := newTestKey(t)
+	tsaRoot := newTestRoot(t, "tsa root")
+	tsa := newTestTSA(t, tsaRoot)
+	logged := withTlogEntry(logKey, time.Now())
+
+	roots := x509.NewCertPool()
+	roots.AddCert(root.cert)
+
+	tsaRoots := x509.NewCertPool()
+	tsaRoots.AddCert(tsaRoot.cert)
+
+	policy := github.AttestationPolicy{
+		Roots:                roots,
+		PublicKeys:           []crypto.PublicKey{key.key.Public()},
+		TransparencyLogKeys:  []crypto.PublicKey{logKey.Public()},
+		TimestampAuthorities: tsaRoots,
+		WorkflowPath:         attestedWorkflow,
+	}
+
+	expired := time.Unix(leaf.cert.NotAfter.Add(time.Hour).Unix(), 0)
+
+	otherRepository := defaultTestIdentity
+	otherRepository.repository = "https://github.com/attacker/my-plugin"
+
+	otherTag := defaultTestIdentity
+	otherTag.ref = "refs/tags/v1.4.1"
+
+	otherWorkflow := defaultTestIdentity
+	otherWorkflow.workflow = ".github/workflows/test.yml"
+
+	testCases := []struct {
+		scenario        string
+		attestationName string
+		attestation     []byte
+		expectedErr     error
+		expectedError   string
+	}{
+		{
+			scenario:        "sigstore bundle",
+			attestationName: "my-plugin.sigstore.json",
+			attestation:     newSigstoreBundle(t, leaf, newSLSAv1Statement(digest, defaultTestIdentity), logged),
+		},
+		{
+			scenario:        "sigstore bundle with timestamp",
+			attestationName: "my-plugin.sigstore.json",
+			attestation:     newSigstoreBundle(t, leaf, newSLSAv1Statement(digest, defaultTestIdentity), withTimestamp(tsa, time.Now())),
+		},
+		{
+			scenario:        "intoto jsonl with certificate",
+			attestationName: "multiple.intoto.jsonl",
+			attestation: newIntotoJSONL(t, leaf,
+				newSLSAv02Statement("0000", defaultTestIdentity),
+				newSLSAv02Statement(digest, defaultTestIdentity),
+			),
+			expectedErr:   github.ErrAttestationFailed,
+			expectedError: "could not verify attestation: attestation verification failed: unverified signing time: no transparency log entry or timestamp",
+		},
+		{
+			scenario:        "no signing time",
+			attestationName: "my-plugin.sigstore.json",
+			attestation:     newSigstoreBundle(t, leaf, newSLSAv1Statement(digest, defaultTestIdentity)),
+			expectedErr:     github.ErrAttestationFailed,
+			expectedError:   "could not verify attestation: attestation verification failed: unverified signing time: no transparency log entry or timestamp",
+		},
+		{
+			scenario:        "untrusted transparency log",
+			attestationName: "my-plugin.sigstore.json",
+			attestation:     newSigstoreBundle(t, leaf, newSLSAv1Statement(digest, defaultTestIdentity), withTlogEntry(newTestKey(t), time.Now())),
+			expectedErr:     github.ErrAttestationFailed,
+			expectedError:   "could not verify attestation: attestation verification failed: unverified signing time: transparency log entry is not signed by a trusted log",
+		},
+		{
+			scenario:        "transparency log entry of other attestation",
+			attestationName: "my-plugin.sigstore.json",
+			attestation: newSigstoreBundle(t, leaf, newSLSAv1Statement(digest, defaultTestIdentity),
+				withTlogEntryOf(logKey, time.Now(), testBundle{payload: newSLSAv1Statement("0000", defaultTestIdentity)})),
+			expectedErr:   github.ErrAttestationFailed,
+			expectedError: `could not verify attestation: attestation verification failed: unverified signing time: transparency log entry of kind "dsse" is not the one of the attestation`,
+		},
+		{
+			scenario:        "transparency log entry of other signature",
+			attestationName: "my-plugin.sigstore.json",
+			attestation: newSigstoreBundle(t, leaf, newSLSAv1Statement(digest, defaultTestIdentity),
+				withTlogEntryOf(logKey, time.Now(), testBundle{sig: []byte("other")})),
+			expectedErr:   github.ErrAttestationFailed,
+			expectedError: `could not verify attestation: attestation verification failed: unverified signing time: transparency log entry of kind "dsse" is not the one of the signature`,
+		},
+		{
+			scenario:        "transparency log entry of other certificate",
+			attestationName: "my-plugin.sigstore.json",
+			attestation: newSigstoreBundle(t, leaf, newSLSAv1Statement(digest, defaultTestIdentity),
+				withTlogEntryOf(logKey, time.Now(), testBundle{cert: newTestLeaf(t, root, defaultTestIdentity).cert})),
+			expectedErr:   github.ErrAttestationFailed,
+			expectedError: `could not verify attestation: attestation verification failed: unverified signing time: transparency log entry of kind "dsse" is not the one of the signature`,
+		},
+		{
+			scenario:        "intoto transparency log entry",
+			attestationName: "my-plugin.sigstore.json",
+			attestation:     newSigstoreBundle(t, leaf, newSLSAv1Statement(digest, defaultTestIdentity), withIntotoTlogEntry(logKey, time.Now())),
+		},
+		{
+			scenario:        "signed after the certificate expired",
+			attestationName: "my-plugin.sigstore.json",
+			attestation:     newSigstoreBundle(t, leaf, newSLSAv1Statement(digest, defaultTestIdentity), withTlogEntry(logKey, expired)),
+			expectedErr:     github.ErrAttestationFailed,
+			expectedError: fmt.Sprintf("could not verify attestation: attestation verification failed: untrusted certificate: "+
+				"x509: certificate has expired or is not yet valid: current time %s is after %s",
+				expired.Format(time.RFC3339), leaf.cert.NotAfter.Format(time.RFC3339)),
+		},
+		{
+			scenario:        "untrusted timestamp authority",
+			attestationName: "my-plugin.sigstore.json",
+			attestation:     newSigstoreBundle(t, leaf, newSLSAv1Statement(digest, defaultTestIdentity), withTimestamp(newTestTSA(t, untrustedRoot), time.Now())),
+			expectedErr:     github.ErrAttestationFailed,
+			expectedError:   "could not verify attestation: attestation verification failed: unverified signing time: untrusted timestamp authority: x509: certificate signed by unknown authority",
+		},
+		{
+			scenario:        "timestamp of other signature",
+			attestationName: "my-plugin.sigstore.json",
+			attestation:     newSigstoreBundle(t, leaf, newSLSAv1Statement(digest, defaultTestIdentity), withTimestampOf(tsa, time.Now(), []byte("other"))),
+			expectedErr:     github.ErrAttestationFailed,
+			expectedError:   "could not verify attestation: attestation verification failed: unverified signing time: timestamp is not the one of the signature",
+		},
+		{
+			scenario:        "intoto jsonl with trusted key",
+			attestationName: "my-plugin.intoto.jsonl",
+			attestation:     newIntotoJSONL(t, key, newSLSAv1Statement(digest, defaultTestIdentity)),
+		},
+		{
+			scenario:      "no attestation",
+			expectedErr:   github.ErrAttestationNotFound,
+			expectedError: "could not verify attestation: attestation not found: no attestation for my-plugin in release v1.4.2",
+		},
+		{
+			scenario:        "untrusted key",
+			attestationName: "my-plugin.intoto.jsonl",
+			attestation:     newIntotoJSONL(t, testSigner{key: newTestKey(t)}, newSLSAv1Statement(digest, defaultTestIdentity)),
+			expectedErr:     github.ErrAttestationFailed,
+			expectedError:   "could not verify attestation: attestation verification failed: no trusted signature",
+		},
+		{
+			scenario:        "untrusted root",
+			attestationName: "my-plugin.sigstore.json",
+			attestation:     newSigstoreBundle(t, newTestLeaf(t, untrustedRoot, defaultTestIdentity), newSLSAv1Statement(digest, defaultTestIdentity), logged),
+			expectedErr:     github.ErrAttestationFailed,
+			expectedError:   "could not verify attestation: attestation verification failed: untrusted certificate: x509: certificate signed by unknown authority",
+		},
+		{
+			scenario:        "other asset",
+			attestationName: "my-plugin.sigstore.json",
+			attestation:     newSigstoreBundle(t, leaf, newSLSAv1Statement("0000", defaultTestIdentity), logged),
+			expectedErr:     github.ErrAttestationFailed,
+			expectedError:   fmt.Sprintf("could not verify attestation: attestation verification failed: asset with sha256 %s is not a subject of the attestation", digest),
+		},
+		{
+			scenario:        "provenance of other repository",
+			attestationName: "my-plugin.sigstore.json",
+			attestation:     newSigstoreBundle(t, leaf, newSLSAv1Statement(digest, otherRepository), logged),
+			expectedErr:     github.ErrAttestationFailed,
+			expectedError:   `could not verify attestation: attestation verification failed: provenance repository "https://github.com/attacker/my-plugin" does not match "https://github.com/owner/my-plugin"`,
+		},
+		{
+			scenario:        "certificate of other repository",
+			attestationName: "my-plugin.sigstore.json",
+			attestation:     newSigstoreBundle(t, newTestLeaf(t, root, otherRepository), newSLSAv1Statement(digest, defaultTestIdentity), logged),
+			expectedErr:     github.ErrAttestationFailed,
+			expectedError:   `could not verify attestation: attestation verification failed: certificate repository "https://github.com/attacker/my-plugin" does not match "https://github.com/owner/my-plugin"`,
+		},
+		{
+			scenario:        "other tag",
+			attestationName: "my-plugin.sigstore.json",
+			attestation:     newSigstoreBundle(t, newTestLeaf(t, root, otherTag), newSLSAv1Statement(digest, otherTag), logged),
+			expectedErr:     github.ErrAttestationFailed,
+			expectedError:   `could not verify attestation: attestation verification failed: provenance ref "refs/tags/v1.4.1" does not match "refs/tags/v1.4.2"`,
+		},
+		{
+			scenario:        "other workflow",
+			attestationName: "my-plugin.intoto.jsonl",
+			attestation:     newIntotoJSONL(t, key, newSLSAv02Statement(digest, otherWorkflow)),
+			expectedErr:     github.ErrAttestationFailed,
+			expectedError:   `could not verify attestation: attestation verification failed: provenance workflow ".github/workflows/test.yml" does not match ".github/workflows/release.yml"`,
+		},
+		{
+			scenario:        "invalid attestation",
+			attestationName: "my-plugin.intoto.jsonl",
+			attestation:     []byte("{\n"),
+			expectedErr:     github.ErrAttestationFailed,
+			expectedError:   "could not verify attestation: attestation verification failed: could not parse attestation: unexpected end of JSON input",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			dest := t.TempDir()
+
+			i := github.NewInstaller(
+				github.WithFs(afero.NewOsFs()),
+				github.WithService(mockAttestedPlugin(tc.attestationName, tc.attestation)(t)),
+				github.WithMetadataSources(github.MetadataFromContents),
+				github.WithAttestationPolicy(policy),
+			)
+
+			_, err := i.Install(context.Background(), dest, "github.com/owner/my-plugin@v1.4.2")
+
+			_, statErr := os.Stat(filepath.Join(dest, "my-plugin", "my-plugin"))
+
+			if tc.expectedErr == nil {
+				require.NoError(t, err)
+				assert.NoError(t, statErr)
+
+				return
+			}
+
+			assert.EqualError(t, err, tc.expectedError)
+			assert.True(t, errors.Is(err, tc.expectedErr))
+			assert.True(t, os.IsNotExist(statErr))
+		})
+	}
+}
+
+func TestInstaller_Install_AttestationNotRequired(t *testing.T) {
+	t.Parallel()
+
+	i := github.NewInstaller(
+		github.WithFs(afero.NewOsFs()),
+		github.WithService(mockAttestedPlugin("", nil)(t)),
+		github.WithMetadataSources(github.MetadataFromContents),
+	)
+
+	_, err := i.Install(context.Background(), t.TempDir(), "github.com/owner/my-plugin@v1.4.2")
+
+	assert.NoError(t, err)
+}
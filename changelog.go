@@ -0,0 +1,170 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/bool64/ctxd"
+	"github.com/google/go-github/v35/github"
+)
+
+// ErrInvalidVersion indicates that the version is not a semantic version.
+var ErrInvalidVersion = errors.New("invalid version")
+
+// breakingChangePattern matches the common breaking change markers: "BREAKING CHANGE", "BREAKING-CHANGES" and the
+// conventional commit "!" like "feat!: ..." or "fix(api)!: ...".
+var breakingChangePattern = regexp.MustCompile(`(?mi)(\bBREAKING[ -]CHANGES?\b|^[ \t]*(?:[-*][ \t]+)?\w+(?:\([^)\n]*\))?!:)`)
+
+// Changelog is the release notes of a plugin between two versions.
+type Changelog struct {
+	Entries []ChangelogEntry
+	// Markdown is the rendered release notes.
+	Markdown string
+}
+
+// ChangelogEntry is the release note of a version.
+type ChangelogEntry struct {
+	// Version is the normalized version, without the v prefix.
+	Version     string
+	Tag         string
+	Name        string
+	PublishedAt time.Time
+	Body        string
+	// Breaking tells whether the release note has a breaking change marker.
+	Breaking bool
+}
+
+// Changelog collects the release notes of the versions after fromVersion, up to and including toVersion, the oldest
+// first. An empty fromVersion starts from the first release, an empty toVersion or "latest" ends at the latest release,
+// that is the newest release that is not a prerelease. The releases that do not have a semantic version tag are ignored.
+func (i *Installer) Changelog(ctx context.Context, source, fromVersion, toVersion string) (*Changelog, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	owner, repository, _, err := parseURL(source)
+	if err != nil {
+		return nil, parseError(err, source)
+	}
+
+	ctx = withSource(ctx, source, owner, repository)
+
+	from, err := parseVersionBound(fromVersion)
+	if err != nil {
+		return nil, ctxd.WrapError(ctx, err, "could not parse from version", "version", fromVersion)
+	}
+
+	to, err := parseVersionBound(toVersion)
+	if err != nil {
+		return nil, ctxd.WrapError(ctx, err, "could not parse to version", "version", toVersion)
+	}
+
+	releases, err := i.listReleases(ctx, owner, repository)
+	if err != nil {
+		return nil, err
+	}
+
+	if to == nil {
+		to = latestVersion(releases)
+		if to == nil {
+			return &Changelog{}, nil
+		}
+	}
+
+	var entries []ChangelogEntry
+
+	// The releases are sorted newest first.
+	for idx := len(releases) - 1; idx >= 0; idx-- {
+		r := releases[idx]
+
+		v, err := semver.NewVersion(r.GetTagName())
+		if err != nil {
+			continue
+		}
+
+		if (from != nil && !v.GreaterThan(from)) || v.GreaterThan(to) {
+			continue
+		}
+
+		entries = append(entries, ChangelogEntry{
+			Version:     trimVersion(r.GetTagName()),
+			Tag:         r.GetTagName(),
+			Name:        r.GetName(),
+			PublishedAt: r.GetPublishedAt().Time,
+			Body:        strings.TrimSpace(r.GetBody()),
+			Breaking:    breakingChangePattern.MatchString(r.GetBody()),
+		})
+	}
+
+	return &Changelog{
+		Entries:  entries,
+		Markdown: renderChangelog(entries),
+	}, nil
+}
+
+// latestVersion returns the version of the newest release that is not a prerelease, or nil if there is none. The
+// releases are sorted newest first.
+func latestVersion(releases []*github.RepositoryRelease) *semver.Version {
+	for _, r := range releases {
+		if r.GetPrerelease() {
+			continue
+		}
+
+		if v, err := semver.NewVersion(r.GetTagName()); err == nil {
+			return v
+		}
+	}
+
+	return nil
+}
+
+func parseVersionBound(v string) (*semver.Version, error) {
+	if v == "" || v == "latest" {
+		return nil, nil
+	}
+
+	sv, err := semver.NewVersion(v)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidVersion, err.Error())
+	}
+
+	return sv, nil
+}
+
+func renderChangelog(entries []ChangelogEntry) string {
+	sections := make([]string, 0, len(entries))
+
+	for _, e := range entries {
+		title := "## " + e.Version
+
+		if e.Name != "" && e.Name != e.Tag && e.Name != e.Version {
+			title += " - " + e.Name
+		}
+
+		if !e.PublishedAt.IsZero() {
+			title += fmt.Sprintf(" (%s)", e.PublishedAt.Format("2006-01-02"))
+		}
+
+		parts := []string{title}
+
+		if e.Breaking {
+			parts = append(parts, "**⚠ Breaking changes**")
+		}
+
+		if e.Body != "" {
+			parts = append(parts, e.Body)
+		}
+
+		sections = append(sections, strings.Join(parts, "\n\n"))
+	}
+
+	if len(sections) == 0 {
+		return ""
+	}
+
+	return strings.Join(sections, "\n\n") + "\n"
+}
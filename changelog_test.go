@@ -0,0 +1,186 @@
+package github_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	goGitHub "github.com/google/go-github/v35/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	github "github.com/nhatthm/plugin-registry-github"
+	"github.com/nhatthm/plugin-registry-github/mock/service"
+)
+
+func TestInstaller_Changelog(t *testing.T) {
+	t.Parallel()
+
+	day := func(d int) time.Time {
+		return time.Date(2021, time.January, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	release := func(tagName string, publishedAt time.Time, body string, options ...releaseOption) *goGitHub.RepositoryRelease {
+		return newRelease(tagName, append(options, withName(tagName), withPublishedAt(publishedAt), withBody(body))...)
+	}
+
+	prerelease := release("v2.1.0-rc.1", day(7), "- feat: add a flag", withPrerelease())
+
+	releases := []*goGitHub.RepositoryRelease{
+		prerelease,
+		release("v2.0.0", day(5), "- feat!: drop the old config format"),
+		release("nightly", day(6), "nightly build"),
+		release("v1.1.0", day(3), "- feat: add a command"),
+		release("v1.0.0", day(1), "- initial release"),
+		release("v1.2.0", day(4), "- fix: typo\n\nBREAKING CHANGE: the output is now json"),
+		release("v1.0.1", day(2), ""),
+	}
+
+	testCases := []struct {
+		scenario         string
+		from             string
+		to               string
+		mockService      service.ReleaseServiceMocker
+		expectedVersions []string
+		expectedBreaking []bool
+		expectedMarkdown string
+		expectedError    string
+	}{
+		{
+			scenario:      "invalid from version",
+			from:          "master",
+			expectedError: "could not parse from version: invalid version: Invalid Semantic Version",
+		},
+		{
+			scenario:      "invalid to version",
+			from:          "1.0.0",
+			to:            "main",
+			expectedError: "could not parse to version: invalid version: Invalid Semantic Version",
+		},
+		{
+			scenario:      "service could not list releases",
+			expectedError: "could not list releases: repository service could not list releases",
+		},
+		{
+			scenario: "between versions",
+			from:     "1.0.0",
+			to:       "v1.2.0",
+			mockService: service.MockReleaseService(func(s *service.ReleaseService) {
+				s.On("ListReleases", mock.Anything, "owner", "my-plugin", mock.Anything).
+					Return(releases, nil, nil)
+			}),
+			expectedVersions: []string{"1.0.1", "1.1.0", "1.2.0"},
+			expectedBreaking: []bool{false, false, true},
+			expectedMarkdown: `## 1.0.1 (2021-01-02)
+
+## 1.1.0 (2021-01-03)
+
+- feat: add a command
+
+## 1.2.0 (2021-01-04)
+
+**⚠ Breaking changes**
+
+- fix: typo
+
+BREAKING CHANGE: the output is now json
+`,
+		},
+		{
+			scenario: "to latest",
+			from:     "v1.2.0",
+			to:       "latest",
+			mockService: service.MockReleaseService(func(s *service.ReleaseService) {
+				s.On("ListReleases", mock.Anything, "owner", "my-plugin", mock.Anything).
+					Return(releases, nil, nil)
+			}),
+			expectedVersions: []string{"2.0.0"},
+			expectedBreaking: []bool{true},
+			expectedMarkdown: `## 2.0.0 (2021-01-05)
+
+**⚠ Breaking changes**
+
+- feat!: drop the old config format
+`,
+		},
+		{
+			scenario: "to prerelease",
+			from:     "v2.0.0",
+			to:       "v2.1.0-rc.1",
+			mockService: service.MockReleaseService(func(s *service.ReleaseService) {
+				s.On("ListReleases", mock.Anything, "owner", "my-plugin", mock.Anything).
+					Return(releases, nil, nil)
+			}),
+			expectedVersions: []string{"2.1.0-rc.1"},
+			expectedBreaking: []bool{false},
+			expectedMarkdown: `## 2.1.0-rc.1 (2021-01-07)
+
+- feat: add a flag
+`,
+		},
+		{
+			scenario: "only prereleases",
+			mockService: service.MockReleaseService(func(s *service.ReleaseService) {
+				s.On("ListReleases", mock.Anything, "owner", "my-plugin", mock.Anything).
+					Return([]*goGitHub.RepositoryRelease{prerelease}, nil, nil)
+			}),
+			expectedVersions: []string{},
+			expectedBreaking: []bool{},
+		},
+		{
+			scenario: "from the beginning",
+			to:       "1.0.1",
+			mockService: service.MockReleaseService(func(s *service.ReleaseService) {
+				s.On("ListReleases", mock.Anything, "owner", "my-plugin", mock.Anything).
+					Return(releases, nil, nil)
+			}),
+			expectedVersions: []string{"1.0.0", "1.0.1"},
+			expectedBreaking: []bool{false, false},
+			expectedMarkdown: `## 1.0.0 (2021-01-01)
+
+- initial release
+
+## 1.0.1 (2021-01-02)
+`,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			options := []github.Option{github.WithService(service.NoMockRepositoryService(t))}
+
+			if tc.mockService != nil {
+				options = append(options, github.WithReleaseService(tc.mockService(t)))
+			}
+
+			i := github.NewInstaller(options...)
+
+			result, err := i.Changelog(context.Background(), "github.com/owner/my-plugin", tc.from, tc.to)
+
+			if tc.expectedError != "" {
+				assert.Nil(t, result)
+				assert.EqualError(t, err, tc.expectedError)
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			versions := make([]string, 0, len(result.Entries))
+			breaking := make([]bool, 0, len(result.Entries))
+
+			for _, e := range result.Entries {
+				versions = append(versions, e.Version)
+				breaking = append(breaking, e.Breaking)
+			}
+
+			assert.Equal(t, tc.expectedVersions, versions)
+			assert.Equal(t, tc.expectedBreaking, breaking)
+			assert.Equal(t, tc.expectedMarkdown, result.Markdown)
+		})
+	}
+}
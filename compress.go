@@ -0,0 +1,111 @@
+package github
+
+import (
+	"bufio"
+	"compress/bzip2"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/bool64/ctxd"
+	"github.com/klauspost/compress/zstd"
+	"github.com/nhatthm/plugin-registry/plugin"
+	"github.com/ulikunitz/xz"
+)
+
+// newDecompressor returns a streaming decompressor for the file type. A plain tar is read as is.
+func newDecompressor(t fileType, r io.Reader) (io.ReadCloser, error) {
+	switch t {
+	case fileTypeXz:
+		xr, err := xz.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+
+		return ioutil.NopCloser(xr), nil
+
+	case fileTypeZstd:
+		zr, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+
+		return zr.IOReadCloser(), nil
+
+	case fileTypeBzip2:
+		return ioutil.NopCloser(bzip2.NewReader(r)), nil
+	}
+
+	return ioutil.NopCloser(r), nil
+}
+
+// decompressArtifact decompresses the artifact into a format that the filesystem installers support, without loading
+// it in memory: a tar is compressed again with gzip, anything else is installed as the plugin binary.
+func (i *Installer) decompressArtifact(ctx context.Context, dir, assetFile string, t fileType, p *plugin.Plugin) (string, error) {
+	tarFile, _ := stagedArtifact(dir, fileTypeTar, p.Name)
+	binaryFile, binarySource := stagedArtifact(dir, fileTypeExecutable, p.Name)
+
+	// The asset could already be at one of the destinations.
+	if assetFile == tarFile || assetFile == binaryFile {
+		from := assetFile
+		assetFile = filepath.Join(dir, p.Name+".download")
+
+		if err := i.moveArtifact(ctx, from, assetFile, 0); err != nil {
+			return "", err
+		}
+	}
+
+	f, err := i.fs.Open(assetFile)
+	if err != nil {
+		return "", ctxd.WrapError(ctx, err, "could not open artifact")
+	}
+
+	defer f.Close() // nolint: errcheck
+
+	dr, err := newDecompressor(t, f)
+	if err != nil {
+		return "", ctxd.WrapError(ctx, err, "could not decompress artifact")
+	}
+
+	defer dr.Close() // nolint: errcheck
+
+	r := bufio.NewReaderSize(dr, sniffLength)
+
+	header, err := r.Peek(sniffLength)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", ctxd.WrapError(ctx, err, "could not decompress artifact")
+	}
+
+	copyFn := i.decompressCopier(contextCopier(ctx, throughput{}))
+
+	if detectFileType("", header) == fileTypeTar {
+		if err := writeTempFile(i.fs, tarFile, r, gzipCopier(copyFn)); err != nil {
+			return "", ctxd.WrapError(ctx, err, "could not decompress artifact")
+		}
+
+		return tarFile, nil
+	}
+
+	if err := writeTempFile(i.fs, binaryFile, r, copyFn); err != nil {
+		return "", ctxd.WrapError(ctx, err, "could not decompress artifact")
+	}
+
+	return binarySource, i.moveArtifact(ctx, binaryFile, binaryFile, executableMode(i.targetOf(ctx)))
+}
+
+// gzipCopier returns a copyFunc that compresses with gzip.
+func gzipCopier(copyFn copyFunc) copyFunc {
+	return func(dst io.Writer, src io.Reader) (int64, error) {
+		zw := gzip.NewWriter(dst)
+
+		n, err := copyFn(zw, src)
+		if err != nil {
+			return n, err
+		}
+
+		return n, zw.Close()
+	}
+}
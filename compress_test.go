@@ -0,0 +1,237 @@
+package github
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/nhatthm/plugin-registry/plugin"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func gunzipData(t *testing.T, data []byte) []byte {
+	t.Helper()
+
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	result, err := ioutil.ReadAll(zr)
+	require.NoError(t, err)
+
+	return result
+}
+
+func TestNewDecompressor(t *testing.T) {
+	t.Parallel()
+
+	binary := gunzipData(t, readFixture(t, "resources/fixtures/gzip/my-plugin-no-parent.gz"))
+
+	testCases := []struct {
+		scenario      string
+		fileType      fileType
+		data          []byte
+		expected      []byte
+		expectedError string
+	}{
+		{
+			scenario: "xz",
+			fileType: fileTypeXz,
+			data:     readFixture(t, "resources/fixtures/xz/my-plugin-no-parent.xz"),
+			expected: binary,
+		},
+		{
+			scenario: "zstd",
+			fileType: fileTypeZstd,
+			data:     readFixture(t, "resources/fixtures/zstd/my-plugin-no-parent.zst"),
+			expected: binary,
+		},
+		{
+			scenario: "bzip2",
+			fileType: fileTypeBzip2,
+			data:     readFixture(t, "resources/fixtures/bzip2/my-plugin-no-parent.bz2"),
+			expected: binary,
+		},
+		{
+			scenario: "tar",
+			fileType: fileTypeTar,
+			data:     newTarData(t, "my-plugin", "#!/bin/bash\n"),
+			expected: newTarData(t, "my-plugin", "#!/bin/bash\n"),
+		},
+		{
+			scenario:      "invalid xz",
+			fileType:      fileTypeXz,
+			data:          []byte("not an xz stream"),
+			expectedError: "xz: invalid header magic bytes",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := newDecompressor(tc.fileType, bytes.NewReader(tc.data))
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			defer r.Close() // nolint: errcheck
+
+			result, err := ioutil.ReadAll(r)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
+
+func TestInstaller_DecompressArtifact(t *testing.T) {
+	t.Parallel()
+
+	tarData := gunzipData(t, readFixture(t, "resources/fixtures/gzip/my-plugin.tar.gz"))
+	binary := gunzipData(t, readFixture(t, "resources/fixtures/gzip/my-plugin-no-parent.gz"))
+
+	testCases := []struct {
+		scenario       string
+		fixture        string
+		fileType       fileType
+		maxSize        int64
+		expectedFile   string
+		expectedSource string
+		expectedTar    []byte
+		expectedBinary []byte
+		expectedError  string
+	}{
+		{
+			scenario:       "tar xz",
+			fixture:        "resources/fixtures/xz/my-plugin.tar.xz",
+			fileType:       fileTypeXz,
+			expectedFile:   "/tmp/my-plugin.tar.gz",
+			expectedSource: "/tmp/my-plugin.tar.gz",
+			expectedTar:    tarData,
+		},
+		{
+			scenario:       "tar zstd",
+			fixture:        "resources/fixtures/zstd/my-plugin.tar.zst",
+			fileType:       fileTypeZstd,
+			expectedFile:   "/tmp/my-plugin.tar.gz",
+			expectedSource: "/tmp/my-plugin.tar.gz",
+			expectedTar:    tarData,
+		},
+		{
+			scenario:       "tar bzip2",
+			fixture:        "resources/fixtures/bzip2/my-plugin.tar.bz2",
+			fileType:       fileTypeBzip2,
+			expectedFile:   "/tmp/my-plugin.tar.gz",
+			expectedSource: "/tmp/my-plugin.tar.gz",
+			expectedTar:    tarData,
+		},
+		{
+			scenario:       "binary xz",
+			fixture:        "resources/fixtures/xz/my-plugin-no-parent.xz",
+			fileType:       fileTypeXz,
+			expectedFile:   "/tmp/my-plugin",
+			expectedSource: "/tmp",
+			expectedBinary: binary,
+		},
+		{
+			scenario:       "binary zstd",
+			fixture:        "resources/fixtures/zstd/my-plugin-no-parent.zst",
+			fileType:       fileTypeZstd,
+			expectedFile:   "/tmp/my-plugin",
+			expectedSource: "/tmp",
+			expectedBinary: binary,
+		},
+		{
+			scenario:       "binary bzip2",
+			fixture:        "resources/fixtures/bzip2/my-plugin-no-parent.bz2",
+			fileType:       fileTypeBzip2,
+			expectedFile:   "/tmp/my-plugin",
+			expectedSource: "/tmp",
+			expectedBinary: binary,
+		},
+		{
+			scenario:       "tar at the destination",
+			fileType:       fileTypeTar,
+			expectedFile:   "/tmp/my-plugin.tar.gz",
+			expectedSource: "/tmp/my-plugin.tar.gz",
+			expectedTar:    tarData,
+		},
+		{
+			scenario:      "decompression bomb",
+			fixture:       "resources/fixtures/xz/my-plugin.tar.xz",
+			fileType:      fileTypeXz,
+			maxSize:       int64(len(tarData)) - 1,
+			expectedError: "could not decompress artifact: asset too large: more than 10239 bytes once decompressed",
+		},
+		{
+			scenario:      "binary decompression bomb",
+			fixture:       "resources/fixtures/zstd/my-plugin-no-parent.zst",
+			fileType:      fileTypeZstd,
+			maxSize:       int64(len(binary)) - 1,
+			expectedError: "could not decompress artifact: asset too large: more than 11 bytes once decompressed",
+		},
+		{
+			scenario:       "within the limit",
+			fixture:        "resources/fixtures/bzip2/my-plugin.tar.bz2",
+			fileType:       fileTypeBzip2,
+			maxSize:        int64(len(tarData)),
+			expectedFile:   "/tmp/my-plugin.tar.gz",
+			expectedSource: "/tmp/my-plugin.tar.gz",
+			expectedTar:    tarData,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			fs := afero.NewMemMapFs()
+			assetFile := filepath.Join("/tmp", filepath.Base(tc.fixture))
+
+			// The plain tar is staged where its gzip is written.
+			if tc.fileType == fileTypeTar {
+				assetFile = "/tmp/my-plugin.tar.gz"
+
+				require.NoError(t, afero.WriteFile(fs, assetFile, tarData, 0o644))
+			} else {
+				require.NoError(t, afero.WriteFile(fs, assetFile, readFixture(t, tc.fixture), 0o644))
+			}
+
+			i := NewInstaller(WithFs(fs), WithMaxDecompressedSize(tc.maxSize))
+
+			source, err := i.decompressArtifact(context.Background(), "/tmp", assetFile, tc.fileType, &plugin.Plugin{Name: "my-plugin"})
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedSource, source)
+
+			data, err := afero.ReadFile(fs, tc.expectedFile)
+			require.NoError(t, err)
+
+			if tc.expectedTar != nil {
+				assert.Equal(t, tc.expectedTar, gunzipData(t, data))
+			}
+
+			if tc.expectedBinary != nil {
+				assert.Equal(t, tc.expectedBinary, data)
+			}
+		})
+	}
+}
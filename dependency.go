@@ -0,0 +1,462 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/bool64/ctxd"
+	"github.com/google/go-github/v35/github"
+)
+
+// maxDependencyRounds is the maximum number of rounds to resolve the dependencies, a round selects again the versions
+// that do not satisfy the new requirements.
+const maxDependencyRounds = 100
+
+var (
+	// ErrDependencyConflict indicates that no version of a dependency satisfies all of its requirements.
+	ErrDependencyConflict = errors.New("dependency conflict")
+	// ErrDependencyCycle indicates that the plugins depend on each other.
+	ErrDependencyCycle = errors.New("dependency cycle")
+)
+
+// Dependency is a plugin that a plugin needs, it is declared in the metadata:
+//
+//	dependencies:
+//	  - source: github.com/owner/helper
+//	    version: ">= 1.2, < 2"
+//
+// The version is a semantic version constraint, any version is accepted if it is empty.
+type Dependency struct {
+	Source  string `yaml:"source"`
+	Version string `yaml:"version"`
+}
+
+// ResolveDependencies resolves the plugin and its dependencies, without downloading or writing anything. The plans
+// are in the install order, the dependencies first and the plugin last.
+func (i *Installer) ResolveDependencies(ctx context.Context, source string) ([]*Plan, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	ctx, plan, err := i.resolve(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+
+	plans, err := i.resolveDependencies(ctx, plan)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(plans, plan), nil
+}
+
+// installDependencies installs the dependencies of the plugin into the destination.
+func (i *Installer) installDependencies(ctx context.Context, dest string, plan *Plan) error {
+	if len(plan.Dependencies) == 0 {
+		return nil
+	}
+
+	plans, err := i.resolveDependencies(ctx, plan)
+	if err != nil {
+		return err
+	}
+
+	for _, dep := range plans {
+		depCtx := withSource(ctx, dep.Source, dep.Owner, dep.Repository)
+
+		if _, err := i.installPlan(depCtx, dest, dep); err != nil {
+			return ctxd.WrapError(ctx, err, "could not install dependency", "dependency", dep.Source, "tag", dep.Tag)
+		}
+	}
+
+	return nil
+}
+
+// dependencyRequirement is a version constraint of a dependency.
+type dependencyRequirement struct {
+	// from is the key of the plugin that requires the dependency.
+	from       string
+	label      string
+	constraint string
+	check      *semver.Constraints
+}
+
+func (r dependencyRequirement) String() string {
+	if r.constraint == "" {
+		return fmt.Sprintf("%s requires any version", r.label)
+	}
+
+	return fmt.Sprintf("%s requires %q", r.label, r.constraint)
+}
+
+// dependencyNode is a plugin in the dependency graph.
+type dependencyNode struct {
+	key          string
+	source       string
+	owner        string
+	repository   string
+	requirements []dependencyRequirement
+	releases     []*github.RepositoryRelease
+	plan         *Plan
+	// fixed is set for the plugin that is installed, its version is never changed.
+	fixed bool
+}
+
+func (n *dependencyNode) satisfied(v *semver.Version) bool {
+	for _, r := range n.requirements {
+		if r.check != nil && (v == nil || !r.check.Check(v)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// dependencyResolver selects the versions of the dependencies. It selects the newest version of a dependency that
+// satisfies its requirements, and selects it again when a new requirement excludes it, until all the requirements are
+// satisfied.
+type dependencyResolver struct {
+	installer *Installer
+	nodes     map[string]*dependencyNode
+	root      *dependencyNode
+}
+
+func (i *Installer) resolveDependencies(ctx context.Context, root *Plan) ([]*Plan, error) {
+	ctx, span := i.startSpan(ctx, "resolve dependencies")
+
+	plans, err := i.resolveDependencyGraph(ctx, root)
+
+	endSpan(span, err)
+
+	if err != nil {
+		return nil, ctxd.WrapError(ctx, err, "could not resolve dependencies")
+	}
+
+	if len(plans) > 0 {
+		i.logger.Info(ctx, "resolved dependencies", "count", len(plans))
+	}
+
+	return plans, nil
+}
+
+func (i *Installer) resolveDependencyGraph(ctx context.Context, root *Plan) ([]*Plan, error) {
+	r := &dependencyResolver{
+		installer: i,
+		nodes:     make(map[string]*dependencyNode),
+	}
+
+	r.root = &dependencyNode{
+		key:        dependencyKey(root.Owner, root.Repository),
+		source:     root.Source,
+		owner:      root.Owner,
+		repository: root.Repository,
+		plan:       root,
+		fixed:      true,
+	}
+	r.nodes[r.root.key] = r.root
+
+	if err := r.require(r.root); err != nil {
+		return nil, err
+	}
+
+	if err := r.resolve(ctx); err != nil {
+		return nil, err
+	}
+
+	return r.installOrder()
+}
+
+// require adds the requirements of the selected version of the node.
+func (r *dependencyResolver) require(n *dependencyNode) error {
+	label := fmt.Sprintf("%s@%s", n.key, n.plan.Tag)
+
+	for _, dep := range n.plan.Dependencies {
+		owner, repository, version, err := parseURL(dep.Source)
+		if err != nil {
+			return fmt.Errorf("%s: invalid dependency %q: %w", label, dep.Source, err)
+		}
+
+		if version != "" {
+			return fmt.Errorf("%s: invalid dependency %q: %w: the version must be a constraint", label, dep.Source, ErrInvalidVersion)
+		}
+
+		req := dependencyRequirement{from: n.key, label: label, constraint: dep.Version}
+
+		if dep.Version != "" {
+			if req.check, err = semver.NewConstraint(dep.Version); err != nil {
+				return fmt.Errorf("%s: invalid constraint %q of %s: %w", label, dep.Version, dep.Source, ErrInvalidVersion)
+			}
+		}
+
+		key := dependencyKey(owner, repository)
+
+		d, ok := r.nodes[key]
+		if !ok {
+			d = &dependencyNode{
+				key:        key,
+				source:     fmt.Sprintf("%s/%s/%s", githubHostname, owner, repository),
+				owner:      owner,
+				repository: repository,
+			}
+			r.nodes[key] = d
+		}
+
+		d.requirements = append(d.requirements, req)
+	}
+
+	return nil
+}
+
+// unrequire removes the requirements of the selected version of the node.
+func (r *dependencyResolver) unrequire(n *dependencyNode) {
+	for _, d := range r.nodes {
+		reqs := d.requirements[:0]
+
+		for _, req := range d.requirements {
+			if req.from != n.key {
+				reqs = append(reqs, req)
+			}
+		}
+
+		d.requirements = reqs
+	}
+}
+
+func (r *dependencyResolver) resolve(ctx context.Context) error {
+	for round := 0; round < maxDependencyRounds; round++ {
+		r.prune()
+
+		changed := false
+
+		for _, key := range r.keys() {
+			n := r.nodes[key]
+
+			if n.plan != nil && n.satisfied(planVersion(n.plan)) {
+				continue
+			}
+
+			if n.fixed {
+				return r.conflict(n)
+			}
+
+			if n.plan != nil {
+				r.unrequire(n)
+			}
+
+			plan, err := r.selectVersion(ctx, n)
+			if err != nil {
+				return err
+			}
+
+			n.plan = plan
+			changed = true
+
+			if err := r.require(n); err != nil {
+				return err
+			}
+		}
+
+		if !changed {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: the versions do not settle after %d rounds", ErrDependencyConflict, maxDependencyRounds)
+}
+
+// prune removes the dependencies that are not required anymore, because the plugins that required them selected other
+// versions.
+func (r *dependencyResolver) prune() {
+	for {
+		removed := false
+
+		for key, n := range r.nodes {
+			if n.fixed || len(n.requirements) > 0 {
+				continue
+			}
+
+			delete(r.nodes, key)
+			r.unrequire(n)
+
+			removed = true
+		}
+
+		if !removed {
+			return
+		}
+	}
+}
+
+// selectVersion selects the newest release that satisfies the requirements and has an artifact for the target.
+func (r *dependencyResolver) selectVersion(ctx context.Context, n *dependencyNode) (*Plan, error) {
+	i := r.installer
+	ctx = withSource(ctx, n.source, n.owner, n.repository)
+
+	cacheHit := n.releases != nil
+
+	i.metrics.ObserveDependencyReleases(cacheHit)
+	i.logger.Debug(ctx, "looked up releases", "cacheHit", cacheHit)
+
+	if n.releases == nil {
+		releases, err := i.listReleases(ctx, n.owner, n.repository)
+		if err != nil {
+			return nil, err
+		}
+
+		n.releases = releases
+	}
+
+	incompatible := false
+
+	for _, release := range n.releases {
+		v, err := semver.NewVersion(release.GetTagName())
+		if err != nil || !n.satisfied(v) {
+			continue
+		}
+
+		plan, err := i.resolveListedRelease(ctx, n.owner, n.repository, release)
+		if errors.Is(err, ErrIncompatibleHost) {
+			incompatible = true
+
+			continue
+		}
+
+		if isRejectedRelease(err) {
+			i.logger.Debug(ctx, "skipped release", "tag", release.GetTagName(), "error", err)
+
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		plan.Source = n.source
+
+		i.logger.Debug(ctx, "selected dependency", "tag", plan.Tag, "requirements", n.requirementStrings())
+
+		return plan, nil
+	}
+
+	if incompatible {
+		return nil, fmt.Errorf("%w: no version of %s that satisfies the requirements is compatible with %s %s: %s",
+			ErrIncompatibleHost, n.key, i.hostApp.name, i.hostApp.version, strings.Join(n.requirementStrings(), ", "))
+	}
+
+	return nil, r.conflict(n)
+}
+
+func (r *dependencyResolver) conflict(n *dependencyNode) error {
+	return fmt.Errorf("%w: no version of %s satisfies: %s", ErrDependencyConflict, n.key, strings.Join(n.requirementStrings(), ", "))
+}
+
+func (n *dependencyNode) requirementStrings() []string {
+	reqs := make([]string, 0, len(n.requirements))
+
+	for _, r := range n.requirements {
+		reqs = append(reqs, r.String())
+	}
+
+	sort.Strings(reqs)
+
+	return reqs
+}
+
+// installOrder returns the plans of the dependencies, a dependency comes before the plugins that require it.
+func (r *dependencyResolver) installOrder() ([]*Plan, error) {
+	const (
+		visiting = 1
+		visited  = 2
+	)
+
+	edges := make(map[string][]string, len(r.nodes))
+
+	for _, n := range r.nodes {
+		for _, req := range n.requirements {
+			edges[req.from] = append(edges[req.from], n.key)
+		}
+	}
+
+	state := make(map[string]int, len(r.nodes))
+	plans := make([]*Plan, 0, len(r.nodes)-1)
+
+	var visit func(key string, path []string) error
+
+	visit = func(key string, path []string) error {
+		path = append(path, key)
+
+		switch state[key] {
+		case visited:
+			return nil
+
+		case visiting:
+			start := 0
+
+			for idx, k := range path {
+				if k == key {
+					start = idx
+
+					break
+				}
+			}
+
+			return fmt.Errorf("%w: %s", ErrDependencyCycle, strings.Join(path[start:], " -> "))
+		}
+
+		state[key] = visiting
+
+		deps := edges[key]
+		sort.Strings(deps)
+
+		for _, dep := range deps {
+			if err := visit(dep, path); err != nil {
+				return err
+			}
+		}
+
+		state[key] = visited
+
+		if n := r.nodes[key]; !n.fixed {
+			plans = append(plans, n.plan)
+		}
+
+		return nil
+	}
+
+	if err := visit(r.root.key, nil); err != nil {
+		return nil, err
+	}
+
+	return plans, nil
+}
+
+func (r *dependencyResolver) keys() []string {
+	keys := make([]string, 0, len(r.nodes))
+
+	for k := range r.nodes {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// dependencyKey identifies a plugin in the dependency graph.
+func dependencyKey(owner, repository string) string {
+	return strings.ToLower(owner + "/" + repository)
+}
+
+// planVersion returns the semantic version of the plan, or nil if the tag is not a semantic version.
+func planVersion(plan *Plan) *semver.Version {
+	v, err := semver.NewVersion(plan.Tag)
+	if err != nil {
+		return nil
+	}
+
+	return v
+}
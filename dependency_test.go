@@ -0,0 +1,244 @@
+package github_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	goGitHub "github.com/google/go-github/v35/github"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	github "github.com/nhatthm/plugin-registry-github"
+)
+
+type testDependencyRelease struct {
+	repository string
+	tag        string
+	// metadata is appended to the metadata of the release.
+	metadata string
+}
+
+// newDependencyService serves the releases of the plugins of the owner from a directory.
+func newDependencyService(t *testing.T, releases ...testDependencyRelease) *github.DirectoryService {
+	t.Helper()
+
+	fs := afero.NewMemMapFs()
+	archive, err := ioutil.ReadFile("resources/fixtures/gzip/my-plugin.tar.gz")
+	require.NoError(t, err)
+
+	for idx, r := range releases {
+		dir := filepath.Join("/mirror", "owner", r.repository, "releases", r.tag)
+		asset := r.repository + ".tar.gz"
+
+		release, err := json.Marshal(&goGitHub.RepositoryRelease{
+			ID:          goGitHub.Int64(int64(idx + 1)),
+			TagName:     goGitHub.String(r.tag),
+			PublishedAt: &goGitHub.Timestamp{Time: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(idx) * time.Hour)},
+			Assets: []*goGitHub.ReleaseAsset{{
+				ID:   goGitHub.Int64(int64(100 + idx)),
+				Name: goGitHub.String(asset),
+				Size: goGitHub.Int(len(archive)),
+			}},
+		})
+		require.NoError(t, err)
+
+		metadata := fmt.Sprintf("name: %s\nartifacts:\n  %s/%s:\n    file: %s\n%s",
+			r.repository, runtime.GOOS, runtime.GOARCH, asset, r.metadata)
+
+		require.NoError(t, afero.WriteFile(fs, filepath.Join(dir, "release.json"), release, 0o644))
+		require.NoError(t, afero.WriteFile(fs, filepath.Join(dir, ".plugin.registry.yaml"), []byte(metadata), 0o644))
+		require.NoError(t, afero.WriteFile(fs, filepath.Join(dir, asset), archive, 0o644))
+	}
+
+	return github.NewDirectoryService(fs, "/mirror")
+}
+
+func dependsOn(deps ...string) string {
+	out := "dependencies:\n"
+
+	for idx := 0; idx < len(deps); idx += 2 {
+		out += fmt.Sprintf("  - source: github.com/owner/%s\n    version: %q\n", deps[idx], deps[idx+1])
+	}
+
+	return out
+}
+
+func TestInstaller_ResolveDependencies(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario      string
+		releases      []testDependencyRelease
+		expected      []string
+		expectedErr   error
+		expectedError string
+	}{
+		{
+			scenario: "no dependencies",
+			releases: []testDependencyRelease{
+				{repository: "app", tag: "v1.0.0"},
+			},
+			expected: []string{"app@v1.0.0"},
+		},
+		{
+			scenario: "chain",
+			releases: []testDependencyRelease{
+				{repository: "app", tag: "v1.0.0", metadata: dependsOn("lib", "^1.0")},
+				{repository: "lib", tag: "v1.0.0"},
+				{repository: "lib", tag: "v1.1.0", metadata: dependsOn("base", "")},
+				{repository: "lib", tag: "v2.0.0"},
+				{repository: "base", tag: "v0.1.0"},
+			},
+			expected: []string{"base@v0.1.0", "lib@v1.1.0", "app@v1.0.0"},
+		},
+		{
+			scenario: "diamond",
+			releases: []testDependencyRelease{
+				{repository: "app", tag: "v1.0.0", metadata: dependsOn("a", "", "b", "")},
+				{repository: "a", tag: "v1.0.0", metadata: dependsOn("lib", "^1.0")},
+				{repository: "b", tag: "v1.0.0", metadata: dependsOn("lib", ">= 1.1, < 1.3")},
+				{repository: "lib", tag: "v1.0.0"},
+				{repository: "lib", tag: "v1.2.0"},
+				{repository: "lib", tag: "v1.3.0"},
+			},
+			expected: []string{"lib@v1.2.0", "a@v1.0.0", "b@v1.0.0", "app@v1.0.0"},
+		},
+		{
+			scenario: "selected again",
+			releases: []testDependencyRelease{
+				{repository: "app", tag: "v1.0.0", metadata: dependsOn("lib", "", "z", "")},
+				{repository: "lib", tag: "v1.0.0"},
+				{repository: "lib", tag: "v2.0.0", metadata: dependsOn("extra", "")},
+				{repository: "z", tag: "v1.0.0", metadata: dependsOn("lib", "< 2")},
+				{repository: "extra", tag: "v1.0.0"},
+			},
+			expected: []string{"lib@v1.0.0", "z@v1.0.0", "app@v1.0.0"},
+		},
+		{
+			scenario: "conflict",
+			releases: []testDependencyRelease{
+				{repository: "app", tag: "v1.0.0", metadata: dependsOn("a", "", "b", "")},
+				{repository: "a", tag: "v1.0.0", metadata: dependsOn("lib", "^1")},
+				{repository: "b", tag: "v1.0.0", metadata: dependsOn("lib", "^2")},
+				{repository: "lib", tag: "v1.0.0"},
+				{repository: "lib", tag: "v2.0.0"},
+			},
+			expectedErr:   github.ErrDependencyConflict,
+			expectedError: `could not resolve dependencies: dependency conflict: no version of owner/lib satisfies: owner/a@v1.0.0 requires "^1", owner/b@v1.0.0 requires "^2"`,
+		},
+		{
+			scenario: "no release",
+			releases: []testDependencyRelease{
+				{repository: "app", tag: "v1.0.0", metadata: dependsOn("lib", "^3")},
+				{repository: "lib", tag: "v1.0.0"},
+			},
+			expectedErr:   github.ErrDependencyConflict,
+			expectedError: `could not resolve dependencies: dependency conflict: no version of owner/lib satisfies: owner/app@v1.0.0 requires "^3"`,
+		},
+		{
+			scenario: "conflict with the plugin",
+			releases: []testDependencyRelease{
+				{repository: "app", tag: "v1.0.0", metadata: dependsOn("a", "")},
+				{repository: "a", tag: "v1.0.0", metadata: dependsOn("app", "^2")},
+			},
+			expectedErr:   github.ErrDependencyConflict,
+			expectedError: `could not resolve dependencies: dependency conflict: no version of owner/app satisfies: owner/a@v1.0.0 requires "^2"`,
+		},
+		{
+			scenario: "cycle",
+			releases: []testDependencyRelease{
+				{repository: "app", tag: "v1.0.0", metadata: dependsOn("a", "")},
+				{repository: "a", tag: "v1.0.0", metadata: dependsOn("b", "")},
+				{repository: "b", tag: "v1.0.0", metadata: dependsOn("a", "")},
+			},
+			expectedErr:   github.ErrDependencyCycle,
+			expectedError: `could not resolve dependencies: dependency cycle: owner/a -> owner/b -> owner/a`,
+		},
+		{
+			scenario: "cycle with the plugin",
+			releases: []testDependencyRelease{
+				{repository: "app", tag: "v1.0.0", metadata: dependsOn("a", "")},
+				{repository: "a", tag: "v1.0.0", metadata: dependsOn("app", "^1")},
+			},
+			expectedErr:   github.ErrDependencyCycle,
+			expectedError: `could not resolve dependencies: dependency cycle: owner/app -> owner/a -> owner/app`,
+		},
+		{
+			scenario: "invalid constraint",
+			releases: []testDependencyRelease{
+				{repository: "app", tag: "v1.0.0", metadata: dependsOn("lib", "not a version")},
+			},
+			expectedErr:   github.ErrInvalidVersion,
+			expectedError: `could not resolve dependencies: owner/app@v1.0.0: invalid constraint "not a version" of github.com/owner/lib: invalid version`,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			i := github.NewInstaller(
+				github.WithService(newDependencyService(t, tc.releases...)),
+				github.WithMetadataSources(github.MetadataFromContents),
+			)
+
+			plans, err := i.ResolveDependencies(context.Background(), "github.com/owner/app@v1.0.0")
+
+			if tc.expectedErr != nil {
+				assert.EqualError(t, err, tc.expectedError)
+				assert.True(t, errors.Is(err, tc.expectedErr))
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			actual := make([]string, 0, len(plans))
+
+			for _, p := range plans {
+				actual = append(actual, p.Repository+"@"+p.Tag)
+			}
+
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestInstaller_Install_Dependencies(t *testing.T) {
+	t.Parallel()
+
+	dest := t.TempDir()
+
+	i := github.NewInstaller(
+		github.WithFs(afero.NewOsFs()),
+		github.WithService(newDependencyService(t,
+			testDependencyRelease{repository: "app", tag: "v1.0.0", metadata: dependsOn("lib", "^1")},
+			testDependencyRelease{repository: "lib", tag: "v1.0.0"},
+			testDependencyRelease{repository: "lib", tag: "v1.1.0"},
+		)),
+		github.WithMetadataSources(github.MetadataFromContents),
+	)
+
+	p, err := i.Install(context.Background(), dest, "github.com/owner/app@v1.0.0")
+	require.NoError(t, err)
+
+	assert.Equal(t, "app", p.Name)
+
+	prov, err := github.ReadProvenance(afero.NewOsFs(), filepath.Join(dest, "lib"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "github.com/owner/lib", prov.Source)
+	assert.Equal(t, "v1.1.0", prov.Tag)
+
+	_, err = github.ReadProvenance(afero.NewOsFs(), filepath.Join(dest, "app"))
+	assert.NoError(t, err)
+}
@@ -0,0 +1,268 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/google/go-github/v35/github"
+	"github.com/spf13/afero"
+)
+
+const (
+	releasesDir     = "releases"
+	releaseFile     = "release.json"
+	defaultPageSize = 30
+)
+
+// ErrOutsideDirectory indicates that the path is outside of the mirror directory.
+var ErrOutsideDirectory = errors.New("path is outside of the directory")
+
+var (
+	_ RepositoryService = (*DirectoryService)(nil)
+	_ CommitService     = (*DirectoryService)(nil)
+	_ ReleaseService    = (*DirectoryService)(nil)
+)
+
+// commitSHAPattern matches the sha of a commit.
+var commitSHAPattern = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// DirectoryService is a RepositoryService that serves the releases from a directory laid out as
+// owner/repository/releases/<tag>/{release.json,<assets>,.plugin.registry.yaml}, see Installer.Mirror().
+type DirectoryService struct {
+	fs  afero.Fs
+	dir string
+}
+
+// DownloadContents satisfies RepositoryService. Only the files of a release could be downloaded, so the ref must be a
+// release tag.
+func (s *DirectoryService) DownloadContents(_ context.Context, owner, repo, file string, opts *github.RepositoryContentGetOptions) (io.ReadCloser, *github.Response, error) {
+	if opts == nil || opts.Ref == "" {
+		return nil, nil, fmt.Errorf("%s: %w", file, os.ErrNotExist)
+	}
+
+	path, err := s.releaseFile(owner, repo, opts.Ref, file)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	f, err := s.fs.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return f, newDirectoryResponse(), nil
+}
+
+// GetLatestRelease satisfies RepositoryService. The latest release is the most recently published release that is
+// neither a draft nor a prerelease.
+func (s *DirectoryService) GetLatestRelease(_ context.Context, owner, repo string) (*github.RepositoryRelease, *github.Response, error) {
+	releases, err := s.releases(owner, repo)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, r := range releases {
+		if !r.GetDraft() && !r.GetPrerelease() {
+			return r, newDirectoryResponse(), nil
+		}
+	}
+
+	return nil, nil, fmt.Errorf("latest release of %s/%s: %w", owner, repo, os.ErrNotExist)
+}
+
+// GetReleaseByTag satisfies RepositoryService.
+func (s *DirectoryService) GetReleaseByTag(_ context.Context, owner, repo, tag string) (*github.RepositoryRelease, *github.Response, error) {
+	r, err := s.release(owner, repo, tag)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return r, newDirectoryResponse(), nil
+}
+
+// DownloadReleaseAsset satisfies RepositoryService.
+func (s *DirectoryService) DownloadReleaseAsset(_ context.Context, owner, repo string, id int64, _ *http.Client) (io.ReadCloser, string, error) {
+	releases, err := s.releases(owner, repo)
+	if err != nil {
+		return nil, "", err
+	}
+
+	for _, r := range releases {
+		for _, a := range r.Assets {
+			if a.GetID() != id {
+				continue
+			}
+
+			path, err := s.releaseFile(owner, repo, r.GetTagName(), a.GetName())
+			if err != nil {
+				return nil, "", err
+			}
+
+			f, err := s.fs.Open(path)
+			if err != nil {
+				return nil, "", err
+			}
+
+			return f, "", nil
+		}
+	}
+
+	return nil, "", fmt.Errorf("asset %d of %s/%s: %w", id, owner, repo, os.ErrNotExist)
+}
+
+// GetCommitSHA1 satisfies CommitService. The ref must be a release tag, the commit is the target commitish of the
+// release if it is a sha, that is recorded by Installer.Mirror().
+func (s *DirectoryService) GetCommitSHA1(_ context.Context, owner, repo, ref, _ string) (string, *github.Response, error) {
+	r, err := s.release(owner, repo, ref)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if !commitSHAPattern.MatchString(r.GetTargetCommitish()) {
+		return "", nil, fmt.Errorf("commit of %s/%s@%s: %w", owner, repo, ref, os.ErrNotExist)
+	}
+
+	return r.GetTargetCommitish(), newDirectoryResponse(), nil
+}
+
+// ListReleases satisfies ReleaseService. The releases are sorted by the published date, the newest first.
+func (s *DirectoryService) ListReleases(_ context.Context, owner, repo string, opts *github.ListOptions) ([]*github.RepositoryRelease, *github.Response, error) {
+	releases, err := s.releases(owner, repo)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	page, perPage := 1, defaultPageSize
+
+	if opts != nil {
+		if opts.Page > 0 {
+			page = opts.Page
+		}
+
+		if opts.PerPage > 0 {
+			perPage = opts.PerPage
+		}
+	}
+
+	resp := newDirectoryResponse()
+	start := (page - 1) * perPage
+
+	if start >= len(releases) {
+		return nil, resp, nil
+	}
+
+	end := start + perPage
+
+	if end < len(releases) {
+		resp.NextPage = page + 1
+	} else {
+		end = len(releases)
+	}
+
+	return releases[start:end], resp, nil
+}
+
+func (s *DirectoryService) releases(owner, repo string) ([]*github.RepositoryRelease, error) {
+	dir, err := s.releaseFile(owner, repo, "", "")
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := afero.ReadDir(s.fs, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	releases := make([]*github.RepositoryRelease, 0, len(entries))
+
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+
+		tag, err := url.PathUnescape(e.Name())
+		if err != nil {
+			continue
+		}
+
+		r, err := s.release(owner, repo, tag)
+		if err != nil {
+			return nil, err
+		}
+
+		releases = append(releases, r)
+	}
+
+	sort.SliceStable(releases, func(i, j int) bool {
+		return releaseTime(releases[i]).After(releaseTime(releases[j]))
+	})
+
+	return releases, nil
+}
+
+func (s *DirectoryService) release(owner, repo, tag string) (*github.RepositoryRelease, error) {
+	path, err := s.releaseFile(owner, repo, tag, releaseFile)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := afero.ReadFile(s.fs, path)
+	if err != nil {
+		return nil, err
+	}
+
+	var r github.RepositoryRelease
+
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+func (s *DirectoryService) releaseFile(owner, repo, tag, name string) (string, error) {
+	base := filepath.Join(s.dir, owner, repo, releasesDir)
+	dir := releaseDir(s.dir, owner, repo, tag)
+	path := filepath.Join(dir, filepath.FromSlash(name))
+
+	if !isSubPath(s.dir, base) || !isSubPath(base, dir) || !isSubPath(dir, path) {
+		return "", fmt.Errorf("%s: %w", path, ErrOutsideDirectory)
+	}
+
+	return path, nil
+}
+
+// releaseDir returns the directory of a release. The tag is escaped because it could contain slashes.
+func releaseDir(dir, owner, repo, tag string) string {
+	return filepath.Join(dir, owner, repo, releasesDir, url.PathEscape(tag))
+}
+
+func releaseTime(r *github.RepositoryRelease) time.Time {
+	if r.PublishedAt != nil {
+		return r.PublishedAt.Time
+	}
+
+	return r.GetCreatedAt().Time
+}
+
+func newDirectoryResponse() *github.Response {
+	return &github.Response{Response: &http.Response{StatusCode: http.StatusOK}}
+}
+
+// NewDirectoryService creates a new RepositoryService that serves the releases from a directory.
+func NewDirectoryService(fs afero.Fs, dir string) *DirectoryService {
+	return &DirectoryService{
+		fs:  fs,
+		dir: dir,
+	}
+}
@@ -0,0 +1,309 @@
+package github_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	goGitHub "github.com/google/go-github/v35/github"
+	"github.com/nhatthm/aferoassert"
+	"github.com/nhatthm/plugin-registry/plugin"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	github "github.com/nhatthm/plugin-registry-github"
+	"github.com/nhatthm/plugin-registry-github/mock/service"
+)
+
+func mockMirrorService(tag string, publishedAt time.Time, prerelease bool) func(s *service.RepositoryService) {
+	return func(s *service.RepositoryService) {
+		r := newReleaseWithArtifact(tag, "my-plugin.tar.gz")
+		r.PublishedAt = &goGitHub.Timestamp{Time: publishedAt}
+		r.Prerelease = &prerelease
+		r.Assets[0].ID = int64Ptr(publishedAt.Unix())
+
+		s.On("GetReleaseByTag", mock.Anything, "owner", "my-plugin", tag).
+			Return(r, nil, nil)
+
+		s.On("DownloadContents", mock.Anything, "owner", "my-plugin", ".plugin.registry.yaml",
+			&goGitHub.RepositoryContentGetOptions{Ref: tag}).
+			Return(newMetadataFileFromStringf("name: my-plugin\nartifacts:\n  %s/%s:\n    file: my-plugin.tar.gz\n",
+				runtime.GOOS, runtime.GOARCH), nil, nil)
+
+		mockMetadataNotFound(s, "my-plugin")
+
+		s.On("DownloadReleaseAsset", mock.Anything, "owner", "my-plugin", publishedAt.Unix(), http.DefaultClient).
+			Return(newShadowedFile("my-plugin.tar.gz", "resources/fixtures/gzip/my-plugin.tar.gz"), "", nil)
+	}
+}
+
+func TestInstaller_Mirror_Error(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario      string
+		mockService   service.RepositoryServiceMocker
+		source        string
+		expectedError string
+	}{
+		{
+			scenario:      "could not parse url",
+			source:        "/tmp/plugin.zip",
+			expectedError: "could not parse url: not a github url",
+		},
+		{
+			scenario: "could not get metadata",
+			source:   "github.com/owner/my-plugin@v1.4.2",
+			mockService: service.MockRepositoryService(func(s *service.RepositoryService) {
+				s.On("GetReleaseByTag", mock.Anything, "owner", "my-plugin", "v1.4.2").
+					Return(newReleaseWithArtifact("v1.4.2", "my-plugin.tar.gz"), nil, nil)
+
+				s.On("DownloadContents", mock.Anything, "owner", "my-plugin", ".plugin.registry.yaml", mock.Anything).
+					Return(nil, nil, errors.New("download error"))
+			}),
+			expectedError: "could not get plugin metadata: download error",
+		},
+		{
+			scenario: "could not download artifact",
+			source:   "github.com/owner/my-plugin@v1.4.2",
+			mockService: service.MockRepositoryService(func(s *service.RepositoryService) {
+				s.On("GetReleaseByTag", mock.Anything, "owner", "my-plugin", "v1.4.2").
+					Return(newReleaseWithArtifact("v1.4.2", "my-plugin.tar.gz"), nil, nil)
+
+				s.On("DownloadContents", mock.Anything, "owner", "my-plugin", ".plugin.registry.yaml", mock.Anything).
+					Return(newMetadataFileFromString("name: my-plugin"), nil, nil)
+
+				mockMetadataNotFound(s, "my-plugin")
+
+				s.On("DownloadReleaseAsset", mock.Anything, "owner", "my-plugin", int64(42), http.DefaultClient).
+					Return(nil, "", errors.New("download error"))
+			}),
+			expectedError: "could not download artifact: download error",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			if tc.mockService == nil {
+				tc.mockService = service.NoMockRepositoryService
+			}
+
+			i := github.NewInstaller(
+				github.WithFs(afero.NewMemMapFs()),
+				github.WithService(tc.mockService(t)),
+			)
+
+			err := i.Mirror(context.Background(), []string{tc.source}, "/mirror")
+
+			assert.EqualError(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestInstaller_Mirror_SkippedAssets(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	s := service.MockRepositoryService(func(s *service.RepositoryService) {
+		s.On("GetReleaseByTag", mock.Anything, "owner", "my-plugin", "v1.4.2").
+			Return(newRelease("v1.4.2", withAssets("../my-plugin.tar.gz", "release.json", ".plugin.registry.yaml", "my-plugin.tar.gz")), nil, nil)
+
+		s.On("DownloadContents", mock.Anything, "owner", "my-plugin", ".plugin.registry.yaml", mock.Anything).
+			Return(newMetadataFileFromString("name: my-plugin"), nil, nil)
+
+		mockMetadataNotFound(s, "my-plugin")
+
+		s.On("DownloadReleaseAsset", mock.Anything, "owner", "my-plugin", int64(45), http.DefaultClient).
+			Return(newShadowedFile("my-plugin.tar.gz", "resources/fixtures/gzip/my-plugin.tar.gz"), "", nil)
+	})(t)
+
+	i := github.NewInstaller(
+		github.WithFs(fs),
+		github.WithService(s),
+		github.WithMetadataSources(github.MetadataFromContents),
+	)
+
+	err := i.Mirror(context.Background(), []string{"github.com/owner/my-plugin@v1.4.2"}, "/mirror")
+	require.NoError(t, err)
+
+	d := github.NewDirectoryService(fs, "/mirror")
+
+	release, _, err := d.GetReleaseByTag(context.Background(), "owner", "my-plugin", "v1.4.2")
+	require.NoError(t, err)
+
+	names := make([]string, 0, len(release.Assets))
+
+	for _, a := range release.Assets {
+		names = append(names, a.GetName())
+	}
+
+	assert.Equal(t, []string{"my-plugin.tar.gz"}, names)
+
+	r, _, err := d.DownloadContents(context.Background(), "owner", "my-plugin", plugin.MetadataFile,
+		&goGitHub.RepositoryContentGetOptions{Ref: "v1.4.2"})
+	require.NoError(t, err)
+
+	data, err := afero.ReadAll(r)
+	require.NoError(t, err)
+
+	assert.Equal(t, "name: my-plugin", string(data))
+}
+
+func TestDirectoryService(t *testing.T) {
+	t.Parallel()
+
+	day := func(d int) time.Time {
+		return time.Date(2021, time.January, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	fs := afero.NewMemMapFs()
+	s := service.MockRepositoryService(
+		mockMirrorService("v1.0.0", day(1), false),
+		mockMirrorService("v1.1.0", day(2), false),
+		mockMirrorService("v2.0.0-rc.1", day(3), true),
+	)(t)
+
+	err := github.NewInstaller(github.WithFs(fs), github.WithService(s)).
+		Mirror(context.Background(), []string{
+			"github.com/owner/my-plugin@v1.0.0",
+			"github.com/owner/my-plugin@v1.1.0",
+			"github.com/owner/my-plugin@v2.0.0-rc.1",
+		}, "/mirror")
+	require.NoError(t, err)
+
+	aferoassert.FileExists(t, fs, "/mirror/owner/my-plugin/releases/v1.1.0/release.json")
+	aferoassert.FileExists(t, fs, "/mirror/owner/my-plugin/releases/v1.1.0/my-plugin.tar.gz")
+	aferoassert.FileExists(t, fs, "/mirror/owner/my-plugin/releases/v1.1.0/.plugin.registry.yaml")
+
+	ctx := context.Background()
+	d := github.NewDirectoryService(fs, "/mirror")
+
+	t.Run("GetLatestRelease", func(t *testing.T) {
+		t.Parallel()
+
+		r, _, err := d.GetLatestRelease(ctx, "owner", "my-plugin")
+		require.NoError(t, err)
+
+		assert.Equal(t, "v1.1.0", r.GetTagName())
+
+		_, _, err = d.GetLatestRelease(ctx, "owner", "unknown")
+		assert.ErrorIs(t, err, afero.ErrFileNotFound)
+	})
+
+	t.Run("GetReleaseByTag", func(t *testing.T) {
+		t.Parallel()
+
+		r, _, err := d.GetReleaseByTag(ctx, "owner", "my-plugin", "v2.0.0-rc.1")
+		require.NoError(t, err)
+
+		assert.Equal(t, "v2.0.0-rc.1", r.GetTagName())
+		assert.True(t, r.GetPrerelease())
+
+		_, _, err = d.GetReleaseByTag(ctx, "owner", "my-plugin", "v3.0.0")
+		assert.ErrorIs(t, err, afero.ErrFileNotFound)
+
+		_, _, err = d.GetReleaseByTag(ctx, "owner", "my-plugin", "..")
+		assert.ErrorIs(t, err, github.ErrOutsideDirectory)
+	})
+
+	t.Run("ListReleases", func(t *testing.T) {
+		t.Parallel()
+
+		releases, resp, err := d.ListReleases(ctx, "owner", "my-plugin", &goGitHub.ListOptions{PerPage: 2})
+		require.NoError(t, err)
+
+		assert.Len(t, releases, 2)
+		assert.Equal(t, "v2.0.0-rc.1", releases[0].GetTagName())
+		assert.Equal(t, "v1.1.0", releases[1].GetTagName())
+		assert.Equal(t, 2, resp.NextPage)
+
+		releases, resp, err = d.ListReleases(ctx, "owner", "my-plugin", &goGitHub.ListOptions{PerPage: 2, Page: 2})
+		require.NoError(t, err)
+
+		assert.Len(t, releases, 1)
+		assert.Equal(t, "v1.0.0", releases[0].GetTagName())
+		assert.Equal(t, 0, resp.NextPage)
+	})
+
+	t.Run("DownloadContents", func(t *testing.T) {
+		t.Parallel()
+
+		r, _, err := d.DownloadContents(ctx, "owner", "my-plugin", plugin.MetadataFile,
+			&goGitHub.RepositoryContentGetOptions{Ref: "v1.0.0"})
+		require.NoError(t, err)
+
+		data, err := afero.ReadAll(r)
+		require.NoError(t, err)
+
+		assert.Contains(t, string(data), "name: my-plugin")
+
+		_, _, err = d.DownloadContents(ctx, "owner", "my-plugin", "../../../release.json",
+			&goGitHub.RepositoryContentGetOptions{Ref: "v1.0.0"})
+		assert.ErrorIs(t, err, github.ErrOutsideDirectory)
+	})
+
+	t.Run("DownloadReleaseAsset", func(t *testing.T) {
+		t.Parallel()
+
+		r, _, err := d.DownloadReleaseAsset(ctx, "owner", "my-plugin", day(2).Unix(), nil)
+		require.NoError(t, err)
+
+		data, err := afero.ReadAll(r)
+		require.NoError(t, err)
+
+		expected, err := afero.ReadFile(afero.NewOsFs(), "resources/fixtures/gzip/my-plugin.tar.gz")
+		require.NoError(t, err)
+
+		assert.Equal(t, expected, data)
+
+		_, _, err = d.DownloadReleaseAsset(ctx, "owner", "my-plugin", 42, nil)
+		assert.ErrorIs(t, err, afero.ErrFileNotFound)
+	})
+}
+
+func TestIntegrationDirectoryService_Install(t *testing.T) {
+	t.Parallel()
+
+	osFs := afero.NewOsFs()
+	mirrorDir := t.TempDir()
+	dest := t.TempDir()
+
+	err := github.NewInstaller(
+		github.WithFs(osFs),
+		github.WithService(service.MockRepositoryService(mockMirrorService("v1.4.2", time.Now(), false))(t)),
+		github.WithCommitService(service.MockCommitService(func(s *service.CommitService) {
+			s.On("GetCommitSHA1", mock.Anything, "owner", "my-plugin", "v1.4.2", "").
+				Return("6dcb09b5b57875f334f61aebed695e2e4193db5e", nil, nil)
+		})(t)),
+	).Mirror(context.Background(), []string{"github.com/owner/my-plugin@v1.4.2"}, mirrorDir)
+	require.NoError(t, err)
+
+	i := github.NewInstaller(
+		github.WithFs(osFs),
+		github.WithService(github.NewDirectoryService(osFs, mirrorDir)),
+	)
+
+	result, err := i.Install(context.Background(), dest, "github.com/owner/my-plugin")
+	require.NoError(t, err)
+
+	assert.Equal(t, "my-plugin", result.Name)
+	assert.Equal(t, "1.4.2", result.Version)
+
+	file := filepath.Join(dest, result.Name, result.Name)
+
+	aferoassert.FileContent(t, osFs, file, "#!/bin/bash\n")
+
+	provenance, err := github.ReadProvenance(osFs, filepath.Join(dest, result.Name))
+	require.NoError(t, err)
+
+	assert.Equal(t, "6dcb09b5b57875f334f61aebed695e2e4193db5e", provenance.Commit)
+}
@@ -0,0 +1,9 @@
+//go:build !linux && !darwin
+// +build !linux,!darwin
+
+package github
+
+// diskFree is not supported on this platform, the free space is not checked.
+func diskFree(string) (uint64, bool) {
+	return 0, false
+}
@@ -0,0 +1,17 @@
+//go:build linux || darwin
+// +build linux darwin
+
+package github
+
+import "syscall"
+
+// diskFree returns the free space of the filesystem at the path that is available to the current user.
+func diskFree(path string) (uint64, bool) {
+	var st syscall.Statfs_t
+
+	if err := syscall.Statfs(path, &st); err != nil {
+		return 0, false
+	}
+
+	return st.Bavail * uint64(st.Bsize), true
+}
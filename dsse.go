@@ -0,0 +1,266 @@
+package github
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// inTotoPayloadType is the payload type of an in-toto statement in a DSSE envelope.
+const inTotoPayloadType = "application/vnd.in-toto+json"
+
+var errInvalidSignature = errors.New("invalid signature")
+
+// dsseEnvelope is a Dead Simple Signing Envelope, see https://github.com/secure-systems-lab/dsse.
+type dsseEnvelope struct {
+	PayloadType string          `json:"payloadType"`
+	Payload     string          `json:"payload"`
+	Signatures  []dsseSignature `json:"signatures"`
+}
+
+// dsseSignature is a signature of a DSSE envelope. The certificate is set by the slsa-github-generator.
+type dsseSignature struct {
+	KeyID string `json:"keyid"`
+	Sig   string `json:"sig"`
+	Cert  string `json:"cert,omitempty"`
+}
+
+// sigstoreBundle is the part of a Sigstore bundle that is needed to verify its DSSE envelope offline.
+type sigstoreBundle struct {
+	MediaType            string `json:"mediaType"`
+	VerificationMaterial struct {
+		Certificate          *sigstoreCertificate `json:"certificate"`
+		X509CertificateChain *struct {
+			Certificates []sigstoreCertificate `json:"certificates"`
+		} `json:"x509CertificateChain"`
+		TlogEntries               []tlogEntry `json:"tlogEntries"`
+		TimestampVerificationData struct {
+			RFC3161Timestamps []struct {
+				SignedTimestamp string `json:"signedTimestamp"`
+			} `json:"rfc3161Timestamps"`
+		} `json:"timestampVerificationData"`
+	} `json:"verificationMaterial"`
+	DSSEEnvelope *dsseEnvelope `json:"dsseEnvelope"`
+}
+
+type sigstoreCertificate struct {
+	RawBytes string `json:"rawBytes"`
+}
+
+// signedAttestation is a DSSE envelope with the certificate chain of its signer, and the proofs of the signing time, if
+// any.
+type signedAttestation struct {
+	envelope    dsseEnvelope
+	chain       []*x509.Certificate
+	tlogEntries []tlogEntry
+	timestamps  [][]byte
+}
+
+// parseAttestations parses a Sigstore bundle, a DSSE envelope, or a JSON lines file of them.
+func parseAttestations(data []byte) ([]signedAttestation, error) {
+	data = bytes.TrimSpace(data)
+
+	if json.Valid(data) {
+		a, err := parseAttestation(data)
+		if err != nil {
+			return nil, err
+		}
+
+		return []signedAttestation{a}, nil
+	}
+
+	var attestations []signedAttestation
+
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+
+		a, err := parseAttestation(line)
+		if err != nil {
+			return nil, err
+		}
+
+		attestations = append(attestations, a)
+	}
+
+	return attestations, nil
+}
+
+func parseAttestation(data []byte) (signedAttestation, error) {
+	var b sigstoreBundle
+
+	if err := json.Unmarshal(data, &b); err != nil {
+		return signedAttestation{}, err
+	}
+
+	if b.DSSEEnvelope == nil {
+		// Not a bundle, but an envelope.
+		var e dsseEnvelope
+
+		if err := json.Unmarshal(data, &e); err != nil {
+			return signedAttestation{}, err
+		}
+
+		return signedAttestation{envelope: e}, nil
+	}
+
+	var raws []sigstoreCertificate
+
+	if c := b.VerificationMaterial.Certificate; c != nil {
+		raws = append(raws, *c)
+	}
+
+	if c := b.VerificationMaterial.X509CertificateChain; c != nil {
+		raws = append(raws, c.Certificates...)
+	}
+
+	chain := make([]*x509.Certificate, 0, len(raws))
+
+	for _, raw := range raws {
+		der, err := decodeBase64(raw.RawBytes)
+		if err != nil {
+			return signedAttestation{}, fmt.Errorf("could not decode certificate: %w", err)
+		}
+
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return signedAttestation{}, err
+		}
+
+		chain = append(chain, cert)
+	}
+
+	timestamps := make([][]byte, 0, len(b.VerificationMaterial.TimestampVerificationData.RFC3161Timestamps))
+
+	for _, ts := range b.VerificationMaterial.TimestampVerificationData.RFC3161Timestamps {
+		der, err := decodeBase64(ts.SignedTimestamp)
+		if err != nil {
+			return signedAttestation{}, fmt.Errorf("could not decode timestamp: %w", err)
+		}
+
+		timestamps = append(timestamps, der)
+	}
+
+	return signedAttestation{
+		envelope:    *b.DSSEEnvelope,
+		chain:       chain,
+		tlogEntries: b.VerificationMaterial.TlogEntries,
+		timestamps:  timestamps,
+	}, nil
+}
+
+// signatureChain returns the certificate chain of the signature, the certificate of the signature has precedence over
+// the one of the bundle.
+func (a signedAttestation) signatureChain(s dsseSignature) ([]*x509.Certificate, error) {
+	if s.Cert == "" {
+		return a.chain, nil
+	}
+
+	var chain []*x509.Certificate
+
+	rest := []byte(s.Cert)
+
+	for {
+		var block *pem.Block
+
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+
+		chain = append(chain, cert)
+	}
+
+	if len(chain) == 0 {
+		return nil, errors.New("could not decode certificate") // nolint: goerr113
+	}
+
+	return chain, nil
+}
+
+// pae returns the pre-authentication encoding of the payload, that is signed.
+func pae(payloadType string, payload []byte) []byte {
+	return []byte(fmt.Sprintf("DSSEv1 %d %s %d %s", len(payloadType), payloadType, len(payload), payload))
+}
+
+// verifySignature verifies a signature of the data with a public key.
+func verifySignature(pub crypto.PublicKey, data, sig []byte) error {
+	switch pub := pub.(type) {
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(pub, ecdsaDigest(pub.Curve, data), sig) {
+			return errInvalidSignature
+		}
+
+		return nil
+
+	case *rsa.PublicKey:
+		digest := sha256.Sum256(data)
+
+		if rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], sig) == nil {
+			return nil
+		}
+
+		if rsa.VerifyPSS(pub, crypto.SHA256, digest[:], sig, nil) == nil {
+			return nil
+		}
+
+		return errInvalidSignature
+
+	case ed25519.PublicKey:
+		if !ed25519.Verify(pub, data, sig) {
+			return errInvalidSignature
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("%w: unsupported key type %T", errInvalidSignature, pub)
+}
+
+func ecdsaDigest(curve elliptic.Curve, data []byte) []byte {
+	switch curve.Params().BitSize {
+	case 384:
+		digest := sha512.Sum384(data)
+
+		return digest[:]
+
+	case 521:
+		digest := sha512.Sum512(data)
+
+		return digest[:]
+	}
+
+	digest := sha256.Sum256(data)
+
+	return digest[:]
+}
+
+// decodeBase64 decodes the standard or the url-safe base64, with or without padding.
+func decodeBase64(s string) ([]byte, error) {
+	s = strings.TrimRight(s, "=")
+
+	if strings.ContainsAny(s, "-_") {
+		return base64.RawURLEncoding.DecodeString(s)
+	}
+
+	return base64.RawStdEncoding.DecodeString(s)
+}
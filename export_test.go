@@ -0,0 +1,7 @@
+package github
+
+// ReadMetadata exposes readMetadata to the tests.
+var ReadMetadata = (*Installer).readMetadata
+
+// MaxMetadataSize exposes maxMetadataSize to the tests.
+const MaxMetadataSize = maxMetadataSize
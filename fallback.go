@@ -0,0 +1,99 @@
+package github
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/go-github/v35/github"
+	"github.com/nhatthm/plugin-registry/plugin"
+)
+
+// ArchFallbacks maps a platform to the platforms, in order, whose artifacts could run on it when the plugin does not
+// publish an artifact for the platform itself.
+type ArchFallbacks map[plugin.ArtifactIdentifier][]plugin.ArtifactIdentifier
+
+// DefaultArchFallbacks returns the default fallbacks: the amd64 artifacts run under emulation on darwin/arm64 and
+// windows/arm64, and a universal binary runs on both darwin archs.
+func DefaultArchFallbacks() ArchFallbacks {
+	return ArchFallbacks{
+		plugin.NewArtifactIdentifier("darwin", "arm64"): {
+			plugin.NewArtifactIdentifier("darwin", "universal"),
+			plugin.NewArtifactIdentifier("darwin", "amd64"),
+		},
+		plugin.NewArtifactIdentifier("darwin", "amd64"): {
+			plugin.NewArtifactIdentifier("darwin", "universal"),
+		},
+		plugin.NewArtifactIdentifier("windows", "arm64"): {
+			plugin.NewArtifactIdentifier("windows", "amd64"),
+		},
+	}
+}
+
+// WithArchFallbacks sets the fallbacks of the artifact platforms, DefaultArchFallbacks() is used by default. An empty
+// map disables the fallbacks.
+func WithArchFallbacks(fallbacks ArchFallbacks) Option {
+	return func(i *Installer) {
+		i.archFallbacks = fallbacks
+	}
+}
+
+// findTargetAsset finds the asset of the artifact of the target platform, or of its first fallback that is in the
+// release.
+func (i *Installer) findTargetAsset(ctx context.Context, p *plugin.Plugin, r *github.RepositoryRelease) (plugin.Artifact, *github.ReleaseAsset, error) {
+	target := i.targetOf(ctx)
+
+	artifact, asset, err := findPlatformAsset(p, r, target, targetArtifact(p, target))
+	if err == nil {
+		return artifact, asset, nil
+	}
+
+	for _, id := range i.archFallbacks[target] {
+		a, ok := p.Artifacts[id]
+		if !ok {
+			// Use the artifact of the target as a template, e.g. `${name}-${os}-${arch}.tar.gz`.
+			a = targetArtifact(p, target)
+		}
+
+		fallback, fallbackAsset, fallbackErr := findPlatformAsset(p, r, id, a)
+		if fallbackErr != nil {
+			continue
+		}
+
+		i.logger.Info(ctx, "using fallback artifact",
+			"target", target.String(),
+			"fallback", id.String(),
+			"artifact", fallback.File,
+		)
+
+		return fallback, fallbackAsset, nil
+	}
+
+	return artifact, nil, err
+}
+
+// findPlatformAsset finds the asset of the artifact of a platform.
+func findPlatformAsset(p *plugin.Plugin, r *github.RepositoryRelease, id plugin.ArtifactIdentifier, a plugin.Artifact) (plugin.Artifact, *github.ReleaseAsset, error) {
+	artifact := resolveArtifact(p, a, id)
+
+	asset, err := findAsset(r, artifact.File)
+	if err != nil {
+		return artifact, nil, err
+	}
+
+	return artifact, asset, nil
+}
+
+// resolveArtifact replaces the placeholders in the artifact definition, like plugin.ResolveArtifact() but for the
+// platform instead of the runtime.
+func resolveArtifact(p *plugin.Plugin, a plugin.Artifact, id plugin.ArtifactIdentifier) plugin.Artifact {
+	r := strings.NewReplacer(
+		"${name}", p.Name,
+		"${version}", p.Version,
+		"${os}", id.OS,
+		"${arch}", id.Arch,
+	)
+
+	a.File = r.Replace(a.File)
+
+	return a
+}
@@ -0,0 +1,151 @@
+package github_test
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/bool64/ctxd"
+	"github.com/nhatthm/plugin-registry/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	github "github.com/nhatthm/plugin-registry-github"
+	"github.com/nhatthm/plugin-registry-github/mock/service"
+)
+
+func TestInstaller_Resolve_ArchFallbacks(t *testing.T) {
+	t.Parallel()
+
+	runtimeID := plugin.RuntimeArtifactIdentifier()
+	fallbacks := github.ArchFallbacks{
+		runtimeID: {
+			plugin.NewArtifactIdentifier(runtime.GOOS, "universal"),
+			plugin.NewArtifactIdentifier(runtime.GOOS, "386"),
+		},
+	}
+
+	testCases := []struct {
+		scenario          string
+		metadata          string
+		assets            []string
+		fallbacks         github.ArchFallbacks
+		expectedAssetName string
+		expectedFallback  string
+		expectedError     string
+	}{
+		{
+			scenario:          "runtime artifact",
+			metadata:          "artifacts:\n  %OS%/%ARCH%:\n    file: my-plugin-runtime.tar.gz\n  %OS%/386:\n    file: my-plugin-386.tar.gz\n",
+			assets:            []string{"my-plugin-386.tar.gz", "my-plugin-runtime.tar.gz"},
+			fallbacks:         fallbacks,
+			expectedAssetName: "my-plugin-runtime.tar.gz",
+		},
+		{
+			scenario:          "declared fallback",
+			metadata:          "artifacts:\n  %OS%/386:\n    file: my-plugin-386.tar.gz\n",
+			assets:            []string{"my-plugin-386.tar.gz"},
+			fallbacks:         fallbacks,
+			expectedAssetName: "my-plugin-386.tar.gz",
+			expectedFallback:  runtime.GOOS + "/386",
+		},
+		{
+			scenario:          "templated fallback",
+			metadata:          "artifacts:\n  %OS%:\n    file: my-plugin-${os}-${arch}.tar.gz\n",
+			assets:            []string{"my-plugin-" + runtime.GOOS + "-386.tar.gz", "my-plugin-" + runtime.GOOS + "-universal.tar.gz"},
+			fallbacks:         fallbacks,
+			expectedAssetName: "my-plugin-" + runtime.GOOS + "-universal.tar.gz",
+			expectedFallback:  runtime.GOOS + "/universal",
+		},
+		{
+			scenario:      "no fallback in release",
+			metadata:      "artifacts:\n  %OS%/386:\n    file: my-plugin-386.tar.gz\n",
+			assets:        []string{"my-plugin-arm.tar.gz"},
+			fallbacks:     fallbacks,
+			expectedError: "could not find artifact: artifact not found",
+		},
+		{
+			scenario:      "fallbacks are disabled",
+			metadata:      "artifacts:\n  %OS%/386:\n    file: my-plugin-386.tar.gz\n",
+			assets:        []string{"my-plugin-386.tar.gz"},
+			fallbacks:     github.ArchFallbacks{},
+			expectedError: "could not find artifact: artifact not found",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			metadata := "name: my-plugin\n" + tc.metadata
+			metadata = strings.NewReplacer("%OS%", runtime.GOOS, "%ARCH%", runtime.GOARCH).Replace(metadata)
+
+			s := service.MockRepositoryService(func(s *service.RepositoryService) {
+				s.On("GetReleaseByTag", mock.Anything, "owner", "my-plugin", "v1.4.2").
+					Return(newRelease("v1.4.2", withAssets(tc.assets...)), nil, nil)
+
+				s.On("DownloadContents", mock.Anything, "owner", "my-plugin", ".plugin.registry.yaml", mock.Anything).
+					Return(newMetadataFileFromString(metadata), nil, nil)
+
+				mockMetadataNotFound(s, "my-plugin")
+			})(t)
+
+			logger := &ctxd.LoggerMock{}
+
+			i := github.NewInstaller(
+				github.WithService(s),
+				github.WithMetadataSources(github.MetadataFromContents),
+				github.WithArchFallbacks(tc.fallbacks),
+				github.WithLogger(logger),
+			)
+
+			plan, err := i.Resolve(context.Background(), "github.com/owner/my-plugin@v1.4.2")
+
+			var fallback interface{}
+
+			for _, e := range logger.LoggedEntries {
+				if e.Message == "using fallback artifact" {
+					fallback = e.Data["fallback"]
+
+					assert.Equal(t, runtimeID.String(), e.Data["target"])
+				}
+			}
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				assert.True(t, errors.Is(err, github.ErrArtifactNotFound))
+				assert.Nil(t, fallback)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedAssetName, plan.AssetName)
+
+			if tc.expectedFallback == "" {
+				assert.Nil(t, fallback)
+			} else {
+				assert.Equal(t, tc.expectedFallback, fallback)
+			}
+		})
+	}
+}
+
+func TestDefaultArchFallbacks(t *testing.T) {
+	t.Parallel()
+
+	fallbacks := github.DefaultArchFallbacks()
+
+	assert.Equal(t, []plugin.ArtifactIdentifier{
+		plugin.NewArtifactIdentifier("darwin", "universal"),
+		plugin.NewArtifactIdentifier("darwin", "amd64"),
+	}, fallbacks[plugin.NewArtifactIdentifier("darwin", "arm64")])
+
+	assert.Equal(t, []plugin.ArtifactIdentifier{
+		plugin.NewArtifactIdentifier("windows", "amd64"),
+	}, fallbacks[plugin.NewArtifactIdentifier("windows", "arm64")])
+}
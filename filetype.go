@@ -0,0 +1,342 @@
+package github
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/bool64/ctxd"
+	"github.com/google/go-github/v35/github"
+	"github.com/nhatthm/plugin-registry/plugin"
+)
+
+// sniffLength is the number of bytes that are needed to detect the type of a file. The magic of a tar file is at the
+// offset 257, and the signature of a PE file is usually in the first few hundred bytes.
+const sniffLength = 1024
+
+// fileType is the type of an artifact, detected from its content.
+type fileType int
+
+const (
+	fileTypeUnknown fileType = iota
+	fileTypeExecutable
+	fileTypeGzip
+	fileTypeTarGzip
+	fileTypeZip
+	fileTypeTar
+	fileTypeXz
+	fileTypeZstd
+	fileTypeBzip2
+)
+
+func (t fileType) String() string {
+	switch t {
+	case fileTypeExecutable:
+		return "executable"
+
+	case fileTypeGzip:
+		return "gzip"
+
+	case fileTypeTarGzip:
+		return "tar.gz"
+
+	case fileTypeZip:
+		return "zip"
+
+	case fileTypeTar:
+		return "tar"
+
+	case fileTypeXz:
+		return "xz"
+
+	case fileTypeZstd:
+		return "zstd"
+
+	case fileTypeBzip2:
+		return "bzip2"
+	}
+
+	return "unknown"
+}
+
+var (
+	magicELF         = []byte{0x7f, 'E', 'L', 'F'}
+	magicMachO32     = []byte{0xfe, 0xed, 0xfa, 0xce}
+	magicMachO64     = []byte{0xfe, 0xed, 0xfa, 0xcf}
+	magicMachO32LE   = []byte{0xce, 0xfa, 0xed, 0xfe}
+	magicMachO64LE   = []byte{0xcf, 0xfa, 0xed, 0xfe}
+	magicMachOFat    = []byte{0xca, 0xfe, 0xba, 0xbe}
+	magicPE          = []byte{'M', 'Z'}
+	magicPESignature = []byte{'P', 'E', 0x00, 0x00}
+	magicPEOffset    = 0x3c
+	magicShebang     = []byte{'#', '!'}
+	magicGzip        = []byte{0x1f, 0x8b}
+	magicZip         = []byte{'P', 'K', 0x03, 0x04}
+	magicZipEmpty    = []byte{'P', 'K', 0x05, 0x06}
+	magicXz          = []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}
+	magicZstd        = []byte{0x28, 0xb5, 0x2f, 0xfd}
+	magicBzip2       = []byte("BZh")
+	magicTar         = []byte("ustar")
+	magicTarOffset   = 257
+	executableMagics = [][]byte{magicELF, magicMachO32, magicMachO64, magicMachO32LE, magicMachO64LE, magicMachOFat, magicShebang}
+)
+
+// detectFileType detects the type of a file from its first bytes. The name is only used when the first bytes are not
+// enough to tell whether a gzip file is a tar.
+func detectFileType(name string, header []byte) fileType {
+	for _, magic := range executableMagics {
+		if bytes.HasPrefix(header, magic) {
+			return fileTypeExecutable
+		}
+	}
+
+	switch {
+	case isPE(header):
+		return fileTypeExecutable
+
+	case bytes.HasPrefix(header, magicGzip):
+		data, complete := gunzipHeader(header)
+
+		if isTar(data) || (!complete && hasTarGzipExt(name)) {
+			return fileTypeTarGzip
+		}
+
+		return fileTypeGzip
+
+	case bytes.HasPrefix(header, magicZip), bytes.HasPrefix(header, magicZipEmpty):
+		return fileTypeZip
+
+	case bytes.HasPrefix(header, magicXz):
+		return fileTypeXz
+
+	case bytes.HasPrefix(header, magicZstd):
+		return fileTypeZstd
+
+	case bytes.HasPrefix(header, magicBzip2):
+		return fileTypeBzip2
+
+	case isTar(header):
+		return fileTypeTar
+	}
+
+	return fileTypeUnknown
+}
+
+// isPE checks whether the header is the one of a PE file, like debug/pe does: the MZ stub has the offset of the PE
+// signature at 0x3c. A file that only starts with MZ, like a text file, is not a PE file.
+func isPE(header []byte) bool {
+	if !bytes.HasPrefix(header, magicPE) || len(header) < magicPEOffset+4 {
+		return false
+	}
+
+	offset := uint64(binary.LittleEndian.Uint32(header[magicPEOffset:]))
+
+	return offset+uint64(len(magicPESignature)) <= uint64(len(header)) &&
+		bytes.Equal(header[offset:offset+uint64(len(magicPESignature))], magicPESignature)
+}
+
+func isTar(header []byte) bool {
+	return len(header) >= magicTarOffset+len(magicTar) &&
+		bytes.Equal(header[magicTarOffset:magicTarOffset+len(magicTar)], magicTar)
+}
+
+// gunzipHeader decompresses as much as possible of the first bytes of a gzip file. It returns false if the data is
+// neither long enough to find the tar magic nor the whole content.
+func gunzipHeader(header []byte) ([]byte, bool) {
+	r, err := gzip.NewReader(bytes.NewReader(header))
+	if err != nil {
+		return nil, false
+	}
+
+	buf := make([]byte, sniffLength)
+	n := 0
+
+	for n < len(buf) {
+		m, err := r.Read(buf[n:])
+		n += m
+
+		if errors.Is(err, io.EOF) {
+			return buf[:n], true
+		}
+
+		if err != nil {
+			break
+		}
+	}
+
+	return buf[:n], n >= magicTarOffset+len(magicTar)
+}
+
+// hasTarGzipExt checks whether the file name says that it is a gzipped tar, for the tar files without the ustar magic.
+func hasTarGzipExt(name string) bool {
+	return strings.HasSuffix(name, ".tar.gz") || strings.HasSuffix(name, ".tgz")
+}
+
+// headerWriter keeps the first bytes that are written, to detect the type of the file while downloading it.
+type headerWriter struct {
+	w      io.Writer
+	header []byte
+}
+
+// Write satisfies io.Writer.
+func (w *headerWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+
+	if need := sniffLength - len(w.header); need > 0 {
+		if need > n {
+			need = n
+		}
+
+		w.header = append(w.header, p[:need]...)
+	}
+
+	return n, err
+}
+
+// wrap returns a copyFunc that writes through the headerWriter.
+func (w *headerWriter) wrap(copyFn copyFunc) copyFunc {
+	return func(dst io.Writer, src io.Reader) (int64, error) {
+		w.w = dst
+
+		return copyFn(w, src)
+	}
+}
+
+// stageArtifact prepares the downloaded artifact for the filesystem installers and returns the source to install. The
+// type of the artifact is detected from its content: an executable is installed from a directory, as the plugin binary,
+// and an archive is renamed with the extension that the filesystem installers expect. If the type is unknown, the
+// artifact is installed as is, and is made executable by its content type. A plain tar and the formats that are not
+// supported by the filesystem installers are decompressed.
+func (i *Installer) stageArtifact(
+	ctx context.Context,
+	dir, assetFile string,
+	asset *github.ReleaseAsset,
+	p *plugin.Plugin,
+	header []byte,
+) (string, error) {
+	t := detectFileType(asset.GetName(), header)
+	mode := executableMode(i.targetOf(ctx))
+
+	i.logger.Debug(ctx, "detected artifact type", "type", t.String())
+
+	if t == fileTypeUnknown {
+		if err := chmod(i.fs, asset.ContentType, assetFile, mode); err != nil {
+			return "", ctxd.WrapError(ctx, err, "could not chmod artifact")
+		}
+
+		return installSource(dir, assetFile), nil
+	}
+
+	if err := validateAssetName(p.Name); err != nil {
+		return "", ctxd.WrapError(ctx, err, "could not stage artifact")
+	}
+
+	switch t {
+	case fileTypeExecutable, fileTypeGzip:
+		// The gzip installer keeps the mode of the archive for the binary inside.
+		file, source := stagedArtifact(dir, t, p.Name)
+
+		return source, i.moveArtifact(ctx, assetFile, file, mode)
+
+	case fileTypeTarGzip, fileTypeZip:
+		file, source := stagedArtifact(dir, t, p.Name)
+
+		return source, i.moveArtifact(ctx, assetFile, file, 0)
+
+	default: // fileTypeTar, fileTypeXz, fileTypeZstd, fileTypeBzip2
+		return i.decompressArtifact(ctx, dir, assetFile, t, p)
+	}
+}
+
+// stagedArtifact returns the file that an artifact of the type is staged as in the directory, and the source for the
+// filesystem installers. An executable is the plugin binary in the directory, an archive has the extension that the
+// filesystem installers expect, and a plain tar is compressed again with gzip.
+func stagedArtifact(dir string, t fileType, name string) (file, source string) {
+	switch t {
+	case fileTypeExecutable:
+		return filepath.Join(dir, name), dir
+
+	case fileTypeGzip:
+		file = filepath.Join(dir, name+".gz")
+
+	case fileTypeZip:
+		file = filepath.Join(dir, name+".zip")
+
+	default: // fileTypeTarGzip, fileTypeTar
+		file = filepath.Join(dir, name+".tar.gz")
+	}
+
+	return file, file
+}
+
+// nameFileTypes are the types of the artifacts by the suffix of their name. The formats that are decompressed before
+// installing have the type of what they usually contain.
+var nameFileTypes = []struct {
+	suffix string
+	t      fileType
+}{
+	{".tar.gz", fileTypeTarGzip},
+	{".tgz", fileTypeTarGzip},
+	{".tar.xz", fileTypeTar},
+	{".txz", fileTypeTar},
+	{".tar.zst", fileTypeTar},
+	{".tzst", fileTypeTar},
+	{".tar.bz2", fileTypeTar},
+	{".tbz2", fileTypeTar},
+	{".tbz", fileTypeTar},
+	{".tar", fileTypeTar},
+	{".gz", fileTypeGzip},
+	{".zip", fileTypeZip},
+	{".xz", fileTypeExecutable},
+	{".zst", fileTypeExecutable},
+	{".bz2", fileTypeExecutable},
+	{".exe", fileTypeExecutable},
+}
+
+// fileExtensionPattern matches a file extension, that is not a part of a version like in my-plugin-1.4.2 or
+// my-plugin-1.4.2-linux-amd64.
+var fileExtensionPattern = regexp.MustCompile(`^\.[A-Za-z0-9]*[A-Za-z][A-Za-z0-9]*$`)
+
+// guessFileType guesses the type of an artifact from its name, when its content is not downloaded. A name without an
+// extension is guessed as an executable.
+func guessFileType(name string) fileType {
+	lower := strings.ToLower(name)
+
+	for _, n := range nameFileTypes {
+		if strings.HasSuffix(lower, n.suffix) {
+			return n.t
+		}
+	}
+
+	if !fileExtensionPattern.MatchString(filepath.Ext(lower)) {
+		return fileTypeExecutable
+	}
+
+	return fileTypeUnknown
+}
+
+// moveArtifact renames the artifact and changes its mode, if the mode is not zero.
+func (i *Installer) moveArtifact(ctx context.Context, from, to string, mode os.FileMode) error {
+	if from != to {
+		if err := i.fs.Rename(from, to); err != nil {
+			return ctxd.WrapError(ctx, err, "could not rename artifact")
+		}
+	}
+
+	if mode == 0 {
+		return nil
+	}
+
+	if err := i.fs.Chmod(to, mode); err != nil {
+		return ctxd.WrapError(ctx, err, "could not chmod artifact")
+	}
+
+	return nil
+}
@@ -0,0 +1,127 @@
+package github
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTarData(t *testing.T, name, content string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	tw := tar.NewWriter(&buf)
+
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0o755, Size: int64(len(content))}))
+
+	_, err := tw.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+
+	return buf.Bytes()
+}
+
+func newGzipData(t *testing.T, data []byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	zw := gzip.NewWriter(&buf)
+
+	_, err := zw.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	return buf.Bytes()
+}
+
+// newPEData returns the first bytes of a PE file, with the signature at the offset.
+func newPEData(offset uint32, signature string) []byte {
+	data := make([]byte, int(offset)+len(signature)+16)
+
+	copy(data, "MZ")
+	binary.LittleEndian.PutUint32(data[0x3c:], offset)
+	copy(data[offset:], signature)
+
+	return data
+}
+
+func readFixture(t *testing.T, path string) []byte {
+	t.Helper()
+
+	data, err := afero.ReadFile(afero.NewOsFs(), path)
+	require.NoError(t, err)
+
+	return data
+}
+
+func TestDetectFileType(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario string
+		name     string
+		data     []byte
+		expected fileType
+	}{
+		{scenario: "empty", expected: fileTypeUnknown},
+		{scenario: "text", data: []byte("hello world"), expected: fileTypeUnknown},
+		{scenario: "elf", data: []byte("\x7fELF\x02\x01\x01"), expected: fileTypeExecutable},
+		{scenario: "mach-o", data: []byte{0xcf, 0xfa, 0xed, 0xfe, 0x07, 0x00}, expected: fileTypeExecutable},
+		{scenario: "mach-o universal", data: []byte{0xca, 0xfe, 0xba, 0xbe, 0x00}, expected: fileTypeExecutable},
+		{scenario: "pe", data: newPEData(0x80, "PE\x00\x00"), expected: fileTypeExecutable},
+		{scenario: "pe with a large stub", data: newPEData(0x300, "PE\x00\x00"), expected: fileTypeExecutable},
+		{scenario: "pe signature after the header", data: newPEData(0x1000, "PE\x00\x00"), expected: fileTypeUnknown},
+		{scenario: "pe without signature", data: newPEData(0x80, "NE\x00\x00"), expected: fileTypeUnknown},
+		{scenario: "pe offset out of range", data: append(newPEData(0x80, "PE\x00\x00")[:0x3c], 0xff, 0xff, 0xff, 0xff), expected: fileTypeUnknown},
+		{scenario: "mz stub only", data: []byte("MZ\x90\x00"), expected: fileTypeUnknown},
+		{scenario: "text starting with mz", data: []byte("MZ is the magic of the dos executables, this is a text file."), expected: fileTypeUnknown},
+		{scenario: "shebang", data: readFixture(t, "resources/fixtures/binary/my-plugin"), expected: fileTypeExecutable},
+		{scenario: "gzip", data: readFixture(t, "resources/fixtures/gzip/my-plugin-no-parent.gz"), expected: fileTypeGzip},
+		{scenario: "tar gzip", data: readFixture(t, "resources/fixtures/gzip/my-plugin.tar.gz"), expected: fileTypeTarGzip},
+		{scenario: "zip", data: readFixture(t, "resources/fixtures/zip/my-plugin.zip"), expected: fileTypeZip},
+		{scenario: "gzip named as tar gzip", name: "my-plugin.tar.gz", data: readFixture(t, "resources/fixtures/gzip/my-plugin-no-parent.gz"), expected: fileTypeGzip},
+		{
+			scenario: "truncated gzip named as tar gzip",
+			name:     "my-plugin.tgz",
+			data:     newGzipData(t, bytes.Repeat([]byte("x"), 4096))[:20],
+			expected: fileTypeTarGzip,
+		},
+		{
+			scenario: "truncated gzip",
+			name:     "my-plugin.gz",
+			data:     newGzipData(t, bytes.Repeat([]byte("x"), 4096))[:20],
+			expected: fileTypeGzip,
+		},
+		{scenario: "xz", data: readFixture(t, "resources/fixtures/xz/my-plugin.tar.xz"), expected: fileTypeXz},
+		{scenario: "zstd", data: readFixture(t, "resources/fixtures/zstd/my-plugin.tar.zst"), expected: fileTypeZstd},
+		{scenario: "bzip2", data: readFixture(t, "resources/fixtures/bzip2/my-plugin.tar.bz2"), expected: fileTypeBzip2},
+		{scenario: "tar", data: newTarData(t, "my-plugin/my-plugin", "#!/bin/bash\n"), expected: fileTypeTar},
+		{
+			scenario: "large tar gzip",
+			data:     newGzipData(t, newTarData(t, "my-plugin/my-plugin", string(bytes.Repeat([]byte("x"), 4096)))),
+			expected: fileTypeTarGzip,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			header := tc.data
+			if len(header) > sniffLength {
+				header = header[:sniffLength]
+			}
+
+			assert.Equal(t, tc.expected, detectFileType(tc.name, header))
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package github
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/nhatthm/plugin-registry/plugin"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// MetadataFileJSON is the plugin metadata in json.
+	MetadataFileJSON = ".plugin.registry.json"
+	// MetadataFileTOML is the plugin metadata in toml.
+	MetadataFileTOML = ".plugin.registry.toml"
+)
+
+// ErrAmbiguousMetadata indicates that the plugin metadata is in more than one file.
+var ErrAmbiguousMetadata = errors.New("ambiguous plugin metadata")
+
+// metadataFormat is a format of the plugin metadata.
+type metadataFormat struct {
+	file string
+	// decode decodes the metadata into a value that is marshaled to yaml. It is nil for yaml.
+	decode func(data []byte) (interface{}, error)
+}
+
+// metadataFormats are the supported formats of the plugin metadata, in the order in which the installer looks for them.
+var metadataFormats = []metadataFormat{
+	{file: plugin.MetadataFile},
+	{file: MetadataFileJSON, decode: decodeJSON},
+	{file: MetadataFileTOML, decode: decodeTOML},
+}
+
+// assetNames returns the names of the metadata file in the release assets. GitHub strips the leading dot of the
+// uploaded files, so both forms are accepted.
+func (f metadataFormat) assetNames() []string {
+	return []string{f.file, strings.TrimPrefix(f.file, ".")}
+}
+
+// toYAML converts the metadata to yaml, so the metadata in all the formats is loaded and validated the same way.
+func (f metadataFormat) toYAML(data []byte) ([]byte, error) {
+	if f.decode == nil {
+		return data, nil
+	}
+
+	v, err := f.decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return yaml.Marshal(v)
+}
+
+func decodeJSON(data []byte) (interface{}, error) {
+	var v map[string]interface{}
+
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+func decodeTOML(data []byte) (interface{}, error) {
+	var v map[string]interface{}
+
+	if err := toml.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// ambiguousMetadataError reports the metadata files that are found.
+func ambiguousMetadataError(files []string) error {
+	return fmt.Errorf("%w: found %s", ErrAmbiguousMetadata, strings.Join(files, ", "))
+}
@@ -0,0 +1,187 @@
+package github
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// contextFs is an afero.Fs that fails the operations once the context is done. The filesystem installers do not take a
+// context, so this is how the install phase honours the cancellation and the deadline.
+type contextFs struct {
+	afero.Fs
+
+	ctx context.Context // nolint: containedctx
+}
+
+func newContextFs(ctx context.Context, fs afero.Fs) afero.Fs {
+	return &contextFs{Fs: fs, ctx: ctx}
+}
+
+// Create satisfies afero.Fs.
+func (fs *contextFs) Create(name string) (afero.File, error) {
+	if err := fs.ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	f, err := fs.Fs.Create(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &contextFile{File: f, ctx: fs.ctx}, nil
+}
+
+// Mkdir satisfies afero.Fs.
+func (fs *contextFs) Mkdir(name string, perm os.FileMode) error {
+	if err := fs.ctx.Err(); err != nil {
+		return err
+	}
+
+	return fs.Fs.Mkdir(name, perm)
+}
+
+// MkdirAll satisfies afero.Fs.
+func (fs *contextFs) MkdirAll(path string, perm os.FileMode) error {
+	if err := fs.ctx.Err(); err != nil {
+		return err
+	}
+
+	return fs.Fs.MkdirAll(path, perm)
+}
+
+// Open satisfies afero.Fs.
+func (fs *contextFs) Open(name string) (afero.File, error) {
+	if err := fs.ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	f, err := fs.Fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &contextFile{File: f, ctx: fs.ctx}, nil
+}
+
+// OpenFile satisfies afero.Fs.
+func (fs *contextFs) OpenFile(name string, flag int, perm os.FileMode) (afero.File, error) {
+	if err := fs.ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	f, err := fs.Fs.OpenFile(name, flag, perm)
+	if err != nil {
+		return nil, err
+	}
+
+	return &contextFile{File: f, ctx: fs.ctx}, nil
+}
+
+// Remove satisfies afero.Fs.
+func (fs *contextFs) Remove(name string) error {
+	if err := fs.ctx.Err(); err != nil {
+		return err
+	}
+
+	return fs.Fs.Remove(name)
+}
+
+// RemoveAll satisfies afero.Fs.
+func (fs *contextFs) RemoveAll(path string) error {
+	if err := fs.ctx.Err(); err != nil {
+		return err
+	}
+
+	return fs.Fs.RemoveAll(path)
+}
+
+// Rename satisfies afero.Fs.
+func (fs *contextFs) Rename(oldname, newname string) error {
+	if err := fs.ctx.Err(); err != nil {
+		return err
+	}
+
+	return fs.Fs.Rename(oldname, newname)
+}
+
+// Chmod satisfies afero.Fs.
+func (fs *contextFs) Chmod(name string, mode os.FileMode) error {
+	if err := fs.ctx.Err(); err != nil {
+		return err
+	}
+
+	return fs.Fs.Chmod(name, mode)
+}
+
+// Chown satisfies afero.Fs.
+func (fs *contextFs) Chown(name string, uid, gid int) error {
+	if err := fs.ctx.Err(); err != nil {
+		return err
+	}
+
+	return fs.Fs.Chown(name, uid, gid)
+}
+
+// Chtimes satisfies afero.Fs.
+func (fs *contextFs) Chtimes(name string, atime time.Time, mtime time.Time) error {
+	if err := fs.ctx.Err(); err != nil {
+		return err
+	}
+
+	return fs.Fs.Chtimes(name, atime, mtime)
+}
+
+// contextFile is an afero.File that fails reading and writing once the context is done.
+type contextFile struct {
+	afero.File
+
+	ctx context.Context // nolint: containedctx
+}
+
+// Read satisfies afero.File.
+func (f *contextFile) Read(p []byte) (int, error) {
+	if err := f.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	return f.File.Read(p)
+}
+
+// ReadAt satisfies afero.File.
+func (f *contextFile) ReadAt(p []byte, off int64) (int, error) {
+	if err := f.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	return f.File.ReadAt(p, off)
+}
+
+// Write satisfies afero.File.
+func (f *contextFile) Write(p []byte) (int, error) {
+	if err := f.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	return f.File.Write(p)
+}
+
+// WriteAt satisfies afero.File.
+func (f *contextFile) WriteAt(p []byte, off int64) (int, error) {
+	if err := f.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	return f.File.WriteAt(p, off)
+}
+
+// WriteString satisfies afero.File.
+func (f *contextFile) WriteString(s string) (int, error) {
+	if err := f.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	return f.File.WriteString(s)
+}
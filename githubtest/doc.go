@@ -0,0 +1,2 @@
+// Package githubtest provides a fake github server for testing the code that uses the installer.
+package githubtest
@@ -0,0 +1,343 @@
+package githubtest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"unicode/utf8"
+
+	registry "github.com/nhatthm/plugin-registry-github"
+)
+
+// Mode is the mode of a Recorder.
+type Mode int
+
+const (
+	// ModeReplay replays the recorded interactions, a request that is not recorded fails.
+	ModeReplay Mode = iota
+	// ModeRecord sends the requests and records the interactions.
+	ModeRecord
+)
+
+// redacted replaces the secrets in the recorded interactions.
+const redacted = "REDACTED"
+
+// ErrNotRecorded indicates that a request is not in the recorded interactions.
+var ErrNotRecorded = errors.New("request is not recorded")
+
+// sensitiveHeaders are not recorded.
+var sensitiveHeaders = []string{"Authorization", "Cookie", "Proxy-Authorization", "Set-Cookie"}
+
+var (
+	// tokenPattern matches the github tokens.
+	tokenPattern = regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,255}|github_pat_[A-Za-z0-9_]{22,255})\b`)
+	// signedParamPattern matches the query parameters that carry a token or a signature, such as in the download urls
+	// of the private raw files and of the assets.
+	signedParamPattern = regexp.MustCompile(`([?&](?:token|access_token|jwt|sig|X-Amz-Signature|X-Amz-Credential|X-Amz-Security-Token)=)[^&"'\s<>]+`)
+)
+
+// RecorderOption configures a Recorder.
+type RecorderOption func(r *Recorder)
+
+// Recorder is a http.RoundTripper that records the interactions with github into a golden file once, then replays them
+// without the network. The tokens, the signatures of the download urls and the secrets are scrubbed before the
+// interactions are written.
+type Recorder struct {
+	file      string
+	mode      Mode
+	transport http.RoundTripper
+	secrets   []string
+
+	mu           sync.Mutex
+	interactions []*Interaction
+	used         []bool
+}
+
+// Interaction is a recorded request and its response.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is a recorded request.
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+}
+
+// RecordedResponse is a recorded response. The body is in base64 if it is not a text.
+type RecordedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+	Base64     bool        `json:"base64,omitempty"`
+}
+
+type recording struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// WithMode sets the mode of the recorder, the default is ModeReplay.
+func WithMode(mode Mode) RecorderOption {
+	return func(r *Recorder) {
+		r.mode = mode
+	}
+}
+
+// WithTransport sets the transport that sends the requests while recording, for example a transport that authenticates
+// the requests. The default is http.DefaultTransport.
+func WithTransport(transport http.RoundTripper) RecorderOption {
+	return func(r *Recorder) {
+		r.transport = transport
+	}
+}
+
+// WithSecrets adds the secrets that are scrubbed from the recorded interactions, in addition to the github tokens.
+func WithSecrets(secrets ...string) RecorderOption {
+	return func(r *Recorder) {
+		r.secrets = append(r.secrets, secrets...)
+	}
+}
+
+// NewRecorder creates a new recorder of the golden file. In ModeReplay, the golden file is loaded and the test fails if
+// it could not be. In ModeRecord, the golden file is written when the test ends.
+func NewRecorder(tb testing.TB, file string, options ...RecorderOption) *Recorder {
+	tb.Helper()
+
+	r := &Recorder{
+		file:      file,
+		transport: http.DefaultTransport,
+	}
+
+	for _, o := range options {
+		o(r)
+	}
+
+	if r.mode == ModeRecord {
+		tb.Cleanup(func() {
+			if err := r.Save(); err != nil {
+				tb.Errorf("could not save recording: %s", err.Error())
+			}
+		})
+
+		return r
+	}
+
+	if err := r.load(); err != nil {
+		tb.Fatalf("could not load recording: %s", err.Error())
+	}
+
+	return r
+}
+
+// Client returns a http client that uses the recorder.
+func (r *Recorder) Client() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// Options returns the options of the installer to send the requests through the recorder.
+func (r *Recorder) Options() []registry.Option {
+	return []registry.Option{registry.WithHTTPClient(r.Client())}
+}
+
+// Interactions returns the recorded interactions.
+func (r *Recorder) Interactions() []*Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]*Interaction(nil), r.interactions...)
+}
+
+// RoundTrip satisfies http.RoundTripper.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	if r.mode == ModeRecord {
+		return r.record(req)
+	}
+
+	return r.replay(req)
+}
+
+func (r *Recorder) record(req *http.Request) (*http.Response, error) {
+	resp, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close() // nolint: errcheck
+
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	i := &Interaction{
+		Request: RecordedRequest{
+			Method: req.Method,
+			URL:    r.scrub(req.URL.String()),
+			Header: r.scrubHeader(req.Header),
+		},
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     r.scrubHeader(resp.Header),
+		},
+	}
+
+	i.Response.Header.Del("Content-Length")
+
+	if utf8.Valid(body) {
+		i.Response.Body = r.scrub(string(body))
+	} else {
+		i.Response.Body = base64.StdEncoding.EncodeToString(body)
+		i.Response.Base64 = true
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.interactions = append(r.interactions, i)
+
+	return resp, nil
+}
+
+// replay responds with the first recorded interaction of the request that is not replayed yet, or with the last one if
+// all of them are replayed.
+func (r *Recorder) replay(req *http.Request) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	u := r.scrub(req.URL.String())
+	accept := req.Header.Get("Accept")
+	found := -1
+
+	for idx, i := range r.interactions {
+		if i.Request.Method != req.Method || i.Request.URL != u || i.Request.Header.Get("Accept") != accept {
+			continue
+		}
+
+		found = idx
+
+		if !r.used[idx] {
+			break
+		}
+	}
+
+	if found < 0 {
+		return nil, fmt.Errorf("%w: %s %s", ErrNotRecorded, req.Method, u)
+	}
+
+	r.used[found] = true
+
+	return r.interactions[found].Response.response(req)
+}
+
+func (r *Recorder) load() error {
+	data, err := ioutil.ReadFile(r.file)
+	if err != nil {
+		return err
+	}
+
+	var rec recording
+
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return err
+	}
+
+	r.interactions = rec.Interactions
+	r.used = make([]bool, len(rec.Interactions))
+
+	return nil
+}
+
+// Save writes the recorded interactions to the golden file.
+func (r *Recorder) Save() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	data, err := json.MarshalIndent(recording{Interactions: r.interactions}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(r.file), 0o755); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(r.file, append(data, '\n'), 0o644) // nolint: gosec
+}
+
+// scrub replaces the tokens, the signatures and the secrets in the text.
+func (r *Recorder) scrub(s string) string {
+	for _, secret := range r.secrets {
+		if secret != "" {
+			s = strings.ReplaceAll(s, secret, redacted)
+		}
+	}
+
+	s = tokenPattern.ReplaceAllString(s, redacted)
+
+	return signedParamPattern.ReplaceAllString(s, "${1}"+redacted)
+}
+
+func (r *Recorder) scrubHeader(h http.Header) http.Header {
+	h = h.Clone()
+
+	for _, k := range sensitiveHeaders {
+		h.Del(k)
+	}
+
+	for k, values := range h {
+		for idx, v := range values {
+			values[idx] = r.scrub(v)
+		}
+
+		h[k] = values
+	}
+
+	if len(h) == 0 {
+		return nil
+	}
+
+	return h
+}
+
+func (r RecordedResponse) response(req *http.Request) (*http.Response, error) {
+	body := []byte(r.Body)
+
+	if r.Base64 {
+		var err error
+
+		if body, err = base64.StdEncoding.DecodeString(r.Body); err != nil {
+			return nil, err
+		}
+	}
+
+	header := r.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", r.StatusCode, http.StatusText(r.StatusCode)),
+		StatusCode:    r.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
@@ -0,0 +1,333 @@
+package githubtest
+
+import (
+	"crypto/sha1" // nolint: gosec
+	"encoding/hex"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nhatthm/plugin-registry/plugin"
+	"gopkg.in/yaml.v3"
+)
+
+// RepositoryOption configures a Repository.
+type RepositoryOption func(r *Repository)
+
+// ReleaseOption configures a Release.
+type ReleaseOption func(r *Release)
+
+// Repository is a repository of the fake server.
+type Repository struct {
+	server *Server
+
+	id          int64
+	owner       string
+	name        string
+	description string
+	topics      []string
+	stars       int
+	private     bool
+	formerNames []string
+
+	tags     map[string]*Tag
+	releases []*Release
+}
+
+// Tag is a tag of a repository, with the files of the repository at the tag.
+type Tag struct {
+	name  string
+	sha   string
+	files map[string][]byte
+}
+
+// Release is a release of a repository.
+type Release struct {
+	id          int64
+	tag         string
+	name        string
+	body        string
+	draft       bool
+	prerelease  bool
+	publishedAt time.Time
+	files       map[string][]byte
+	assets      []*Asset
+}
+
+// Asset is an asset of a release.
+type Asset struct {
+	id          int64
+	name        string
+	contentType string
+	data        []byte
+	createdAt   time.Time
+}
+
+// WithDescription sets the description of the repository.
+func WithDescription(description string) RepositoryOption {
+	return func(r *Repository) {
+		r.description = description
+	}
+}
+
+// WithTopics sets the topics of the repository.
+func WithTopics(topics ...string) RepositoryOption {
+	return func(r *Repository) {
+		r.topics = topics
+	}
+}
+
+// WithStars sets the number of stargazers of the repository.
+func WithStars(stars int) RepositoryOption {
+	return func(r *Repository) {
+		r.stars = stars
+	}
+}
+
+// WithPrivate makes the repository private, it is not found without a valid token.
+func WithPrivate() RepositoryOption {
+	return func(r *Repository) {
+		r.private = true
+	}
+}
+
+// WithFormerName adds a former name of the repository, the requests to the former name are redirected like a renamed
+// repository on github.
+func WithFormerName(name string) RepositoryOption {
+	return func(r *Repository) {
+		r.formerNames = append(r.formerNames, name)
+	}
+}
+
+// WithName sets the title of the release.
+func WithName(name string) ReleaseOption {
+	return func(r *Release) {
+		r.name = name
+	}
+}
+
+// WithBody sets the body of the release.
+func WithBody(body string) ReleaseOption {
+	return func(r *Release) {
+		r.body = body
+	}
+}
+
+// WithDraft makes the release a draft, it is only listed with a valid token.
+func WithDraft() ReleaseOption {
+	return func(r *Release) {
+		r.draft = true
+	}
+}
+
+// WithPrerelease makes the release a prerelease.
+func WithPrerelease() ReleaseOption {
+	return func(r *Release) {
+		r.prerelease = true
+	}
+}
+
+// WithPublishedAt sets the publish time of the release. By default, the releases are published in the order in which
+// they are added.
+func WithPublishedAt(t time.Time) ReleaseOption {
+	return func(r *Release) {
+		r.publishedAt = t
+	}
+}
+
+// WithAsset adds an asset to the release. The content type is application/octet-stream if it is empty.
+func WithAsset(name, contentType string, data []byte) ReleaseOption {
+	return func(r *Release) {
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+
+		r.assets = append(r.assets, &Asset{name: name, contentType: contentType, data: data})
+	}
+}
+
+// WithFile adds a file to the repository at the tag of the release.
+func WithFile(path string, data []byte) ReleaseOption {
+	return func(r *Release) {
+		r.files[path] = data
+	}
+}
+
+// WithMetadata adds the plugin metadata to the repository at the tag of the release.
+func WithMetadata(p plugin.Plugin) ReleaseOption {
+	data, err := yaml.Marshal(p)
+	if err != nil {
+		panic(err)
+	}
+
+	return WithFile(plugin.MetadataFile, data)
+}
+
+// Owner returns the owner of the repository.
+func (r *Repository) Owner() string {
+	return r.owner
+}
+
+// Name returns the name of the repository.
+func (r *Repository) Name() string {
+	return r.name
+}
+
+// AddTag adds a tag with the files of the repository at the tag. The files are added to the tag if it exists.
+func (r *Repository) AddTag(name string, files map[string][]byte) *Tag {
+	r.server.mu.Lock()
+	defer r.server.mu.Unlock()
+
+	return r.addTag(name, files)
+}
+
+func (r *Repository) addTag(name string, files map[string][]byte) *Tag {
+	t, ok := r.tags[name]
+	if !ok {
+		t = &Tag{
+			name:  name,
+			sha:   commitSHA(r.owner, r.name, name),
+			files: make(map[string][]byte),
+		}
+
+		r.tags[name] = t
+	}
+
+	for p, data := range files {
+		t.files[strings.TrimPrefix(path.Clean("/"+p), "/")] = data
+	}
+
+	return t
+}
+
+// AddRelease adds a release of the tag, the tag is created if it does not exist.
+func (r *Repository) AddRelease(tag string, options ...ReleaseOption) *Release {
+	r.server.mu.Lock()
+	defer r.server.mu.Unlock()
+
+	rel := &Release{
+		id:    r.server.newID(),
+		tag:   tag,
+		name:  tag,
+		files: make(map[string][]byte),
+	}
+
+	for _, o := range options {
+		o(rel)
+	}
+
+	if rel.publishedAt.IsZero() {
+		rel.publishedAt = r.server.clock()
+	}
+
+	for _, a := range rel.assets {
+		a.id = r.server.newID()
+		a.createdAt = rel.publishedAt
+	}
+
+	r.addTag(tag, rel.files)
+	r.releases = append(r.releases, rel)
+
+	return rel
+}
+
+// SHA returns the commit of the tag.
+func (t *Tag) SHA() string {
+	return t.sha
+}
+
+// ID returns the id of the release.
+func (r *Release) ID() int64 {
+	return r.id
+}
+
+// AssetID returns the id of the asset, or 0 if the release does not have it.
+func (r *Release) AssetID(name string) int64 {
+	for _, a := range r.assets {
+		if a.name == name {
+			return a.id
+		}
+	}
+
+	return 0
+}
+
+// visible checks whether the repository could be seen with or without a valid token.
+func (r *Repository) visible(authenticated bool) bool {
+	return !r.private || authenticated
+}
+
+// sortedReleases returns the releases, the newest first.
+func (r *Repository) sortedReleases(authenticated bool) []*Release {
+	releases := make([]*Release, 0, len(r.releases))
+
+	for _, rel := range r.releases {
+		if !rel.draft || authenticated {
+			releases = append(releases, rel)
+		}
+	}
+
+	sort.SliceStable(releases, func(i, j int) bool {
+		if !releases[i].publishedAt.Equal(releases[j].publishedAt) {
+			return releases[i].publishedAt.After(releases[j].publishedAt)
+		}
+
+		return releases[i].id > releases[j].id
+	})
+
+	return releases
+}
+
+// latestRelease returns the most recently published release that is neither a draft nor a prerelease.
+func (r *Repository) latestRelease() *Release {
+	for _, rel := range r.sortedReleases(false) {
+		if !rel.prerelease {
+			return rel
+		}
+	}
+
+	return nil
+}
+
+func (r *Repository) releaseByTag(tag string) *Release {
+	for _, rel := range r.releases {
+		if rel.tag == tag && !rel.draft {
+			return rel
+		}
+	}
+
+	return nil
+}
+
+// asset finds an asset by its id, the assets of the draft releases are only found with a valid token.
+func (r *Repository) asset(id int64, authenticated bool) *Asset {
+	for _, rel := range r.releases {
+		if rel.draft && !authenticated {
+			continue
+		}
+
+		for _, a := range rel.assets {
+			if a.id == id {
+				return a
+			}
+		}
+	}
+
+	return nil
+}
+
+// commitSHA generates a stable commit sha for a tag.
+func commitSHA(owner, repository, tag string) string {
+	sum := sha1.Sum([]byte(fmt.Sprintf("commit %s/%s@%s", owner, repository, tag))) // nolint: gosec
+
+	return hex.EncodeToString(sum[:])
+}
+
+// blobSHA returns the git sha of the content of a file.
+func blobSHA(data []byte) string {
+	sum := sha1.Sum(append([]byte(fmt.Sprintf("blob %d\x00", len(data))), data...)) // nolint: gosec
+
+	return hex.EncodeToString(sum[:])
+}
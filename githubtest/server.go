@@ -0,0 +1,714 @@
+package githubtest
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v35/github"
+
+	registry "github.com/nhatthm/plugin-registry-github"
+)
+
+const (
+	defaultRateLimit = 5000
+	defaultPageSize  = 30
+	maxPageSize      = 100
+
+	resourceCore   = "core"
+	resourceSearch = "search"
+
+	mediaTypeOctetStream = "application/octet-stream"
+	mediaTypeV3SHA       = "application/vnd.github.v3.sha"
+)
+
+// errBadCredentials indicates that the request has a token that the server does not know.
+var errBadCredentials = errors.New("bad credentials")
+
+// ServerOption configures a Server.
+type ServerOption func(s *Server)
+
+// Server is a fake github server. It serves the repositories, the releases, the assets and the contents that are
+// declared in the test with the endpoints of the github rest api that the installer uses:
+//
+//	GET /repos/{owner}/{repo}/releases
+//	GET /repos/{owner}/{repo}/releases/latest
+//	GET /repos/{owner}/{repo}/releases/tags/{tag}
+//	GET /repos/{owner}/{repo}/releases/assets/{id}
+//	GET /repos/{owner}/{repo}/contents/{path}?ref={tag}
+//	GET /repos/{owner}/{repo}/commits/{ref}
+//	GET /search/repositories
+//	GET /rate_limit
+//
+// The lists are paginated with the Link header, the api responses have the rate limit headers, and the assets and the
+// raw files are downloaded from the urls that the api redirects to. The state could be changed while the server runs.
+type Server struct {
+	server *httptest.Server
+
+	tokens    map[string]struct{}
+	rateLimit int
+
+	mu        sync.Mutex
+	repos     []*Repository
+	nextID    int64
+	start     time.Time
+	remaining map[string]int
+	reset     time.Time
+	requests  []string
+}
+
+// WithToken adds a valid token. A request with an unknown token fails with 401 Bad credentials, and the private
+// repositories and the draft releases are only visible with a valid token.
+func WithToken(token string) ServerOption {
+	return func(s *Server) {
+		s.tokens[token] = struct{}{}
+	}
+}
+
+// WithRateLimit sets the number of requests to the api and to the search api that are allowed before the server
+// responds with 403 rate limit exceeded. The default is 5000.
+func WithRateLimit(limit int) ServerOption {
+	return func(s *Server) {
+		s.rateLimit = limit
+	}
+}
+
+// NewServer starts a new fake github server that is closed when the test ends.
+func NewServer(tb testing.TB, options ...ServerOption) *Server {
+	tb.Helper()
+
+	s := &Server{
+		tokens:    make(map[string]struct{}),
+		rateLimit: defaultRateLimit,
+		start:     time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	for _, o := range options {
+		o(s)
+	}
+
+	s.remaining = map[string]int{resourceCore: s.rateLimit, resourceSearch: s.rateLimit}
+	s.reset = time.Now().Add(time.Hour).Truncate(time.Second)
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+
+	tb.Cleanup(s.Close)
+
+	return s
+}
+
+// URL returns the base url of the server.
+func (s *Server) URL() string {
+	return s.server.URL
+}
+
+// Close shuts down the server.
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// Options returns the options of the installer to use the server as the github api.
+func (s *Server) Options() []registry.Option {
+	u, err := url.Parse(s.server.URL + "/")
+	if err != nil {
+		panic(err)
+	}
+
+	return []registry.Option{registry.WithBaseURL(u)}
+}
+
+// Requests returns the requests that the server received, as "METHOD /path?query".
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string(nil), s.requests...)
+}
+
+// AddRepository adds a repository.
+func (s *Server) AddRepository(owner, name string, options ...RepositoryOption) *Repository {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r := &Repository{
+		server: s,
+		id:     s.newID(),
+		owner:  owner,
+		name:   name,
+		tags:   make(map[string]*Tag),
+	}
+
+	for _, o := range options {
+		o(r)
+	}
+
+	s.repos = append(s.repos, r)
+
+	return r
+}
+
+// newID returns a new id for a repository, a release or an asset.
+func (s *Server) newID() int64 {
+	s.nextID++
+
+	return s.nextID
+}
+
+// clock returns the publish time of a new release, the releases are one hour apart.
+func (s *Server) clock() time.Time {
+	return s.start.Add(time.Duration(s.nextID) * time.Hour)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = append(s.requests, r.Method+" "+r.URL.RequestURI())
+
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusNotFound, "Not Found")
+
+		return
+	}
+
+	parts := splitPath(r.URL.Path)
+
+	switch {
+	case len(parts) >= 3 && parts[0] == "repos":
+		s.serveAPI(w, r, resourceCore, func(authenticated bool) {
+			s.serveRepository(w, r, parts[1], parts[2], parts[3:], authenticated)
+		})
+
+	case len(parts) == 2 && parts[0] == "search" && parts[1] == "repositories":
+		s.serveAPI(w, r, resourceSearch, func(authenticated bool) {
+			s.serveSearch(w, r, authenticated)
+		})
+
+	case len(parts) == 1 && parts[0] == "rate_limit":
+		s.serveRateLimit(w)
+
+	case len(parts) == 4 && parts[0] == "downloads":
+		s.serveDownload(w, r, func(authenticated bool) {
+			s.serveAssetDownload(w, r, parts[1], parts[2], parts[3], authenticated)
+		})
+
+	case len(parts) >= 5 && parts[0] == "raw":
+		s.serveDownload(w, r, func(authenticated bool) {
+			s.serveRaw(w, r, parts[1], parts[2], parts[3], strings.Join(parts[4:], "/"), authenticated)
+		})
+
+	case len(parts) == 6 && parts[2] == "releases" && parts[3] == "download":
+		s.serveDownload(w, r, func(authenticated bool) {
+			s.serveBrowserDownload(w, r, parts[0], parts[1], parts[4], parts[5], authenticated)
+		})
+
+	default:
+		writeError(w, http.StatusNotFound, "Not Found")
+	}
+}
+
+// serveAPI checks the credentials and the rate limit of a request to the api.
+func (s *Server) serveAPI(w http.ResponseWriter, r *http.Request, resource string, serve func(authenticated bool)) {
+	authenticated, err := s.authenticate(r)
+	if err != nil {
+		writeError(w, http.StatusUnauthorized, "Bad credentials")
+
+		return
+	}
+
+	if s.remaining[resource] <= 0 {
+		s.writeRateLimit(w, resource)
+		writeError(w, http.StatusForbidden, fmt.Sprintf("API rate limit exceeded for %s.", r.RemoteAddr))
+
+		return
+	}
+
+	s.remaining[resource]--
+	s.writeRateLimit(w, resource)
+
+	serve(authenticated)
+}
+
+// serveDownload checks the credentials of a download, the downloads do not count in the rate limit.
+func (s *Server) serveDownload(w http.ResponseWriter, r *http.Request, serve func(authenticated bool)) {
+	authenticated, err := s.authenticate(r)
+	if err != nil {
+		writeError(w, http.StatusUnauthorized, "Bad credentials")
+
+		return
+	}
+
+	serve(authenticated)
+}
+
+func (s *Server) authenticate(r *http.Request) (bool, error) {
+	auth := r.Header.Get("Authorization")
+	if auth == "" {
+		return false, nil
+	}
+
+	fields := strings.Fields(auth)
+	if len(fields) != 2 || (!strings.EqualFold(fields[0], "token") && !strings.EqualFold(fields[0], "bearer")) {
+		return false, errBadCredentials
+	}
+
+	if _, ok := s.tokens[fields[1]]; !ok {
+		return false, errBadCredentials
+	}
+
+	return true, nil
+}
+
+func (s *Server) writeRateLimit(w http.ResponseWriter, resource string) {
+	h := w.Header()
+
+	h.Set("X-RateLimit-Limit", strconv.Itoa(s.rateLimit))
+	h.Set("X-RateLimit-Remaining", strconv.Itoa(s.remaining[resource]))
+	h.Set("X-RateLimit-Reset", strconv.FormatInt(s.reset.Unix(), 10))
+	h.Set("X-RateLimit-Used", strconv.Itoa(s.rateLimit-s.remaining[resource]))
+	h.Set("X-RateLimit-Resource", resource)
+}
+
+func (s *Server) serveRateLimit(w http.ResponseWriter) {
+	rate := func(resource string) *github.Rate {
+		return &github.Rate{
+			Limit:     s.rateLimit,
+			Remaining: s.remaining[resource],
+			Reset:     github.Timestamp{Time: s.reset},
+		}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"resources": &github.RateLimits{Core: rate(resourceCore), Search: rate(resourceSearch)},
+		"rate":      rate(resourceCore),
+	})
+}
+
+// findRepository finds a repository by its name or by one of its former names.
+func (s *Server) findRepository(owner, name string, authenticated bool) (*Repository, bool) {
+	for _, r := range s.repos {
+		if !strings.EqualFold(r.owner, owner) || !r.visible(authenticated) {
+			continue
+		}
+
+		if strings.EqualFold(r.name, name) {
+			return r, false
+		}
+
+		for _, former := range r.formerNames {
+			if strings.EqualFold(former, name) {
+				return r, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+func (s *Server) serveRepository(w http.ResponseWriter, r *http.Request, owner, name string, rest []string, authenticated bool) {
+	repo, renamed := s.findRepository(owner, name, authenticated)
+	if repo == nil {
+		writeError(w, http.StatusNotFound, "Not Found")
+
+		return
+	}
+
+	if renamed {
+		u := *r.URL
+		u.Path = "/" + strings.Join(append([]string{"repos", repo.owner, repo.name}, rest...), "/")
+
+		http.Redirect(w, r, u.String(), http.StatusMovedPermanently)
+
+		return
+	}
+
+	switch {
+	case len(rest) == 0:
+		writeJSON(w, http.StatusOK, s.repository(repo))
+
+	case len(rest) == 1 && rest[0] == "releases":
+		s.serveReleases(w, r, repo, authenticated)
+
+	case len(rest) == 2 && rest[0] == "releases" && rest[1] == "latest":
+		s.serveRelease(w, repo, repo.latestRelease())
+
+	case len(rest) == 3 && rest[0] == "releases" && rest[1] == "tags":
+		s.serveRelease(w, repo, repo.releaseByTag(rest[2]))
+
+	case len(rest) == 3 && rest[0] == "releases" && rest[1] == "assets":
+		s.serveAsset(w, r, repo, rest[2], authenticated)
+
+	case len(rest) >= 1 && rest[0] == "contents":
+		s.serveContents(w, r, repo, strings.Join(rest[1:], "/"))
+
+	case len(rest) == 2 && rest[0] == "commits":
+		s.serveCommit(w, r, repo, rest[1])
+
+	default:
+		writeError(w, http.StatusNotFound, "Not Found")
+	}
+}
+
+func (s *Server) serveReleases(w http.ResponseWriter, r *http.Request, repo *Repository, authenticated bool) {
+	releases := repo.sortedReleases(authenticated)
+	start, end := paginate(w, r, len(releases))
+
+	result := make([]*github.RepositoryRelease, 0, end-start)
+
+	for _, rel := range releases[start:end] {
+		result = append(result, s.release(repo, rel))
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) serveRelease(w http.ResponseWriter, repo *Repository, rel *Release) {
+	if rel == nil {
+		writeError(w, http.StatusNotFound, "Not Found")
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.release(repo, rel))
+}
+
+// serveAsset responds with the asset, or redirects to the download url if the asset is requested as a binary.
+func (s *Server) serveAsset(w http.ResponseWriter, r *http.Request, repo *Repository, id string, authenticated bool) {
+	assetID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "Not Found")
+
+		return
+	}
+
+	a := repo.asset(assetID, authenticated)
+	if a == nil {
+		writeError(w, http.StatusNotFound, "Not Found")
+
+		return
+	}
+
+	if strings.Contains(r.Header.Get("Accept"), mediaTypeOctetStream) {
+		http.Redirect(w, r, s.downloadURL(repo, a), http.StatusFound)
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.asset(repo, a))
+}
+
+// serveAssetDownload serves the content of an asset, it is where the api redirects to. The assets of the private
+// repositories and of the draft releases are only served with a valid token.
+func (s *Server) serveAssetDownload(w http.ResponseWriter, r *http.Request, owner, name, id string, authenticated bool) {
+	assetID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	repo, _ := s.findRepository(owner, name, authenticated)
+	if repo == nil {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	a := repo.asset(assetID, authenticated)
+	if a == nil {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", a.contentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(a.data)))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(a.data) // nolint: errcheck
+}
+
+// serveBrowserDownload redirects the browser download url of an asset to the download url.
+func (s *Server) serveBrowserDownload(w http.ResponseWriter, r *http.Request, owner, name, tag, file string, authenticated bool) {
+	repo, _ := s.findRepository(owner, name, authenticated)
+	if repo == nil {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	rel := repo.releaseByTag(tag)
+	if rel == nil {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	for _, a := range rel.assets {
+		if a.name == file {
+			http.Redirect(w, r, s.downloadURL(repo, a), http.StatusFound)
+
+			return
+		}
+	}
+
+	http.NotFound(w, r)
+}
+
+func (s *Server) serveContents(w http.ResponseWriter, r *http.Request, repo *Repository, path string) {
+	tag, ok := repo.tags[r.URL.Query().Get("ref")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "No commit found for the ref "+r.URL.Query().Get("ref"))
+
+		return
+	}
+
+	path = strings.Trim(path, "/")
+
+	if data, ok := tag.files[path]; ok {
+		c := s.content(repo, tag, path, "file")
+		c.Encoding = github.String("base64")
+		c.Content = github.String(base64Encode(data))
+		c.Size = github.Int(len(data))
+		c.SHA = github.String(blobSHA(data))
+
+		writeJSON(w, http.StatusOK, c)
+
+		return
+	}
+
+	entries := s.listDirectory(repo, tag, path)
+	if entries == nil {
+		writeError(w, http.StatusNotFound, "Not Found")
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, entries)
+}
+
+// listDirectory lists the files and the directories in the directory at the tag, or returns nil if the directory does
+// not exist.
+func (s *Server) listDirectory(repo *Repository, tag *Tag, dir string) []*github.RepositoryContent {
+	prefix := ""
+	if dir != "" {
+		prefix = dir + "/"
+	}
+
+	names := make(map[string]string)
+
+	for p := range tag.files {
+		if !strings.HasPrefix(p, prefix) {
+			continue
+		}
+
+		name := strings.TrimPrefix(p, prefix)
+
+		if idx := strings.Index(name, "/"); idx >= 0 {
+			names[name[:idx]] = "dir"
+		} else {
+			names[name] = "file"
+		}
+	}
+
+	if len(names) == 0 && dir != "" {
+		return nil
+	}
+
+	entries := make([]*github.RepositoryContent, 0, len(names))
+
+	for _, name := range sortedKeys(names) {
+		c := s.content(repo, tag, prefix+name, names[name])
+
+		if data, ok := tag.files[prefix+name]; ok {
+			c.Size = github.Int(len(data))
+			c.SHA = github.String(blobSHA(data))
+		}
+
+		entries = append(entries, c)
+	}
+
+	return entries
+}
+
+// serveRaw serves a file of a tag, the files of the private repositories are only served with a valid token.
+func (s *Server) serveRaw(w http.ResponseWriter, r *http.Request, owner, name, ref, path string, authenticated bool) {
+	repo, _ := s.findRepository(owner, name, authenticated)
+	if repo == nil {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	tag, ok := repo.tags[ref]
+	if !ok {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	data, ok := tag.files[path]
+	if !ok {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(data) // nolint: errcheck
+}
+
+func (s *Server) serveCommit(w http.ResponseWriter, r *http.Request, repo *Repository, ref string) {
+	var sha string
+
+	if tag, ok := repo.tags[ref]; ok {
+		sha = tag.sha
+	} else {
+		for _, tag := range repo.tags {
+			if tag.sha == ref {
+				sha = tag.sha
+			}
+		}
+	}
+
+	if sha == "" {
+		writeError(w, http.StatusUnprocessableEntity, "No commit found for SHA: "+ref)
+
+		return
+	}
+
+	if !strings.Contains(r.Header.Get("Accept"), mediaTypeV3SHA) {
+		writeJSON(w, http.StatusOK, &github.RepositoryCommit{SHA: github.String(sha)})
+
+		return
+	}
+
+	if r.Header.Get("If-None-Match") == `"`+sha+`"` {
+		w.WriteHeader(http.StatusNotModified)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", mediaTypeV3SHA)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(sha)) // nolint: errcheck
+}
+
+// serveSearch finds the repositories whose name or description has all the words of the query. The topic:, user: and
+// org: qualifiers are supported.
+func (s *Server) serveSearch(w http.ResponseWriter, r *http.Request, authenticated bool) {
+	var matches []*Repository
+
+	terms := strings.Fields(r.URL.Query().Get("q"))
+
+	for _, repo := range s.repos {
+		if repo.visible(authenticated) && matchSearch(repo, terms) {
+			matches = append(matches, repo)
+		}
+	}
+
+	sortRepositories(matches)
+
+	start, end := paginate(w, r, len(matches))
+	items := make([]*github.Repository, 0, end-start)
+
+	for _, repo := range matches[start:end] {
+		items = append(items, s.repository(repo))
+	}
+
+	writeJSON(w, http.StatusOK, &github.RepositoriesSearchResult{
+		Total:             github.Int(len(matches)),
+		IncompleteResults: github.Bool(false),
+		Repositories:      items,
+	})
+}
+
+func (s *Server) repository(r *Repository) *github.Repository {
+	return &github.Repository{
+		ID:              github.Int64(r.id),
+		Name:            github.String(r.name),
+		FullName:        github.String(r.owner + "/" + r.name),
+		Owner:           &github.User{Login: github.String(r.owner)},
+		Description:     github.String(r.description),
+		Topics:          r.topics,
+		StargazersCount: github.Int(r.stars),
+		Private:         github.Bool(r.private),
+		HTMLURL:         github.String(fmt.Sprintf("%s/%s/%s", s.server.URL, r.owner, r.name)),
+		URL:             github.String(fmt.Sprintf("%s/repos/%s/%s", s.server.URL, r.owner, r.name)),
+	}
+}
+
+func (s *Server) release(repo *Repository, r *Release) *github.RepositoryRelease {
+	assets := make([]*github.ReleaseAsset, 0, len(r.assets))
+
+	for _, a := range r.assets {
+		assets = append(assets, s.asset(repo, a))
+	}
+
+	return &github.RepositoryRelease{
+		ID:          github.Int64(r.id),
+		TagName:     github.String(r.tag),
+		Name:        github.String(r.name),
+		Body:        github.String(r.body),
+		Draft:       github.Bool(r.draft),
+		Prerelease:  github.Bool(r.prerelease),
+		CreatedAt:   &github.Timestamp{Time: r.publishedAt},
+		PublishedAt: &github.Timestamp{Time: r.publishedAt},
+		URL:         github.String(fmt.Sprintf("%s/repos/%s/%s/releases/%d", s.server.URL, repo.owner, repo.name, r.id)),
+		HTMLURL:     github.String(fmt.Sprintf("%s/%s/%s/releases/tag/%s", s.server.URL, repo.owner, repo.name, r.tag)),
+		Assets:      assets,
+	}
+}
+
+func (s *Server) asset(repo *Repository, a *Asset) *github.ReleaseAsset {
+	rel := s.assetRelease(repo, a)
+
+	return &github.ReleaseAsset{
+		ID:                 github.Int64(a.id),
+		Name:               github.String(a.name),
+		ContentType:        github.String(a.contentType),
+		Size:               github.Int(len(a.data)),
+		State:              github.String("uploaded"),
+		CreatedAt:          &github.Timestamp{Time: a.createdAt},
+		UpdatedAt:          &github.Timestamp{Time: a.createdAt},
+		URL:                github.String(fmt.Sprintf("%s/repos/%s/%s/releases/assets/%d", s.server.URL, repo.owner, repo.name, a.id)),
+		BrowserDownloadURL: github.String(fmt.Sprintf("%s/%s/%s/releases/download/%s/%s", s.server.URL, repo.owner, repo.name, rel.tag, a.name)),
+	}
+}
+
+func (s *Server) assetRelease(repo *Repository, a *Asset) *Release {
+	for _, rel := range repo.releases {
+		for _, ra := range rel.assets {
+			if ra == a {
+				return rel
+			}
+		}
+	}
+
+	return &Release{}
+}
+
+func (s *Server) content(repo *Repository, tag *Tag, path, typ string) *github.RepositoryContent {
+	c := &github.RepositoryContent{
+		Type: github.String(typ),
+		Name: github.String(path[strings.LastIndex(path, "/")+1:]),
+		Path: github.String(path),
+		URL:  github.String(fmt.Sprintf("%s/repos/%s/%s/contents/%s?ref=%s", s.server.URL, repo.owner, repo.name, path, tag.name)),
+	}
+
+	if typ == "file" {
+		c.DownloadURL = github.String(fmt.Sprintf("%s/raw/%s/%s/%s/%s", s.server.URL, repo.owner, repo.name, tag.name, path))
+	}
+
+	return c
+}
+
+func (s *Server) downloadURL(repo *Repository, a *Asset) string {
+	return fmt.Sprintf("%s/downloads/%s/%s/%d", s.server.URL, repo.owner, repo.name, a.id)
+}
@@ -0,0 +1,426 @@
+package githubtest_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-github/v35/github"
+	"github.com/nhatthm/aferoassert"
+	"github.com/nhatthm/plugin-registry/plugin"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	registry "github.com/nhatthm/plugin-registry-github"
+	"github.com/nhatthm/plugin-registry-github/githubtest"
+)
+
+type tokenTransport string
+
+func (t tokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "token "+string(t))
+
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func newClient(t *testing.T, s *githubtest.Server, token string) *github.Client {
+	t.Helper()
+
+	var httpClient *http.Client
+
+	if token != "" {
+		httpClient = &http.Client{Transport: tokenTransport(token)}
+	}
+
+	c := github.NewClient(httpClient)
+
+	u, err := url.Parse(s.URL() + "/")
+	require.NoError(t, err)
+
+	c.BaseURL = u
+
+	return c
+}
+
+func TestServer_Install(t *testing.T) {
+	t.Parallel()
+
+	archive, err := ioutil.ReadFile("../resources/fixtures/gzip/my-plugin.tar.gz")
+	require.NoError(t, err)
+
+	s := githubtest.NewServer(t)
+
+	s.AddRepository("owner", "my-plugin").
+		AddRelease("v1.4.2",
+			githubtest.WithMetadata(plugin.Plugin{
+				Name: "my-plugin",
+				Artifacts: plugin.Artifacts{
+					plugin.RuntimeArtifactIdentifier(): {File: "my-plugin.tar.gz"},
+				},
+			}),
+			githubtest.WithAsset("my-plugin.tar.gz", "application/gzip", archive),
+		)
+
+	dest := t.TempDir()
+	osFs := afero.NewOsFs()
+
+	i := registry.NewInstaller(append(s.Options(), registry.WithFs(osFs))...)
+
+	p, err := i.Install(context.Background(), dest, "github.com/owner/my-plugin")
+	require.NoError(t, err)
+
+	assert.Equal(t, "my-plugin", p.Name)
+	assert.Equal(t, "1.4.2", p.Version)
+
+	aferoassert.FileExists(t, osFs, filepath.Join(dest, "my-plugin", "my-plugin"))
+
+	expected := []string{
+		"GET /repos/owner/my-plugin/releases/latest",
+		"GET /repos/owner/my-plugin/contents/?ref=v1.4.2",
+		"GET /repos/owner/my-plugin/contents/.plugin.registry.yaml?ref=v1.4.2",
+		"GET /repos/owner/my-plugin/releases/assets/3",
+		"GET /downloads/owner/my-plugin/3",
+		"GET /repos/owner/my-plugin/commits/v1.4.2",
+	}
+
+	assert.Equal(t, expected, s.Requests())
+}
+
+func TestServer_ListReleases(t *testing.T) {
+	t.Parallel()
+
+	s := githubtest.NewServer(t)
+	r := s.AddRepository("owner", "my-plugin")
+
+	r.AddRelease("v1.0.0")
+	r.AddRelease("v1.1.0", githubtest.WithPrerelease())
+	r.AddRelease("v1.2.0")
+	r.AddRelease("v2.0.0", githubtest.WithDraft())
+
+	c := newClient(t, s, "")
+
+	releases, resp, err := c.Repositories.ListReleases(context.Background(), "owner", "my-plugin", &github.ListOptions{PerPage: 2})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"v1.2.0", "v1.1.0"}, tags(releases))
+	assert.Equal(t, 2, resp.NextPage)
+	assert.Equal(t, 2, resp.LastPage)
+
+	releases, resp, err = c.Repositories.ListReleases(context.Background(), "owner", "my-plugin", &github.ListOptions{PerPage: 2, Page: 2})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"v1.0.0"}, tags(releases))
+	assert.Equal(t, 0, resp.NextPage)
+	assert.Equal(t, 1, resp.PrevPage)
+
+	latest, _, err := c.Repositories.GetLatestRelease(context.Background(), "owner", "my-plugin")
+	require.NoError(t, err)
+
+	assert.Equal(t, "v1.2.0", latest.GetTagName())
+
+	_, _, err = c.Repositories.GetReleaseByTag(context.Background(), "owner", "my-plugin", "v2.0.0")
+	assertStatus(t, err, http.StatusNotFound)
+}
+
+func TestServer_DownloadReleaseAsset(t *testing.T) {
+	t.Parallel()
+
+	s := githubtest.NewServer(t)
+	rel := s.AddRepository("owner", "my-plugin").
+		AddRelease("v1.0.0", githubtest.WithAsset("my-plugin", "", []byte("#!/bin/bash\n")))
+
+	c := newClient(t, s, "")
+
+	rc, redirect, err := c.Repositories.DownloadReleaseAsset(context.Background(), "owner", "my-plugin", rel.AssetID("my-plugin"), nil)
+	require.NoError(t, err)
+
+	assert.Nil(t, rc)
+	assert.Equal(t, fmt.Sprintf("%s/downloads/owner/my-plugin/%d", s.URL(), rel.AssetID("my-plugin")), redirect)
+
+	rc, _, err = c.Repositories.DownloadReleaseAsset(context.Background(), "owner", "my-plugin", rel.AssetID("my-plugin"), http.DefaultClient)
+	require.NoError(t, err)
+
+	defer rc.Close() // nolint: errcheck
+
+	data, err := ioutil.ReadAll(rc)
+	require.NoError(t, err)
+
+	assert.Equal(t, "#!/bin/bash\n", string(data))
+
+	release, _, err := c.Repositories.GetReleaseByTag(context.Background(), "owner", "my-plugin", "v1.0.0")
+	require.NoError(t, err)
+
+	resp, err := http.Get(release.Assets[0].GetBrowserDownloadURL()) // nolint: noctx
+	require.NoError(t, err)
+
+	defer resp.Body.Close() // nolint: errcheck
+
+	data, err = ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, "#!/bin/bash\n", string(data))
+}
+
+func TestServer_Contents(t *testing.T) {
+	t.Parallel()
+
+	s := githubtest.NewServer(t)
+	r := s.AddRepository("owner", "my-plugin")
+
+	r.AddRelease("v1.0.0",
+		githubtest.WithFile("README.md", []byte("readme")),
+		githubtest.WithFile("docs/usage.md", []byte("usage")),
+	)
+
+	c := newClient(t, s, "")
+	opts := &github.RepositoryContentGetOptions{Ref: "v1.0.0"}
+
+	_, dir, _, err := c.Repositories.GetContents(context.Background(), "owner", "my-plugin", "", opts)
+	require.NoError(t, err)
+
+	require.Len(t, dir, 2)
+	assert.Equal(t, "README.md", dir[0].GetName())
+	assert.Equal(t, "file", dir[0].GetType())
+	assert.Equal(t, "docs", dir[1].GetName())
+	assert.Equal(t, "dir", dir[1].GetType())
+
+	file, _, _, err := c.Repositories.GetContents(context.Background(), "owner", "my-plugin", "docs/usage.md", opts)
+	require.NoError(t, err)
+
+	content, err := file.GetContent()
+	require.NoError(t, err)
+
+	assert.Equal(t, "usage", content)
+
+	rc, _, err := c.Repositories.DownloadContents(context.Background(), "owner", "my-plugin", "README.md", opts)
+	require.NoError(t, err)
+
+	defer rc.Close() // nolint: errcheck
+
+	data, err := ioutil.ReadAll(rc)
+	require.NoError(t, err)
+
+	assert.Equal(t, "readme", string(data))
+
+	_, _, _, err = c.Repositories.GetContents(context.Background(), "owner", "my-plugin", "", &github.RepositoryContentGetOptions{Ref: "v2.0.0"})
+	assertStatus(t, err, http.StatusNotFound)
+}
+
+func TestServer_GetCommitSHA1(t *testing.T) {
+	t.Parallel()
+
+	s := githubtest.NewServer(t)
+	tag := s.AddRepository("owner", "my-plugin").AddTag("v1.0.0", nil)
+
+	c := newClient(t, s, "")
+
+	sha, _, err := c.Repositories.GetCommitSHA1(context.Background(), "owner", "my-plugin", "v1.0.0", "")
+	require.NoError(t, err)
+
+	assert.Equal(t, tag.SHA(), sha)
+	assert.Len(t, sha, 40)
+
+	_, resp, err := c.Repositories.GetCommitSHA1(context.Background(), "owner", "my-plugin", "v1.0.0", sha)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+
+	_, _, err = c.Repositories.GetCommitSHA1(context.Background(), "owner", "my-plugin", "v2.0.0", "")
+	assertStatus(t, err, http.StatusUnprocessableEntity)
+}
+
+func TestServer_Search(t *testing.T) {
+	t.Parallel()
+
+	s := githubtest.NewServer(t)
+
+	s.AddRepository("owner", "n26", githubtest.WithTopics("plugin-registry-plugin"), githubtest.WithStars(7))
+	s.AddRepository("owner", "n26-cli", githubtest.WithTopics("plugin-registry-plugin"), githubtest.WithStars(42))
+	s.AddRepository("owner", "n26-other", githubtest.WithDescription("n26 without topic"))
+	s.AddRepository("other", "bank", githubtest.WithDescription("n26 plugin"), githubtest.WithTopics("plugin-registry-plugin"))
+
+	c := newClient(t, s, "")
+
+	result, _, err := c.Search.Repositories(context.Background(), "n26 topic:plugin-registry-plugin", nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, result.GetTotal())
+
+	names := make([]string, 0, len(result.Repositories))
+
+	for _, r := range result.Repositories {
+		names = append(names, r.GetFullName())
+	}
+
+	assert.Equal(t, []string{"owner/n26-cli", "owner/n26", "other/bank"}, names)
+
+	result, _, err = c.Search.Repositories(context.Background(), "n26 user:owner", nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, result.GetTotal())
+}
+
+func TestServer_Auth(t *testing.T) {
+	t.Parallel()
+
+	s := githubtest.NewServer(t, githubtest.WithToken("secret"))
+
+	s.AddRepository("owner", "private", githubtest.WithPrivate()).AddRelease("v1.0.0")
+
+	_, _, err := newClient(t, s, "").Repositories.GetLatestRelease(context.Background(), "owner", "private")
+	assertStatus(t, err, http.StatusNotFound)
+
+	_, _, err = newClient(t, s, "unknown").Repositories.GetLatestRelease(context.Background(), "owner", "private")
+	assertStatus(t, err, http.StatusUnauthorized)
+
+	release, _, err := newClient(t, s, "secret").Repositories.GetLatestRelease(context.Background(), "owner", "private")
+	require.NoError(t, err)
+
+	assert.Equal(t, "v1.0.0", release.GetTagName())
+}
+
+func TestServer_Auth_Downloads(t *testing.T) {
+	t.Parallel()
+
+	s := githubtest.NewServer(t, githubtest.WithToken("secret"))
+
+	private := s.AddRepository("owner", "private", githubtest.WithPrivate()).
+		AddRelease("v1.0.0",
+			githubtest.WithAsset("my-plugin", "", []byte("private")),
+			githubtest.WithFile("README.md", []byte("readme")),
+		)
+
+	draft := s.AddRepository("owner", "public").
+		AddRelease("v2.0.0", githubtest.WithDraft(), githubtest.WithAsset("my-plugin", "", []byte("draft")))
+
+	testCases := []struct {
+		scenario string
+		url      string
+	}{
+		{
+			scenario: "asset of private repository",
+			url:      fmt.Sprintf("%s/downloads/owner/private/%d", s.URL(), private.AssetID("my-plugin")),
+		},
+		{
+			scenario: "browser download of private repository",
+			url:      s.URL() + "/owner/private/releases/download/v1.0.0/my-plugin",
+		},
+		{
+			scenario: "raw file of private repository",
+			url:      s.URL() + "/raw/owner/private/v1.0.0/README.md",
+		},
+		{
+			scenario: "asset of draft release",
+			url:      fmt.Sprintf("%s/downloads/owner/public/%d", s.URL(), draft.AssetID("my-plugin")),
+		},
+		{
+			scenario: "api asset of draft release",
+			url:      fmt.Sprintf("%s/repos/owner/public/releases/assets/%d", s.URL(), draft.AssetID("my-plugin")),
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, http.StatusNotFound, getStatus(t, tc.url, ""))
+			assert.Equal(t, http.StatusUnauthorized, getStatus(t, tc.url, "unknown"))
+			assert.Equal(t, http.StatusOK, getStatus(t, tc.url, "secret"))
+		})
+	}
+}
+
+func TestServer_RateLimit(t *testing.T) {
+	t.Parallel()
+
+	s := githubtest.NewServer(t, githubtest.WithRateLimit(2))
+
+	s.AddRepository("owner", "my-plugin").AddRelease("v1.0.0")
+
+	c := newClient(t, s, "")
+
+	_, resp, err := c.Repositories.GetLatestRelease(context.Background(), "owner", "my-plugin")
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, resp.Rate.Limit)
+	assert.Equal(t, 1, resp.Rate.Remaining)
+
+	_, resp, err = c.Repositories.GetLatestRelease(context.Background(), "owner", "my-plugin")
+	require.NoError(t, err)
+
+	assert.Equal(t, 0, resp.Rate.Remaining)
+
+	// A new client does not know that the limit is reached, so the request is sent.
+	_, _, err = newClient(t, s, "").Repositories.GetLatestRelease(context.Background(), "owner", "my-plugin")
+
+	var rateErr *github.RateLimitError
+
+	require.True(t, errors.As(err, &rateErr))
+	assert.Equal(t, 0, rateErr.Rate.Remaining)
+
+	limits, _, err := newClient(t, s, "").RateLimits(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, 0, limits.GetCore().Remaining)
+	assert.Equal(t, 2, limits.GetSearch().Remaining)
+}
+
+func TestServer_Renamed(t *testing.T) {
+	t.Parallel()
+
+	s := githubtest.NewServer(t)
+
+	s.AddRepository("owner", "my-plugin", githubtest.WithFormerName("old-plugin")).AddRelease("v1.0.0")
+
+	release, _, err := newClient(t, s, "").Repositories.GetReleaseByTag(context.Background(), "owner", "old-plugin", "v1.0.0")
+	require.NoError(t, err)
+
+	assert.Equal(t, "v1.0.0", release.GetTagName())
+	assert.Equal(t, []string{
+		"GET /repos/owner/old-plugin/releases/tags/v1.0.0",
+		"GET /repos/owner/my-plugin/releases/tags/v1.0.0",
+	}, s.Requests())
+}
+
+func tags(releases []*github.RepositoryRelease) []string {
+	result := make([]string, 0, len(releases))
+
+	for _, r := range releases {
+		result = append(result, r.GetTagName())
+	}
+
+	return result
+}
+
+func assertStatus(t *testing.T, err error, status int) {
+	t.Helper()
+
+	var errResp *github.ErrorResponse
+
+	require.True(t, errors.As(err, &errResp), "unexpected error: %v", err)
+	assert.Equal(t, status, errResp.Response.StatusCode)
+}
+
+func getStatus(t *testing.T, url, token string) int {
+	t.Helper()
+
+	c := http.DefaultClient
+
+	if token != "" {
+		c = &http.Client{Transport: tokenTransport(token)}
+	}
+
+	resp, err := c.Get(url) // nolint: noctx
+	require.NoError(t, err)
+
+	defer resp.Body.Close() // nolint: errcheck
+
+	return resp.StatusCode
+}
@@ -0,0 +1,180 @@
+package githubtest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func splitPath(p string) []string {
+	p = strings.Trim(p, "/")
+	if p == "" {
+		return nil
+	}
+
+	parts := strings.Split(p, "/")
+
+	for i, part := range parts {
+		if unescaped, err := url.PathUnescape(part); err == nil {
+			parts[i] = unescaped
+		}
+	}
+
+	return parts
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_, _ = w.Write(data) // nolint: errcheck
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{
+		"message":           message,
+		"documentation_url": "https://docs.github.com/rest",
+	})
+}
+
+// paginate reads the page and the page size of the request, sets the Link header, and returns the range of the items
+// in the page.
+func paginate(w http.ResponseWriter, r *http.Request, total int) (int, int) {
+	q := r.URL.Query()
+
+	page, err := strconv.Atoi(q.Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	perPage, err := strconv.Atoi(q.Get("per_page"))
+	if err != nil || perPage < 1 {
+		perPage = defaultPageSize
+	}
+
+	if perPage > maxPageSize {
+		perPage = maxPageSize
+	}
+
+	last := (total + perPage - 1) / perPage
+	if last < 1 {
+		last = 1
+	}
+
+	link := func(page int, rel string) string {
+		u := *r.URL
+		v := u.Query()
+
+		v.Set("page", strconv.Itoa(page))
+		v.Set("per_page", strconv.Itoa(perPage))
+
+		u.RawQuery = v.Encode()
+
+		return fmt.Sprintf("<http://%s%s>; rel=%q", r.Host, u.RequestURI(), rel)
+	}
+
+	var links []string
+
+	if page < last {
+		links = append(links, link(page+1, "next"), link(last, "last"))
+	}
+
+	if page > 1 {
+		links = append(links, link(1, "first"), link(page-1, "prev"))
+	}
+
+	if len(links) > 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
+
+	start := (page - 1) * perPage
+	if start > total {
+		start = total
+	}
+
+	end := start + perPage
+	if end > total {
+		end = total
+	}
+
+	return start, end
+}
+
+func matchSearch(r *Repository, terms []string) bool {
+	for _, term := range terms {
+		qualifier, value := "", term
+
+		if idx := strings.Index(term, ":"); idx > 0 {
+			qualifier, value = term[:idx], term[idx+1:]
+		}
+
+		switch qualifier {
+		case "topic":
+			if !containsFold(r.topics, value) {
+				return false
+			}
+
+		case "user", "org":
+			if !strings.EqualFold(r.owner, value) {
+				return false
+			}
+
+		default:
+			text := strings.ToLower(r.name + " " + r.description)
+
+			if !strings.Contains(text, strings.ToLower(term)) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// sortRepositories sorts the repositories by stars, the most starred first, then by name.
+func sortRepositories(repos []*Repository) {
+	sort.SliceStable(repos, func(i, j int) bool {
+		if repos[i].stars != repos[j].stars {
+			return repos[i].stars > repos[j].stars
+		}
+
+		return repos[i].owner+"/"+repos[i].name < repos[j].owner+"/"+repos[j].name
+	})
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+func base64Encode(data []byte) string {
+	return base64.StdEncoding.EncodeToString(data)
+}
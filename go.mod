@@ -3,8 +3,11 @@ module github.com/nhatthm/plugin-registry-github
 go 1.17
 
 require (
+	github.com/BurntSushi/toml v1.2.1
+	github.com/Masterminds/semver/v3 v3.1.1
 	github.com/bool64/ctxd v1.1.3
 	github.com/google/go-github/v35 v35.3.0
+	github.com/klauspost/compress v1.15.9
 	github.com/nhatthm/aferoassert v0.1.6
 	github.com/nhatthm/aferomock v0.3.1
 	github.com/nhatthm/httpmock v0.8.0
@@ -12,6 +15,10 @@ require (
 	github.com/nhatthm/plugin-registry-fs v0.2.1
 	github.com/spf13/afero v1.9.2
 	github.com/stretchr/testify v1.8.0
+	github.com/ulikunitz/xz v0.5.10
+	go.opentelemetry.io/otel v1.10.0
+	go.opentelemetry.io/otel/sdk v1.10.0
+	go.opentelemetry.io/otel/trace v1.10.0
 	gopkg.in/yaml.v3 v3.0.1
 )
 
@@ -19,6 +26,8 @@ require (
 	github.com/bool64/shared v0.1.4 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/fatih/structtag v1.2.0 // indirect
+	github.com/go-logr/logr v1.2.3 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
 	github.com/google/go-querystring v1.1.0 // indirect
 	github.com/iancoleman/orderedmap v0.2.0 // indirect
 	github.com/nhatthm/aferocopy v1.1.0 // indirect
@@ -30,5 +39,6 @@ require (
 	github.com/yudai/golcs v0.0.0-20170316035057-ecda9a501e82 // indirect
 	go.nhat.io/matcher/v2 v2.0.0 // indirect
 	golang.org/x/crypto v0.0.0-20211117183948-ae814b36b871 // indirect
+	golang.org/x/sys v0.0.0-20220513210249-45d2b4557a2a // indirect
 	golang.org/x/text v0.3.7 // indirect
 )
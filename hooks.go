@@ -0,0 +1,91 @@
+package github
+
+import (
+	"context"
+
+	"github.com/bool64/ctxd"
+	"github.com/google/go-github/v35/github"
+	"github.com/nhatthm/plugin-registry/plugin"
+	"github.com/spf13/afero"
+)
+
+// Hooks are called at the steps of the installation. A hook aborts the installation by returning an error, except
+// while searching the releases for a compatible release or a dependency, where the denied release is skipped. The hooks
+// that are nil are skipped.
+type Hooks struct {
+	// AfterResolve is called after the release is resolved, for example to deny some versions. It is also called by
+	// Installer.Resolve().
+	AfterResolve func(ctx context.Context, owner, repository string, release *github.RepositoryRelease) error
+	// AfterMetadata is called after the plugin metadata is loaded, before the artifact is chosen. The metadata could be
+	// changed. It is also called by Installer.Resolve().
+	AfterMetadata func(ctx context.Context, release *github.RepositoryRelease, p *plugin.Plugin) error
+	// AfterDownload is called after the artifact is downloaded into a file on the file system of the installer, for
+	// example to scan it. The metadata of the plan could still be changed, it is written after this hook.
+	AfterDownload func(ctx context.Context, plan *Plan, fs afero.Fs, file string) error
+	// AfterInstall is called after the plugin is installed into the destination. The installed plugin is removed if the
+	// hook fails.
+	AfterInstall func(ctx context.Context, plan *Plan, dest string, p *plugin.Plugin) error
+}
+
+// WithHooks adds hooks to the installation. The hooks are called in the order they are added.
+func WithHooks(hooks Hooks) Option {
+	return func(i *Installer) {
+		i.hooks = append(i.hooks, hooks)
+	}
+}
+
+func (i *Installer) afterResolve(ctx context.Context, owner, repository string, release *github.RepositoryRelease) error {
+	for _, h := range i.hooks {
+		if h.AfterResolve == nil {
+			continue
+		}
+
+		if err := h.AfterResolve(ctx, owner, repository, release); err != nil {
+			return rejectRelease(ctxd.WrapError(ctx, err, "aborted after resolve", "tag", release.GetTagName()))
+		}
+	}
+
+	return nil
+}
+
+func (i *Installer) afterMetadata(ctx context.Context, release *github.RepositoryRelease, p *plugin.Plugin) error {
+	for _, h := range i.hooks {
+		if h.AfterMetadata == nil {
+			continue
+		}
+
+		if err := h.AfterMetadata(ctx, release, p); err != nil {
+			return rejectRelease(ctxd.WrapError(ctx, err, "aborted after metadata"))
+		}
+	}
+
+	return nil
+}
+
+func (i *Installer) afterDownload(ctx context.Context, plan *Plan, file string) error {
+	for _, h := range i.hooks {
+		if h.AfterDownload == nil {
+			continue
+		}
+
+		if err := h.AfterDownload(ctx, plan, i.fs, file); err != nil {
+			return ctxd.WrapError(ctx, err, "aborted after download")
+		}
+	}
+
+	return nil
+}
+
+func (i *Installer) afterInstall(ctx context.Context, plan *Plan, dest string, p *plugin.Plugin) error {
+	for _, h := range i.hooks {
+		if h.AfterInstall == nil {
+			continue
+		}
+
+		if err := h.AfterInstall(ctx, plan, dest, p); err != nil {
+			return ctxd.WrapError(ctx, err, "aborted after install")
+		}
+	}
+
+	return nil
+}
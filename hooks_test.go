@@ -0,0 +1,161 @@
+package github_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	goGitHub "github.com/google/go-github/v35/github"
+	"github.com/nhatthm/plugin-registry/plugin"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	github "github.com/nhatthm/plugin-registry-github"
+	"github.com/nhatthm/plugin-registry-github/mock/service"
+)
+
+func TestInstaller_Install_Hooks(t *testing.T) {
+	t.Parallel()
+
+	var calls []string
+
+	hooks := github.Hooks{
+		AfterResolve: func(_ context.Context, owner, repository string, release *goGitHub.RepositoryRelease) error {
+			calls = append(calls, "after resolve "+owner+"/"+repository+"@"+release.GetTagName())
+
+			return nil
+		},
+		AfterMetadata: func(_ context.Context, _ *goGitHub.RepositoryRelease, p *plugin.Plugin) error {
+			calls = append(calls, "after metadata "+p.Name)
+
+			p.Description = "My Plugin"
+
+			return nil
+		},
+		AfterDownload: func(_ context.Context, plan *github.Plan, fs afero.Fs, file string) error {
+			calls = append(calls, "after download "+filepath.Base(file))
+
+			stat, err := fs.Stat(file)
+			require.NoError(t, err)
+			assert.NotZero(t, stat.Size())
+
+			plan.Plugin.Tags = plugin.Tags{"scanned"}
+
+			return nil
+		},
+		AfterInstall: func(_ context.Context, _ *github.Plan, _ string, p *plugin.Plugin) error {
+			calls = append(calls, "after install "+p.Name)
+
+			return nil
+		},
+	}
+
+	i := github.NewInstaller(
+		github.WithFs(afero.NewOsFs()),
+		github.WithService(service.MockRepositoryService(mockPlugin(testPlugin{}))(t)),
+		github.WithMetadataSources(github.MetadataFromContents),
+		github.WithHooks(hooks),
+		github.WithHooks(github.Hooks{
+			AfterInstall: func(context.Context, *github.Plan, string, *plugin.Plugin) error {
+				calls = append(calls, "after install 2")
+
+				return nil
+			},
+		}),
+	)
+
+	p, err := i.Install(context.Background(), t.TempDir(), "github.com/owner/my-plugin@v1.4.2")
+	require.NoError(t, err)
+
+	expectedCalls := []string{
+		"after resolve owner/my-plugin@v1.4.2",
+		"after metadata my-plugin",
+		"after download my-plugin.tar.gz",
+		"after install my-plugin",
+		"after install 2",
+	}
+
+	assert.Equal(t, expectedCalls, calls)
+	assert.Equal(t, "My Plugin", p.Description)
+	assert.Equal(t, plugin.Tags{"scanned"}, p.Tags)
+}
+
+func TestInstaller_Install_HookAborts(t *testing.T) {
+	t.Parallel()
+
+	errDenied := errors.New("denied")
+
+	testCases := []struct {
+		scenario      string
+		hooks         github.Hooks
+		expectedError string
+	}{
+		{
+			scenario: "after resolve",
+			hooks: github.Hooks{
+				AfterResolve: func(context.Context, string, string, *goGitHub.RepositoryRelease) error {
+					return errDenied
+				},
+			},
+			expectedError: "aborted after resolve: denied",
+		},
+		{
+			scenario: "after metadata",
+			hooks: github.Hooks{
+				AfterMetadata: func(context.Context, *goGitHub.RepositoryRelease, *plugin.Plugin) error {
+					return errDenied
+				},
+			},
+			expectedError: "aborted after metadata: denied",
+		},
+		{
+			scenario: "after download",
+			hooks: github.Hooks{
+				AfterDownload: func(context.Context, *github.Plan, afero.Fs, string) error {
+					return errDenied
+				},
+			},
+			expectedError: "aborted after download: denied",
+		},
+		{
+			scenario: "after install",
+			hooks: github.Hooks{
+				AfterInstall: func(context.Context, *github.Plan, string, *plugin.Plugin) error {
+					return errDenied
+				},
+			},
+			expectedError: "aborted after install: denied",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			// The next steps are skipped when a hook aborts.
+			s := service.MockRepositoryService(mockPlugin(testPlugin{maybe: true}))(t)
+
+			dest := t.TempDir()
+
+			i := github.NewInstaller(
+				github.WithFs(afero.NewOsFs()),
+				github.WithService(s),
+				github.WithMetadataSources(github.MetadataFromContents),
+				github.WithHooks(tc.hooks),
+			)
+
+			_, err := i.Install(context.Background(), dest, "github.com/owner/my-plugin@v1.4.2")
+
+			assert.EqualError(t, err, tc.expectedError)
+			assert.True(t, errors.Is(err, errDenied))
+
+			_, err = os.Stat(filepath.Join(dest, "my-plugin"))
+
+			assert.True(t, os.IsNotExist(err))
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/bool64/ctxd"
+	"github.com/google/go-github/v35/github"
+)
+
+// ErrIncompatibleHost indicates that the plugin is not compatible with the version of the host application.
+var ErrIncompatibleHost = errors.New("incompatible host")
+
+// hostApplication is the application that the plugins are installed for.
+type hostApplication struct {
+	name    string
+	version string
+}
+
+// WithHost sets the name and the version of the host application. The metadata of a plugin could declare the versions
+// of the hosts that it is compatible with:
+//
+//	hosts:
+//	  mycli: ">= 2.0, < 3"
+//
+// The latest release and the dependencies are then resolved to the newest compatible release, and a pinned release
+// that is not compatible fails with ErrIncompatibleHost. A plugin that does not declare the host is compatible.
+func WithHost(name, version string) Option {
+	return func(i *Installer) {
+		i.hostApp = hostApplication{name: name, version: version}
+	}
+}
+
+// checkHost checks whether a release of the plugin is compatible with the host application.
+func (i *Installer) checkHost(ctx context.Context, repository, tag string, hosts map[string]string) error {
+	if i.hostApp.name == "" {
+		return nil
+	}
+
+	constraint, ok := hosts[i.hostApp.name]
+	if !ok {
+		return nil
+	}
+
+	err := i.checkHostVersion(repository, tag, constraint)
+	if err != nil {
+		return ctxd.WrapError(ctx, err, "could not check host compatibility")
+	}
+
+	return nil
+}
+
+func (i *Installer) checkHostVersion(repository, tag, constraint string) error {
+	c, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return rejectRelease(fmt.Errorf("%w: %s@%s has an invalid constraint %q of %s", ErrInvalidVersion, repository, tag, constraint, i.hostApp.name))
+	}
+
+	v, err := semver.NewVersion(i.hostApp.version)
+	if err != nil {
+		return fmt.Errorf("%w: %s %q", ErrInvalidVersion, i.hostApp.name, i.hostApp.version)
+	}
+
+	if !c.Check(v) {
+		return fmt.Errorf("%w: %s@%s requires %s %q, the host is %s",
+			ErrIncompatibleHost, repository, tag, i.hostApp.name, constraint, i.hostApp.version)
+	}
+
+	return nil
+}
+
+// findCompatibleRelease resolves the newest release, that is not a prerelease, and is compatible with the host.
+func (i *Installer) findCompatibleRelease(ctx context.Context, owner, repository string, latestErr error) (*Plan, error) {
+	releases, err := i.listReleases(ctx, owner, repository)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range releases {
+		if r.GetPrerelease() {
+			continue
+		}
+
+		plan, err := i.resolveListedRelease(ctx, owner, repository, r)
+		if err != nil {
+			if isRejectedRelease(err) {
+				i.logger.Debug(ctx, "skipped release", "tag", r.GetTagName(), "error", err)
+
+				continue
+			}
+
+			return nil, err
+		}
+
+		i.logger.Info(ctx, "resolved compatible release", "tag", plan.Tag, "host", i.hostApp.name, "hostVersion", i.hostApp.version)
+
+		return plan, nil
+	}
+
+	return nil, latestErr
+}
+
+// resolveListedRelease resolves a release that is found by listing the releases.
+func (i *Installer) resolveListedRelease(ctx context.Context, owner, repository string, r *github.RepositoryRelease) (*Plan, error) {
+	if err := i.afterResolve(ctx, owner, repository, r); err != nil {
+		return nil, err
+	}
+
+	return i.resolveRelease(ctx, owner, repository, r)
+}
+
+// rejectedReleaseError is an error that rejects a release, for example a denial of a hook, or an invalid metadata. A
+// search of the releases skips the release and goes on with the others.
+type rejectedReleaseError struct {
+	error
+}
+
+func (e rejectedReleaseError) Unwrap() error {
+	return e.error
+}
+
+func rejectRelease(err error) error {
+	return rejectedReleaseError{error: err}
+}
+
+// isRejectedRelease checks whether a release that is resolved while searching the releases should be skipped.
+func isRejectedRelease(err error) bool {
+	var rejected rejectedReleaseError
+
+	return errors.As(err, &rejected) ||
+		errors.Is(err, ErrIncompatibleHost) ||
+		errors.Is(err, ErrArtifactNotFound) ||
+		errors.Is(err, ErrMetadataNotFound) ||
+		errors.Is(err, ErrAmbiguousMetadata) ||
+		errors.Is(err, ErrMetadataTooLarge)
+}
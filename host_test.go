@@ -0,0 +1,241 @@
+package github_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	goGitHub "github.com/google/go-github/v35/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	github "github.com/nhatthm/plugin-registry-github"
+)
+
+func hosts(constraint string) string {
+	return "hosts:\n  mycli: \"" + constraint + "\"\n"
+}
+
+var errReleaseDenied = errors.New("release denied")
+
+// denyRelease denies a release of a repository after it is resolved.
+func denyRelease(repository, tag string) github.Option {
+	return github.WithHooks(github.Hooks{
+		AfterResolve: func(_ context.Context, _, r string, release *goGitHub.RepositoryRelease) error {
+			if r == repository && release.GetTagName() == tag {
+				return errReleaseDenied
+			}
+
+			return nil
+		},
+	})
+}
+
+// repositoryOnlyService hides the optional interfaces of a repository service.
+type repositoryOnlyService struct {
+	github.RepositoryService
+}
+
+func TestInstaller_ResolveDependencies_Host(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario      string
+		source        string
+		releases      []testDependencyRelease
+		options       []github.Option
+		unlisted      bool
+		expected      []string
+		expectedErr   error
+		expectedError string
+	}{
+		{
+			scenario: "latest is compatible",
+			source:   "github.com/owner/app",
+			releases: []testDependencyRelease{
+				{repository: "app", tag: "v1.0.0", metadata: hosts("^1")},
+				{repository: "app", tag: "v1.1.0", metadata: hosts(">= 1.5")},
+			},
+			options:  []github.Option{github.WithHost("mycli", "1.5.0")},
+			expected: []string{"app@v1.1.0"},
+		},
+		{
+			scenario: "latest is not compatible",
+			source:   "github.com/owner/app@latest",
+			releases: []testDependencyRelease{
+				{repository: "app", tag: "v1.0.0", metadata: hosts("^1")},
+				{repository: "app", tag: "v1.1.0"},
+				{repository: "app", tag: "v2.0.0", metadata: hosts("^2")},
+			},
+			options:  []github.Option{github.WithHost("mycli", "1.5.0")},
+			expected: []string{"app@v1.1.0"},
+		},
+		{
+			scenario: "latest is not compatible and a compatible release is denied",
+			source:   "github.com/owner/app",
+			releases: []testDependencyRelease{
+				{repository: "app", tag: "v1.0.0", metadata: hosts("^1")},
+				{repository: "app", tag: "v1.1.0", metadata: hosts("^1")},
+				{repository: "app", tag: "v2.0.0", metadata: hosts("^2")},
+			},
+			options:  []github.Option{github.WithHost("mycli", "1.5.0"), denyRelease("app", "v1.1.0")},
+			expected: []string{"app@v1.0.0"},
+		},
+		{
+			scenario: "latest is not compatible and a release has an invalid metadata",
+			source:   "github.com/owner/app",
+			releases: []testDependencyRelease{
+				{repository: "app", tag: "v1.0.0", metadata: hosts("^1")},
+				{repository: "app", tag: "v1.1.0", metadata: "hosts: ["},
+				{repository: "app", tag: "v1.2.0", metadata: hosts("not a constraint")},
+				{repository: "app", tag: "v2.0.0", metadata: hosts("^2")},
+			},
+			options:  []github.Option{github.WithHost("mycli", "1.5.0")},
+			expected: []string{"app@v1.0.0"},
+		},
+		{
+			scenario: "latest is not compatible and releases could not be listed",
+			source:   "github.com/owner/app",
+			releases: []testDependencyRelease{
+				{repository: "app", tag: "v1.0.0", metadata: hosts("^1")},
+				{repository: "app", tag: "v2.0.0", metadata: hosts("^2")},
+			},
+			options:       []github.Option{github.WithHost("mycli", "1.5.0")},
+			unlisted:      true,
+			expectedErr:   github.ErrListReleasesNotSupported,
+			expectedError: "could not list releases: repository service could not list releases",
+		},
+		{
+			scenario: "no compatible release",
+			source:   "github.com/owner/app",
+			releases: []testDependencyRelease{
+				{repository: "app", tag: "v1.0.0", metadata: hosts("^2")},
+				{repository: "app", tag: "v2.0.0", metadata: hosts("^2")},
+			},
+			options:       []github.Option{github.WithHost("mycli", "1.5.0")},
+			expectedErr:   github.ErrIncompatibleHost,
+			expectedError: `could not check host compatibility: incompatible host: app@v2.0.0 requires mycli "^2", the host is 1.5.0`,
+		},
+		{
+			scenario: "pinned release is not compatible",
+			source:   "github.com/owner/app@v1.0.0",
+			releases: []testDependencyRelease{
+				{repository: "app", tag: "v1.0.0", metadata: hosts("^2")},
+				{repository: "app", tag: "v2.0.0", metadata: hosts("^1")},
+			},
+			options:       []github.Option{github.WithHost("mycli", "1.5.0")},
+			expectedErr:   github.ErrIncompatibleHost,
+			expectedError: `could not check host compatibility: incompatible host: app@v1.0.0 requires mycli "^2", the host is 1.5.0`,
+		},
+		{
+			scenario: "other host",
+			source:   "github.com/owner/app",
+			releases: []testDependencyRelease{
+				{repository: "app", tag: "v1.0.0", metadata: "hosts:\n  othercli: \"^2\"\n"},
+			},
+			options:  []github.Option{github.WithHost("mycli", "1.5.0")},
+			expected: []string{"app@v1.0.0"},
+		},
+		{
+			scenario: "no host",
+			source:   "github.com/owner/app",
+			releases: []testDependencyRelease{
+				{repository: "app", tag: "v1.0.0", metadata: hosts("^1")},
+				{repository: "app", tag: "v2.0.0", metadata: hosts("^2")},
+			},
+			expected: []string{"app@v2.0.0"},
+		},
+		{
+			scenario: "invalid host version",
+			source:   "github.com/owner/app",
+			releases: []testDependencyRelease{
+				{repository: "app", tag: "v1.0.0", metadata: hosts("^1")},
+			},
+			options:       []github.Option{github.WithHost("mycli", "unknown")},
+			expectedErr:   github.ErrInvalidVersion,
+			expectedError: `could not check host compatibility: invalid version: mycli "unknown"`,
+		},
+		{
+			scenario: "compatible dependency",
+			source:   "github.com/owner/app",
+			releases: []testDependencyRelease{
+				{repository: "app", tag: "v1.0.0", metadata: dependsOn("lib", "")},
+				{repository: "lib", tag: "v1.0.0", metadata: hosts("^1")},
+				{repository: "lib", tag: "v2.0.0", metadata: hosts("^2")},
+			},
+			options:  []github.Option{github.WithHost("mycli", "1.5.0")},
+			expected: []string{"lib@v1.0.0", "app@v1.0.0"},
+		},
+		{
+			scenario: "dependency is denied",
+			source:   "github.com/owner/app",
+			releases: []testDependencyRelease{
+				{repository: "app", tag: "v1.0.0", metadata: dependsOn("lib", "")},
+				{repository: "lib", tag: "v1.0.0", metadata: hosts("^1")},
+				{repository: "lib", tag: "v1.1.0", metadata: hosts("^1")},
+			},
+			options:  []github.Option{github.WithHost("mycli", "1.5.0"), denyRelease("lib", "v1.1.0")},
+			expected: []string{"lib@v1.0.0", "app@v1.0.0"},
+		},
+		{
+			scenario: "pinned release is denied",
+			source:   "github.com/owner/app@v1.0.0",
+			releases: []testDependencyRelease{
+				{repository: "app", tag: "v1.0.0"},
+				{repository: "app", tag: "v1.1.0"},
+			},
+			options:       []github.Option{denyRelease("app", "v1.0.0")},
+			expectedErr:   errReleaseDenied,
+			expectedError: "aborted after resolve: release denied",
+		},
+		{
+			scenario: "no compatible dependency",
+			source:   "github.com/owner/app",
+			releases: []testDependencyRelease{
+				{repository: "app", tag: "v1.0.0", metadata: dependsOn("lib", "^2")},
+				{repository: "lib", tag: "v1.0.0", metadata: hosts("^1")},
+				{repository: "lib", tag: "v2.0.0", metadata: hosts("^2")},
+			},
+			options:       []github.Option{github.WithHost("mycli", "1.5.0")},
+			expectedErr:   github.ErrIncompatibleHost,
+			expectedError: `could not resolve dependencies: incompatible host: no version of owner/lib that satisfies the requirements is compatible with mycli 1.5.0: owner/app@v1.0.0 requires "^2"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			var s github.RepositoryService = newDependencyService(t, tc.releases...)
+
+			if tc.unlisted {
+				s = repositoryOnlyService{s}
+			}
+
+			i := github.NewInstaller(append(tc.options,
+				github.WithService(s),
+				github.WithMetadataSources(github.MetadataFromContents),
+			)...)
+
+			plans, err := i.ResolveDependencies(context.Background(), tc.source)
+
+			if tc.expectedErr != nil {
+				assert.EqualError(t, err, tc.expectedError)
+				assert.True(t, errors.Is(err, tc.expectedErr))
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			actual := make([]string, 0, len(plans))
+
+			for _, p := range plans {
+				actual = append(actual, p.Repository+"@"+p.Tag)
+			}
+
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
@@ -2,12 +2,16 @@ package github
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
+	"os"
 	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/bool64/ctxd"
 	"github.com/google/go-github/v35/github"
@@ -16,6 +20,9 @@ import (
 	"github.com/nhatthm/plugin-registry/installer"
 	"github.com/nhatthm/plugin-registry/plugin"
 	"github.com/spf13/afero"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const githubHostname = "github.com"
@@ -35,15 +42,64 @@ func init() { // nolint: gochecknoinits
 
 type contextKey string
 
+// withSource puts the source into the context, for the errors and the logs.
+func withSource(ctx context.Context, source, owner, repository string) context.Context {
+	ctx = context.WithValue(ctx, contextKey("source"), source)
+	ctx = context.WithValue(ctx, contextKey("owner"), owner)
+	ctx = context.WithValue(ctx, contextKey("repository"), repository)
+
+	return ctxd.AddFields(ctx, "source", source, "owner", owner, "repository", repository)
+}
+
 // Option is option to configure Installer.
 type Option func(i *Installer)
 
 // Installer installs plugin from github.
 type Installer struct {
-	fs      afero.Fs
-	service RepositoryService
+	fs       afero.Fs
+	service  RepositoryService
+	search   SearchService
+	commits  CommitService
+	contents ContentsService
+	releases ReleaseService
+
+	baseURL         *url.URL
+	metadataSources []MetadataSource
+	archFallbacks   ArchFallbacks
+
+	httpClient     *http.Client
+	downloadClient *http.Client
+	urlRewriters   []URLRewriter
+	hostHeaders    map[string]http.Header
+
+	searchTopic      string
+	searchMaxResults int
+	searchValidation bool
+
+	versionsAssetCheck bool
 
-	baseURL *url.URL
+	logger  ctxd.Logger
+	metrics Metrics
+
+	hooks []Hooks
+
+	smokeTestEnabled bool
+	smokeTestTimeout time.Duration
+
+	target  plugin.ArtifactIdentifier
+	hostApp hostApplication
+
+	attestationPolicy *AttestationPolicy
+
+	tracerProvider trace.TracerProvider
+	tracer         trace.Tracer
+	propagator     propagation.TextMapPropagator
+
+	timeouts      Timeouts
+	minThroughput throughput
+	maxAssetSize  int64
+
+	maxDecompressedSize int64
 
 	mu sync.Mutex
 }
@@ -55,90 +111,183 @@ func (i *Installer) Install(ctx context.Context, dest, source string) (*plugin.P
 	i.mu.Lock()
 	defer i.mu.Unlock()
 
-	owner, repository, version, err := parseURL(source)
+	ctx, span := i.startSpan(ctx, "install", attrSource.String(source))
+
+	p, err := i.install(ctx, dest, source)
+
+	endSpan(span, err)
+	i.observeInstall(err)
+
+	return p, err
+}
+
+func (i *Installer) install(ctx context.Context, dest, source string) (*plugin.Plugin, error) {
+	ctx, plan, err := i.resolve(ctx, source)
 	if err != nil {
-		return nil, parseError(err, source)
+		return nil, err
 	}
 
-	ctx = context.WithValue(ctx, contextKey("source"), source)
-	ctx = context.WithValue(ctx, contextKey("owner"), owner)
-	ctx = context.WithValue(ctx, contextKey("repository"), repository)
+	if err := i.installDependencies(ctx, dest, plan); err != nil {
+		return nil, err
+	}
 
-	return i.install(ctx, dest, owner, repository, version)
+	return i.installPlan(ctx, dest, plan)
 }
 
-func (i *Installer) install(ctx context.Context, dest, owner, repository, version string) (*plugin.Plugin, error) {
-	if version == "" || version == "latest" {
-		r, _, err := i.service.GetLatestRelease(ctx, owner, repository)
-		if err != nil {
-			return nil, ctxd.WrapError(ctx, err, "could not find latest release")
-		}
+func (i *Installer) resolve(ctx context.Context, source string) (context.Context, *Plan, error) {
+	owner, repository, version, err := i.parseSource(ctx, source)
+	if err != nil {
+		return ctx, nil, err
+	}
 
-		if r.TagName == nil {
-			return nil, ctxd.NewError(ctx, "latest release has no tag name")
-		}
+	ctx = withSource(ctx, source, owner, repository)
+
+	i.logger.Debug(ctx, "resolving plugin", "version", version)
+
+	release, err := i.getRelease(ctx, owner, repository, version)
+	if err != nil {
+		return ctx, nil, err
+	}
 
-		return i.installRelease(ctx, dest, owner, repository, r)
+	if err := i.afterResolve(ctx, owner, repository, release); err != nil {
+		return ctx, nil, err
+	}
+
+	plan, err := i.resolveRelease(ctx, owner, repository, release)
+	if isLatest(version) && errors.Is(err, ErrIncompatibleHost) {
+		plan, err = i.findCompatibleRelease(ctx, owner, repository, err)
 	}
 
-	r, _, err := i.service.GetReleaseByTag(ctx, owner, repository, version)
 	if err != nil {
-		return nil, ctxd.WrapError(ctx, err, "could not get release")
+		return ctx, nil, err
 	}
 
-	return i.installRelease(ctx, dest, owner, repository, r)
+	plan.Source = source
+
+	return ctx, plan, nil
 }
 
-func (i *Installer) installRelease(ctx context.Context, dest string, owner, repository string, release *github.RepositoryRelease) (*plugin.Plugin, error) {
-	r, _, err := i.service.DownloadContents(ctx, owner, repository, plugin.MetadataFile, &github.RepositoryContentGetOptions{
-		Ref: *release.TagName,
-	})
+func (i *Installer) parseSource(ctx context.Context, source string) (owner, repository, version string, err error) {
+	_, span := i.startSpan(ctx, "parse", attrSource.String(source))
+
+	owner, repository, version, err = parseURL(source)
 	if err != nil {
-		return nil, ctxd.WrapError(ctx, err, "could not get plugin metadata", "version", *release.TagName)
+		err = parseError(err, source)
 	}
 
-	p, err := loadMetadata(r)
+	endSpan(span, err)
+
+	return owner, repository, version, err
+}
+
+func (i *Installer) getRelease(ctx context.Context, owner, repository, version string) (*github.RepositoryRelease, error) {
+	ctx, span := i.startSpan(ctx, "resolve release", attrOwner.String(owner), attrRepository.String(repository))
+	start := time.Now()
+
+	r, err := i.findRelease(ctx, owner, repository, version)
 	if err != nil {
-		return nil, ctxd.WrapError(ctx, err, "could not load plugin metadata")
+		endSpan(span, err)
+
+		return nil, err
 	}
 
-	p.Version = trimVersion(*release.TagName)
-	p.URL = fmt.Sprintf("https://github.com/%s/%s", owner, repository)
+	span.SetAttributes(attrTag.String(r.GetTagName()))
+	endSpan(span, nil)
+
+	i.logger.Debug(ctx, "found release", "tag", r.GetTagName(), "duration", time.Since(start))
+
+	return r, nil
+}
 
-	return i.installPluginRelease(ctx, dest, owner, repository, p, release)
+// isLatest checks whether the version of the source is the latest release.
+func isLatest(version string) bool {
+	return version == "" || version == "latest"
 }
 
-func (i *Installer) installPluginRelease(
-	ctx context.Context,
-	dest string,
-	owner, repository string,
-	p *plugin.Plugin,
-	release *github.RepositoryRelease,
-) (*plugin.Plugin, error) {
-	artifact := p.ResolveArtifact(p.RuntimeArtifact())
+func (i *Installer) findRelease(ctx context.Context, owner, repository, version string) (*github.RepositoryRelease, error) {
+	apiCtx, cancel := i.phaseContext(ctx, PhaseAPI)
+	defer cancel()
+
+	span := trace.SpanFromContext(ctx)
+
+	if isLatest(version) {
+		r, resp, err := i.service.GetLatestRelease(apiCtx, owner, repository)
+
+		setResponseStatus(span, resp)
+		i.observeAPICall(EndpointGetLatestRelease, resp)
+
+		if err != nil {
+			return nil, ctxd.WrapError(ctx, i.phaseError(ctx, apiCtx, PhaseAPI, err), "could not find latest release")
+		}
+
+		if r.TagName == nil {
+			return nil, ctxd.NewError(ctx, "latest release has no tag name")
+		}
+
+		return r, nil
+	}
+
+	r, resp, err := i.service.GetReleaseByTag(apiCtx, owner, repository, version)
+
+	setResponseStatus(span, resp)
+	i.observeAPICall(EndpointGetReleaseByTag, resp)
 
-	asset, err := findAsset(release, artifact.File)
 	if err != nil {
-		return nil, ctxd.WrapError(ctx, err, "could not find artifact")
+		return nil, ctxd.WrapError(ctx, i.phaseError(ctx, apiCtx, PhaseAPI, err), "could not get release")
 	}
 
-	return i.installPluginReleaseAsset(ctx, dest, owner, repository, p, asset)
+	return r, nil
 }
 
-func (i *Installer) installPluginReleaseAsset(
-	ctx context.Context,
-	dest string,
-	owner, repository string,
-	p *plugin.Plugin,
-	asset *github.ReleaseAsset,
-) (*plugin.Plugin, error) {
-	ctx = context.WithValue(ctx, contextKey("asset"), asset)
+func (i *Installer) resolveRelease(ctx context.Context, owner, repository string, release *github.RepositoryRelease) (*Plan, error) {
+	p, ext, err := i.loadReleaseMetadata(ctx, owner, repository, release)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := i.afterMetadata(ctx, release, p); err != nil {
+		return nil, err
+	}
 
-	r, _, err := i.service.DownloadReleaseAsset(ctx, owner, repository, *asset.ID, http.DefaultClient)
+	if err := i.checkHost(ctx, repository, release.GetTagName(), ext.Hosts); err != nil {
+		return nil, err
+	}
+
+	artifact, asset, err := i.findTargetAsset(ctx, p, release)
 	if err != nil {
-		return nil, ctxd.WrapError(ctx, err, "could not download artifact")
+		return nil, ctxd.WrapError(ctx, err, "could not find artifact")
 	}
 
+	i.logger.Info(ctx, "resolved artifact",
+		"tag", release.GetTagName(),
+		"artifact", artifact.File,
+		"assetID", asset.GetID(),
+		"assetSize", asset.GetSize(),
+	)
+
+	plan := newPlan(owner, repository, p, artifact, release, asset)
+	plan.Target = i.targetOf(ctx)
+	plan.SmokeTest = ext.SmokeTest
+	plan.Dependencies = ext.Dependencies
+	plan.Hosts = ext.Hosts
+
+	return plan, nil
+}
+
+// download is an artifact that is downloaded into a temp dir.
+type download struct {
+	dir    string
+	file   string
+	header []byte
+	sha256 string
+}
+
+func (i *Installer) installPlan(ctx context.Context, dest string, plan *Plan) (*plugin.Plugin, error) {
+	asset := plan.asset
+	ctx = context.WithValue(ctx, contextKey("asset"), asset)
+	ctx = ctxd.AddFields(ctx, "tag", plan.Tag, "assetID", asset.GetID())
+	start := time.Now()
+
 	tmpDir, err := afero.TempDir(i.fs, "", "plugin-registry-github-")
 	if err != nil {
 		return nil, ctxd.WrapError(ctx, err, "could not create temp dir")
@@ -148,33 +297,243 @@ func (i *Installer) installPluginReleaseAsset(
 		_ = i.fs.RemoveAll(tmpDir) // nolint: errcheck
 	}()
 
-	assetFile := filepath.Join(tmpDir, *asset.Name)
+	if err := i.verifyAsset(ctx, asset, tmpDir, dest); err != nil {
+		return nil, err
+	}
 
-	if err := writeFile(i.fs, assetFile, r); err != nil {
-		return nil, ctxd.WrapError(ctx, err, "could not write artifact")
+	d, err := i.downloadArtifact(ctx, plan, tmpDir)
+	if err != nil {
+		return nil, err
 	}
 
-	if err := chmod(i.fs, asset.ContentType, assetFile, 0o755); err != nil {
-		return nil, ctxd.WrapError(ctx, err, "could not chmod artifact")
+	i.logger.Debug(ctx, "downloaded artifact", "assetSize", asset.GetSize(), "duration", time.Since(start))
+
+	if err := i.verifyAttestation(ctx, plan, d); err != nil {
+		return nil, err
 	}
 
-	if err := writeMetadata(i.fs, tmpDir, p); err != nil {
+	if err := i.afterDownload(ctx, plan, d.file); err != nil {
+		return nil, err
+	}
+
+	source, err := i.stageArtifact(ctx, d.dir, d.file, asset, plan.Plugin, d.header)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := writeMetadata(i.fs, d.dir, plan.Plugin); err != nil {
 		return nil, ctxd.WrapError(ctx, err, "could not write plugin metadata")
 	}
 
-	source := assetFile
-	if filepath.Ext(assetFile) == "" {
-		source = tmpDir
+	backup, err := i.backupPlugin(ctx, dest, plan.Plugin.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := i.installArtifact(ctx, dest, source)
+	if err != nil {
+		i.rollback(ctx, dest, plan.Plugin, backup)
+
+		return nil, err
+	}
+
+	if err := i.smokeTest(ctx, plan, dest, p); err != nil {
+		i.rollback(ctx, dest, p, backup)
+
+		return nil, err
+	}
+
+	if err := i.writeProvenance(ctx, plan, d, dest, p); err != nil {
+		i.rollback(ctx, dest, p, backup)
+
+		return nil, err
+	}
+
+	if err := i.afterInstall(ctx, plan, dest, p); err != nil {
+		i.rollback(ctx, dest, p, backup)
+
+		return nil, err
+	}
+
+	i.removeBackup(ctx, backup)
+
+	i.logger.Info(ctx, "installed plugin", "name", p.Name, "version", p.Version, "dest", dest, "duration", time.Since(start))
+
+	return p, nil
+}
+
+// backupPlugin moves the installed version of the plugin aside, so it is restored if the installation fails. The backup
+// is a hidden dir in the destination, so it is on the same filesystem. The path is empty if the plugin is not installed.
+func (i *Installer) backupPlugin(ctx context.Context, dest, name string) (string, error) {
+	if err := validateAssetName(name); err != nil {
+		return "", ctxd.WrapError(ctx, err, "could not back up plugin")
+	}
+
+	path := filepath.Join(dest, name)
+
+	if _, err := i.fs.Stat(path); err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+
+		return "", ctxd.WrapError(ctx, err, "could not back up plugin")
+	}
+
+	backup, err := afero.TempDir(i.fs, dest, "."+name+"-")
+	if err != nil {
+		return "", ctxd.WrapError(ctx, err, "could not back up plugin")
+	}
+
+	if err := movePath(i.fs, path, filepath.Join(backup, name)); err != nil {
+		_ = movePath(i.fs, filepath.Join(backup, name), path) // nolint: errcheck
+		_ = i.fs.RemoveAll(backup)                            // nolint: errcheck
+
+		return "", ctxd.WrapError(ctx, err, "could not back up plugin")
+	}
+
+	return backup, nil
+}
+
+// removeBackup removes the previous version of the plugin once the installation succeeds.
+func (i *Installer) removeBackup(ctx context.Context, backup string) {
+	if backup == "" {
+		return
+	}
+
+	if err := i.fs.RemoveAll(backup); err != nil {
+		i.logger.Error(ctx, "could not remove backup", "path", backup, "error", err)
+	}
+}
+
+// rollback removes the installed plugin and restores the previous version from the backup, if any. The name of the
+// plugin is validated so the destination itself is never removed.
+func (i *Installer) rollback(ctx context.Context, dest string, p *plugin.Plugin, backup string) {
+	if err := validateAssetName(p.Name); err != nil {
+		i.logger.Error(ctx, "could not remove plugin", "dest", dest, "error", err)
+
+		return
+	}
+
+	path := filepath.Join(dest, p.Name)
+
+	if err := i.fs.RemoveAll(path); err != nil {
+		i.logger.Error(ctx, "could not remove plugin", "path", path, "error", err)
+
+		return
+	}
+
+	i.logger.Info(ctx, "removed plugin", "path", path)
+
+	if backup == "" {
+		return
+	}
+
+	if err := movePath(i.fs, filepath.Join(backup, p.Name), path); err != nil {
+		i.logger.Error(ctx, "could not restore plugin", "path", path, "backup", backup, "error", err)
+
+		return
+	}
+
+	_ = i.fs.RemoveAll(backup) // nolint: errcheck
+
+	i.logger.Info(ctx, "restored plugin", "path", path)
+}
+
+// verifyAsset checks the asset before downloading it into the temp dir and installing it into the destination.
+func (i *Installer) verifyAsset(ctx context.Context, asset *github.ReleaseAsset, tmpDir, dest string) error {
+	ctx, span := i.startSpan(ctx, "verify", assetAttributes(asset)...)
+
+	err := i.checkAsset(asset, tmpDir, dest)
+	if err != nil {
+		err = ctxd.WrapError(ctx, err, "could not download artifact")
+	}
+
+	endSpan(span, err)
+
+	return err
+}
+
+// downloadArtifact downloads the artifact into the temp dir.
+func (i *Installer) downloadArtifact(ctx context.Context, plan *Plan, tmpDir string) (*download, error) {
+	ctx, span := i.startSpan(ctx, "download asset", append(assetAttributes(plan.asset),
+		attrOwner.String(plan.Owner),
+		attrRepository.String(plan.Repository),
+		attrTag.String(plan.Tag),
+	)...)
+
+	d, err := i.download(ctx, plan, tmpDir)
+
+	endSpan(span, err)
+
+	return d, err
+}
+
+func (i *Installer) download(ctx context.Context, plan *Plan, tmpDir string) (*download, error) {
+	asset := plan.asset
+
+	downloadCtx, cancel := i.phaseContext(ctx, PhaseDownload)
+	defer cancel()
+
+	start := time.Now()
+
+	r, _, err := i.service.DownloadReleaseAsset(downloadCtx, plan.Owner, plan.Repository, *asset.ID, i.downloadClient)
+
+	i.observeAPICall(EndpointDownloadReleaseAsset, nil)
+
+	if err != nil {
+		return nil, ctxd.WrapError(ctx, i.phaseError(ctx, downloadCtx, PhaseDownload, err), "could not download artifact")
+	}
+
+	assetFile := filepath.Join(tmpDir, *asset.Name)
+
+	sniffer := &headerWriter{}
+	counter := &byteCounter{}
+	h := sha256.New()
+	copyFn := hashCopier(h, counter.wrap(sniffer.wrap(i.downloadCopier(contextCopier(downloadCtx, i.minThroughput)))))
+
+	err = writeTempFile(i.fs, assetFile, r, copyFn)
+
+	i.metrics.ObserveDownload(counter.bytes, time.Since(start))
+
+	if err != nil {
+		return nil, ctxd.WrapError(ctx, i.phaseError(ctx, downloadCtx, PhaseDownload, err), "could not write artifact")
 	}
 
-	ctx = fsCtx.WithFs(ctx, i.fs)
+	return &download{dir: tmpDir, file: assetFile, header: sniffer.header, sha256: hex.EncodeToString(h.Sum(nil))}, nil
+}
+
+// installArtifact installs the downloaded artifact with the filesystem installers.
+func (i *Installer) installArtifact(ctx context.Context, dest, source string) (*plugin.Plugin, error) {
+	ctx, span := i.startSpan(ctx, "fs install")
+
+	p, err := i.installSource(ctx, dest, source)
+
+	endSpan(span, err)
+
+	return p, err
+}
+
+func (i *Installer) installSource(ctx context.Context, dest, source string) (*plugin.Plugin, error) {
+	installCtx, cancel := i.phaseContext(ctx, PhaseInstall)
+	defer cancel()
 
-	pkgInstaller, err := installer.Find(ctx, source)
+	installCtx = fsCtx.WithFs(installCtx, newContextFs(installCtx, i.fs))
+
+	pkgInstaller, err := installer.Find(installCtx, source)
 	if err != nil {
 		return nil, err
 	}
 
-	return pkgInstaller.Install(ctx, dest, source)
+	i.logger.Debug(ctx, "found installer", "installer", fmt.Sprintf("%T", pkgInstaller), "path", source)
+
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("plugin.installer", fmt.Sprintf("%T", pkgInstaller)))
+
+	p, err := pkgInstaller.Install(installCtx, dest, source)
+	if err != nil {
+		return nil, i.phaseError(ctx, installCtx, PhaseInstall, err)
+	}
+
+	return p, nil
 }
 
 // WithService sets the repository service.
@@ -190,21 +549,44 @@ func (i *Installer) WithService(service RepositoryService) *Installer {
 // NewInstaller initiates a new github installer.
 func NewInstaller(options ...Option) *Installer {
 	i := &Installer{
-		fs: afero.NewOsFs(),
+		fs:               afero.NewOsFs(),
+		metadataSources:  DefaultMetadataSources(),
+		archFallbacks:    DefaultArchFallbacks(),
+		searchTopic:      DefaultSearchTopic,
+		searchMaxResults: DefaultSearchMaxResults,
+		logger:           ctxd.NoOpLogger{},
+		metrics:          NoOpMetrics{},
 	}
 
 	for _, o := range options {
 		o(i)
 	}
 
-	if i.service == nil {
-		c := github.NewClient(nil)
+	tracerProvider := i.tracerProvider
+	if tracerProvider == nil {
+		tracerProvider = trace.NewNoopTracerProvider()
+	}
+
+	i.tracer = tracerProvider.Tracer(tracerName)
+
+	apiClient, downloadClient := i.newHTTPClients()
+
+	i.downloadClient = downloadClient
+
+	if i.service == nil || i.search == nil {
+		c := github.NewClient(apiClient)
 
 		if i.baseURL != nil {
 			c.BaseURL = i.baseURL
 		}
 
-		i.service = c.Repositories
+		if i.service == nil {
+			i.service = c.Repositories
+		}
+
+		if i.search == nil {
+			i.search = c.Search
+		}
 	}
 
 	return i
@@ -224,6 +606,71 @@ func WithService(service RepositoryService) Option {
 	}
 }
 
+// WithSearchService sets the search service.
+func WithSearchService(service SearchService) Option {
+	return func(i *Installer) {
+		i.search = service
+	}
+}
+
+// WithCommitService sets the service that resolves the commit of the release tags for the provenance. The repository
+// service is used if it could resolve the commits.
+func WithCommitService(service CommitService) Option {
+	return func(i *Installer) {
+		i.commits = service
+	}
+}
+
+// WithReleaseService sets the service that lists the releases of a repository. The repository service is used if it
+// could list the releases.
+func WithReleaseService(service ReleaseService) Option {
+	return func(i *Installer) {
+		i.releases = service
+	}
+}
+
+// WithContentsService sets the service that lists the repository contents to find the plugin metadata. The repository
+// service is used if it could list the contents.
+func WithContentsService(service ContentsService) Option {
+	return func(i *Installer) {
+		i.contents = service
+	}
+}
+
+// WithSearchTopic sets the topic of the plugin repositories for Installer.Search(). An empty topic disables the filter.
+func WithSearchTopic(topic string) Option {
+	return func(i *Installer) {
+		i.searchTopic = topic
+	}
+}
+
+// WithSearchMaxResults sets the number of the repositories that Installer.Search() returns, the pages of the search are
+// not fetched once it is reached. Each result costs a call to the github api to find the latest release. The github
+// search api returns at most 1000 results.
+func WithSearchMaxResults(n int) Option {
+	return func(i *Installer) {
+		if n > 0 {
+			i.searchMaxResults = n
+		}
+	}
+}
+
+// WithSearchMetadataValidation makes Installer.Search() fetch and validate the metadata of the latest release of each
+// plugin repository.
+func WithSearchMetadataValidation() Option {
+	return func(i *Installer) {
+		i.searchValidation = true
+	}
+}
+
+// WithVersionsRuntimeAssetCheck makes Installer.ListVersions() fetch the metadata of every release to find out whether
+// it has an artifact for the target platform.
+func WithVersionsRuntimeAssetCheck() Option {
+	return func(i *Installer) {
+		i.versionsAssetCheck = true
+	}
+}
+
 // WithBaseURL sets the github base url.
 func WithBaseURL(url *url.URL) Option {
 	return func(i *Installer) {
@@ -231,6 +678,112 @@ func WithBaseURL(url *url.URL) Option {
 	}
 }
 
+// WithMetadataSources sets the sources and the order in which the installer looks for the plugin metadata.
+func WithMetadataSources(sources ...MetadataSource) Option {
+	return func(i *Installer) {
+		i.metadataSources = sources
+	}
+}
+
+// WithHTTPClient sets the http client for the github api and for downloading the assets.
+func WithHTTPClient(c *http.Client) Option {
+	return func(i *Installer) {
+		i.httpClient = c
+	}
+}
+
+// WithURLRewriter adds a rewriter for the urls of the github api and of the assets. The first rewriter that matches the
+// url is used.
+func WithURLRewriter(r URLRewriter) Option {
+	return func(i *Installer) {
+		i.urlRewriters = append(i.urlRewriters, r)
+	}
+}
+
+// WithURLRewrite rewrites the urls of the github api and of the assets that start with the prefix, for example to go
+// through an artifact proxy.
+//
+//	github.WithURLRewrite("https://objects.githubusercontent.com/", "https://artifactory.example.com/github-assets/")
+func WithURLRewrite(from, to string) Option {
+	return WithURLRewriter(RewriteURLPrefix(from, to))
+}
+
+// WithHostHeader sets a header, for example an authorization header, to all the requests to the host. The host is
+// matched after the url is rewritten.
+func WithHostHeader(host, key, value string) Option {
+	return func(i *Installer) {
+		if i.hostHeaders == nil {
+			i.hostHeaders = make(map[string]http.Header)
+		}
+
+		if i.hostHeaders[host] == nil {
+			i.hostHeaders[host] = make(http.Header)
+		}
+
+		i.hostHeaders[host].Set(key, value)
+	}
+}
+
+// WithLogger sets the logger for the debug and info events of the installer.
+func WithLogger(logger ctxd.Logger) Option {
+	return func(i *Installer) {
+		i.logger = logger
+	}
+}
+
+// WithMetrics sets the collector of the metrics of the installer.
+func WithMetrics(m Metrics) Option {
+	return func(i *Installer) {
+		i.metrics = m
+	}
+}
+
+// WithTracerProvider enables the tracing of the installation and of the requests to github. The tracing is disabled by
+// default.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(i *Installer) {
+		i.tracerProvider = tp
+	}
+}
+
+// WithPropagator sets the propagator of the trace context into the requests to github. The global propagator is used
+// by default.
+func WithPropagator(p propagation.TextMapPropagator) Option {
+	return func(i *Installer) {
+		i.propagator = p
+	}
+}
+
+// WithTimeouts sets the deadlines of the phases of the installation.
+func WithTimeouts(timeouts Timeouts) Option {
+	return func(i *Installer) {
+		i.timeouts = timeouts
+	}
+}
+
+// WithMinThroughput aborts the download of an artifact with ErrDownloadStalled if less than bytesPerSecond is downloaded
+// on average in a window.
+func WithMinThroughput(bytesPerSecond int64, window time.Duration) Option {
+	return func(i *Installer) {
+		i.minThroughput = throughput{bytesPerSecond: bytesPerSecond, window: window}
+	}
+}
+
+// WithMaxAssetSize sets the maximum size of an artifact, in bytes. The size is checked before and while downloading.
+func WithMaxAssetSize(bytes int64) Option {
+	return func(i *Installer) {
+		i.maxAssetSize = bytes
+	}
+}
+
+// WithMaxDecompressedSize sets the maximum size of an artifact once it is decompressed, in bytes. The plain tars and the
+// xz, zstd and bzip2 artifacts are decompressed before installing and fail with ErrAssetTooLarge if they expand to more.
+func WithMaxDecompressedSize(bytes int64) Option {
+	return func(i *Installer) {
+		i.maxDecompressedSize = bytes
+	}
+}
+
 // RegisterInstaller registers the installer.
 func RegisterInstaller(options ...Option) {
 	installer.Register(githubHostname,
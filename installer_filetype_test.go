@@ -0,0 +1,205 @@
+package github_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/nhatthm/aferoassert"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	github "github.com/nhatthm/plugin-registry-github"
+	"github.com/nhatthm/plugin-registry-github/mock/service"
+)
+
+func newTarArchive(files map[string]string) []byte {
+	var buf bytes.Buffer
+
+	tw := tar.NewWriter(&buf)
+
+	for path, content := range files {
+		_ = tw.WriteHeader(&tar.Header{Name: path, Mode: 0o755, Size: int64(len(content))}) // nolint: errcheck
+		_, _ = tw.Write([]byte(content))                                                    // nolint: errcheck
+	}
+
+	_ = tw.Close() // nolint: errcheck
+
+	return buf.Bytes()
+}
+
+func TestIntegrationInstaller_Install_DetectFileType(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario    string
+		assetName   string
+		contentType string
+		fixture     string
+		asset       []byte
+	}{
+		{
+			scenario:    "executable with a custom content type",
+			assetName:   "my-plugin-linux-amd64",
+			contentType: "application/x-executable",
+			fixture:     "resources/fixtures/binary/my-plugin",
+		},
+		{
+			scenario:    "executable with an extension and without content type",
+			assetName:   "my-plugin.bin",
+			contentType: "",
+			fixture:     "resources/fixtures/binary/my-plugin",
+		},
+		{
+			scenario:    "tar gzip without extension",
+			assetName:   "my-plugin-linux-amd64",
+			contentType: "binary/octet-stream",
+			fixture:     "resources/fixtures/gzip/my-plugin.tar.gz",
+		},
+		{
+			scenario:    "gzip binary with a wrong extension",
+			assetName:   "my-plugin.tar.gz",
+			contentType: "application/octet-stream",
+			fixture:     "resources/fixtures/gzip/my-plugin-no-parent.gz",
+		},
+		{
+			scenario:    "zip without extension",
+			assetName:   "my-plugin",
+			contentType: "application/octet-stream",
+			fixture:     "resources/fixtures/zip/my-plugin.zip",
+		},
+		{
+			scenario:    "tar with a wrong extension",
+			assetName:   "my-plugin.tar.gz",
+			contentType: "application/gzip",
+			asset:       newTarArchive(map[string]string{"my-plugin/my-plugin": "#!/bin/bash\n"}),
+		},
+		{
+			scenario:    "tar",
+			assetName:   "my-plugin.tar",
+			contentType: "application/x-tar",
+			asset:       newTarArchive(map[string]string{"my-plugin/my-plugin": "#!/bin/bash\n"}),
+		},
+		{
+			scenario:    "xz tar",
+			assetName:   "my-plugin-linux-amd64.tar.xz",
+			contentType: "application/octet-stream",
+			fixture:     "resources/fixtures/xz/my-plugin.tar.xz",
+		},
+		{
+			scenario:    "xz binary",
+			assetName:   "my-plugin.xz",
+			contentType: "application/octet-stream",
+			fixture:     "resources/fixtures/xz/my-plugin-no-parent.xz",
+		},
+		{
+			scenario:    "zstd tar",
+			assetName:   "my-plugin-linux-amd64.tar.zst",
+			contentType: "application/octet-stream",
+			fixture:     "resources/fixtures/zstd/my-plugin.tar.zst",
+		},
+		{
+			scenario:    "zstd binary",
+			assetName:   "my-plugin.zst",
+			contentType: "application/octet-stream",
+			fixture:     "resources/fixtures/zstd/my-plugin-no-parent.zst",
+		},
+		{
+			scenario:    "bzip2 tar",
+			assetName:   "my-plugin-linux-amd64.tar.bz2",
+			contentType: "application/octet-stream",
+			fixture:     "resources/fixtures/bzip2/my-plugin.tar.bz2",
+		},
+		{
+			scenario:    "bzip2 binary",
+			assetName:   "my-plugin.bz2",
+			contentType: "application/octet-stream",
+			fixture:     "resources/fixtures/bzip2/my-plugin-no-parent.bz2",
+		},
+		{
+			scenario:    "xz binary named as the plugin",
+			assetName:   "my-plugin",
+			contentType: "application/octet-stream",
+			fixture:     "resources/fixtures/xz/my-plugin-no-parent.xz",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			osFs := afero.NewOsFs()
+			dest := t.TempDir()
+
+			s := service.MockRepositoryService(mockPlugin(testPlugin{
+				assetName:   tc.assetName,
+				contentType: tc.contentType,
+				fixture:     tc.fixture,
+				asset:       tc.asset,
+			}))(t)
+
+			i := github.NewInstaller(github.WithFs(osFs), github.WithService(s))
+
+			result, err := i.Install(context.Background(), dest, "github.com/owner/my-plugin@v1.4.2")
+			require.NoError(t, err)
+
+			assert.Equal(t, "my-plugin", result.Name)
+
+			file := filepath.Join(dest, "my-plugin", "my-plugin")
+
+			aferoassert.FileContent(t, osFs, file, "#!/bin/bash\n")
+
+			if runtime.GOOS != "windows" {
+				aferoassert.Perm(t, osFs, file, 0o755)
+			}
+		})
+	}
+}
+
+func TestIntegrationInstaller_Install_MaxDecompressedSize(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario  string
+		assetName string
+		fixture   string
+	}{
+		{scenario: "xz tar", assetName: "my-plugin.tar.xz", fixture: "resources/fixtures/xz/my-plugin.tar.xz"},
+		{scenario: "zstd tar", assetName: "my-plugin.tar.zst", fixture: "resources/fixtures/zstd/my-plugin.tar.zst"},
+		{scenario: "bzip2 tar", assetName: "my-plugin.tar.bz2", fixture: "resources/fixtures/bzip2/my-plugin.tar.bz2"},
+		{scenario: "xz binary", assetName: "my-plugin.xz", fixture: "resources/fixtures/xz/my-plugin-no-parent.xz"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			osFs := afero.NewOsFs()
+			dest := t.TempDir()
+
+			s := service.MockRepositoryService(mockPlugin(testPlugin{assetName: tc.assetName, fixture: tc.fixture}))(t)
+
+			i := github.NewInstaller(
+				github.WithFs(osFs),
+				github.WithService(s),
+				github.WithMaxAssetSize(1<<20),
+				github.WithMaxDecompressedSize(8),
+			)
+
+			result, err := i.Install(context.Background(), dest, "github.com/owner/my-plugin@v1.4.2")
+
+			assert.Nil(t, result)
+			assert.True(t, errors.Is(err, github.ErrAssetTooLarge))
+			assert.EqualError(t, err, "could not decompress artifact: asset too large: more than 8 bytes once decompressed")
+
+			aferoassert.NoFileExists(t, osFs, filepath.Join(dest, "my-plugin"))
+		})
+	}
+}
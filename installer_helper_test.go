@@ -3,13 +3,20 @@ package github_test
 import (
 	"fmt"
 	"io"
+	"net/http"
+	"net/http/httptest"
 	"path/filepath"
+	"runtime"
+	"time"
 
 	goGitHub "github.com/google/go-github/v35/github"
 	"github.com/nhatthm/plugin-registry/plugin"
 	"github.com/spf13/afero"
 	"github.com/spf13/afero/mem"
 	"github.com/stretchr/testify/mock"
+
+	github "github.com/nhatthm/plugin-registry-github"
+	"github.com/nhatthm/plugin-registry-github/mock/service"
 )
 
 func newEmptyFile(name string) afero.File {
@@ -53,33 +60,70 @@ func newMetadataFileFromStringf(format string, args ...interface{}) afero.File {
 	return newMetadataFileFromString(fmt.Sprintf(format, args...))
 }
 
-func newRelease(tagName string) *goGitHub.RepositoryRelease {
-	return &goGitHub.RepositoryRelease{
+// releaseOption configures a release of the tests.
+type releaseOption func(r *goGitHub.RepositoryRelease)
+
+func newRelease(tagName string, options ...releaseOption) *goGitHub.RepositoryRelease {
+	r := &goGitHub.RepositoryRelease{
 		TagName: &tagName,
 	}
-}
 
-func newReleaseWithArtifact(tagName, fileName string) *goGitHub.RepositoryRelease {
-	r := newRelease(tagName)
-	r.Assets = []*goGitHub.ReleaseAsset{
-		{
-			ID:   int64Ptr(42),
-			Name: &fileName,
-		},
+	for _, o := range options {
+		o(r)
 	}
 
 	return r
 }
 
-func newReleaseWithArtifactAndContentType(tagName, fileName, contentType string) *goGitHub.RepositoryRelease {
-	r := newRelease(tagName)
-	r.Assets = []*goGitHub.ReleaseAsset{
-		{
-			ID:          int64Ptr(42),
-			Name:        &fileName,
-			ContentType: &contentType,
-		},
+// withAssets adds the assets to the release, their ids start at 42.
+func withAssets(fileNames ...string) releaseOption {
+	return func(r *goGitHub.RepositoryRelease) {
+		for _, name := range fileNames {
+			r.Assets = append(r.Assets, &goGitHub.ReleaseAsset{
+				ID:   int64Ptr(int64(42 + len(r.Assets))),
+				Name: stringPtr(name),
+			})
+		}
+	}
+}
+
+func withName(name string) releaseOption {
+	return func(r *goGitHub.RepositoryRelease) {
+		r.Name = &name
 	}
+}
+
+func withBody(body string) releaseOption {
+	return func(r *goGitHub.RepositoryRelease) {
+		r.Body = &body
+	}
+}
+
+func withPublishedAt(publishedAt time.Time) releaseOption {
+	return func(r *goGitHub.RepositoryRelease) {
+		r.PublishedAt = &goGitHub.Timestamp{Time: publishedAt}
+	}
+}
+
+func withPrerelease() releaseOption {
+	return func(r *goGitHub.RepositoryRelease) {
+		r.Prerelease = boolPtr(true)
+	}
+}
+
+func withDraft() releaseOption {
+	return func(r *goGitHub.RepositoryRelease) {
+		r.Draft = boolPtr(true)
+	}
+}
+
+func newReleaseWithArtifact(tagName, fileName string) *goGitHub.RepositoryRelease {
+	return newRelease(tagName, withAssets(fileName))
+}
+
+func newReleaseWithArtifactAndContentType(tagName, fileName, contentType string) *goGitHub.RepositoryRelease {
+	r := newReleaseWithArtifact(tagName, fileName)
+	r.Assets[0].ContentType = &contentType
 
 	return r
 }
@@ -89,6 +133,110 @@ func newReleaseWithArtifactf(tagName string, format string, args ...interface{})
 	return newReleaseWithArtifact(tagName, fmt.Sprintf(format, args...))
 }
 
+// testPlugin is the release v1.4.2 of owner/my-plugin, that has the artifact of the runtime.
+type testPlugin struct {
+	// assetName is the name of the artifact, the default is my-plugin.tar.gz.
+	assetName string
+	// contentType is the content type of the artifact.
+	contentType string
+	// fixture is the file of the artifact, the default is the gzip fixture.
+	fixture string
+	// asset is the content of the artifact, it replaces the fixture.
+	asset []byte
+	// extraMetadata is appended to the metadata.
+	extraMetadata string
+	// metadata replaces the metadata that has the artifact of the runtime.
+	metadata string
+	// extraAssetName is the name of an asset that is added to the release after the artifact.
+	extraAssetName string
+	// extraAsset is the content of the extra asset.
+	extraAsset []byte
+	// response is the response of GetReleaseByTag.
+	response *goGitHub.Response
+	// noDownload does not expect the artifact to be downloaded.
+	noDownload bool
+	// maybe allows the metadata and the artifact not to be downloaded.
+	maybe bool
+}
+
+// mockPlugin mocks the release, the metadata and the assets of the plugin.
+func mockPlugin(p testPlugin) func(s *service.RepositoryService) {
+	if p.assetName == "" {
+		p.assetName = "my-plugin.tar.gz"
+	}
+
+	if p.fixture == "" {
+		p.fixture = "resources/fixtures/gzip/my-plugin.tar.gz"
+	}
+
+	if p.metadata == "" {
+		p.metadata = fmt.Sprintf("name: my-plugin\nartifacts:\n  %s/%s:\n    file: %s\n",
+			runtime.GOOS, runtime.GOARCH, p.assetName)
+	}
+
+	return func(s *service.RepositoryService) {
+		release := newReleaseWithArtifactAndContentType("v1.4.2", p.assetName, p.contentType)
+
+		if p.extraAssetName != "" {
+			withAssets(p.extraAssetName)(release)
+
+			s.On("DownloadReleaseAsset", mock.Anything, "owner", "my-plugin", int64(43), mock.Anything).
+				Return(newFileWithData(newEmptyFile(p.extraAssetName), p.extraAsset), "", nil)
+		}
+
+		s.On("GetReleaseByTag", mock.Anything, "owner", "my-plugin", "v1.4.2").
+			Return(release, p.response, nil)
+
+		metadata := s.On("DownloadContents", mock.Anything, "owner", "my-plugin", ".plugin.registry.yaml", mock.Anything).
+			Return(newMetadataFileFromString(p.metadata+p.extraMetadata), nil, nil)
+
+		for _, c := range append(mockMetadataNotFound(s, "my-plugin"), metadata) {
+			if p.maybe {
+				c.Maybe()
+			} else {
+				c.Once()
+			}
+		}
+
+		if p.noDownload {
+			return
+		}
+
+		asset := newShadowedFile(p.assetName, p.fixture)
+
+		if p.asset != nil {
+			asset = newFileWithData(newEmptyFile(p.assetName), p.asset)
+		}
+
+		download := s.On("DownloadReleaseAsset", mock.Anything, "owner", "my-plugin", int64(42), mock.Anything).
+			Return(asset, "", nil)
+
+		if p.maybe {
+			download.Maybe()
+		}
+	}
+}
+
+// mockMetadataNotFound mocks the metadata files in the other formats than yaml, that are not in the repository.
+func mockMetadataNotFound(s *service.RepositoryService, repository string) []*mock.Call {
+	files := []string{github.MetadataFileJSON, github.MetadataFileTOML}
+	calls := make([]*mock.Call, 0, len(files))
+
+	for _, file := range files {
+		calls = append(calls, s.On("DownloadContents", mock.Anything, "owner", repository, file, mock.Anything).
+			Return(nil, nil, fmt.Errorf("No file named %s found in .", file))) // nolint: goerr113
+	}
+
+	return calls
+}
+
+func newHTTPResponse(status int) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Request:    httptest.NewRequest(http.MethodGet, "https://api.github.com/", nil),
+	}
+}
+
 func expectFileName(expect string) interface{} {
 	return mock.MatchedBy(func(actual string) bool {
 		return filepath.Base(filepath.Clean(actual)) == expect
@@ -110,3 +258,7 @@ func stringPtr(i string) *string {
 func stringPtrf(format string, args ...interface{}) *string {
 	return stringPtr(fmt.Sprintf(format, args...))
 }
+
+func boolPtr(b bool) *bool {
+	return &b
+}
@@ -2,6 +2,8 @@ package github_test
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -64,14 +66,14 @@ func mockServerAssets(file string) func(s *httpmock.Server) {
 		s.ExpectGet("/repos/owner/my-plugin/contents/?ref=v1.4.2").
 			ReturnJSON([]*goGitHub.RepositoryContent{
 				{
-					Name:        stringPtr(".plugin.registry.yaml"),
-					DownloadURL: stringPtrf("%s/owner/my-plugin/v1.4.2/.plugin.registry.yaml", s.URL()),
+					Name: stringPtr(".plugin.registry.yaml"),
+					Type: stringPtr("file"),
 				},
 			})
 
-		s.ExpectGet("/owner/my-plugin/v1.4.2/.plugin.registry.yaml").
+		s.ExpectGet("/repos/owner/my-plugin/contents/.plugin.registry.yaml?ref=v1.4.2").
 			Run(func(*http.Request) ([]byte, error) {
-				return yaml.Marshal(plugin.Plugin{
+				metadata, err := yaml.Marshal(plugin.Plugin{
 					Name:   "my-plugin",
 					Hidden: true,
 					Artifacts: plugin.Artifacts{
@@ -80,11 +82,24 @@ func mockServerAssets(file string) func(s *httpmock.Server) {
 						},
 					},
 				})
+				if err != nil {
+					return nil, err
+				}
+
+				return json.Marshal(goGitHub.RepositoryContent{
+					Name:     stringPtr(".plugin.registry.yaml"),
+					Type:     stringPtr("file"),
+					Encoding: stringPtr("base64"),
+					Content:  stringPtr(base64.StdEncoding.EncodeToString(metadata)),
+				})
 			})
 
 		s.ExpectGet("/repos/owner/my-plugin/releases/assets/42").
 			WithHeader("Accept", "application/octet-stream").
 			ReturnFile(file)
+
+		s.ExpectGet("/repos/owner/my-plugin/commits/v1.4.2").
+			Return("6dcb09b5b57875f334f61aebed695e2e4193db5e")
 	}
 }
 
@@ -309,4 +324,10 @@ func TestWithBaseURL(t *testing.T) {
 
 	aferoassert.Perm(t, osFs, file, 0o755)
 	aferoassert.FileContent(t, osFs, file, "#!/bin/bash\n")
+
+	provenance, err := github.ReadProvenance(osFs, filepath.Join(dest, result.Name))
+	require.NoError(t, err)
+
+	assert.Equal(t, u.Host, provenance.Host)
+	assert.Equal(t, "6dcb09b5b57875f334f61aebed695e2e4193db5e", provenance.Commit)
 }
@@ -103,6 +103,8 @@ func TestInstaller_Install(t *testing.T) {
 				s.On("DownloadContents", mock.Anything, "owner", "my-plugin", ".plugin.registry.yaml",
 					&goGitHub.RepositoryContentGetOptions{Ref: "v1.4.2"}).
 					Return(newEmptyMetadataFile(), nil, nil)
+
+				mockMetadataNotFound(s, "my-plugin")
 			}),
 			expectedError: "could not load plugin metadata: EOF",
 		},
@@ -116,6 +118,8 @@ func TestInstaller_Install(t *testing.T) {
 				s.On("DownloadContents", mock.Anything, "owner", "my-plugin", ".plugin.registry.yaml",
 					&goGitHub.RepositoryContentGetOptions{Ref: "v1.4.2"}).
 					Return(newMetadataFile("resources/fixtures/.plugin.registry.yaml"), nil, nil)
+
+				mockMetadataNotFound(s, "my-plugin")
 			}),
 			expectedError: "could not find artifact: artifact not found",
 		},
@@ -129,12 +133,20 @@ func TestInstaller_Install(t *testing.T) {
 				s.On("DownloadContents", mock.Anything, "owner", "my-plugin", ".plugin.registry.yaml",
 					&goGitHub.RepositoryContentGetOptions{Ref: "v1.4.2"}).
 					Return(newMetadataFile("resources/fixtures/.plugin.registry.yaml"), nil, nil)
+
+				mockMetadataNotFound(s, "my-plugin")
 			}),
 			expectedError: "could not find artifact: artifact not found",
 		},
 		{
 			scenario: "could not download artifact",
 			source:   "github.com/owner/my-plugin@v1.4.2",
+			mockFs: aferomock.MockFs(func(fs *aferomock.Fs) {
+				fs.On("Mkdir", mock.Anything, os.FileMode(0o700)).
+					Return(nil)
+
+				fs.On("RemoveAll", mock.Anything).Return(nil)
+			}),
 			mockService: service.MockRepositoryService(func(s *service.RepositoryService) {
 				artifact := newReleaseWithArtifactf("v1.4.2", "my-plugin-1.4.2-%s-%s.tar.gz", runtime.GOOS, runtime.GOARCH)
 
@@ -145,6 +157,8 @@ func TestInstaller_Install(t *testing.T) {
 					&goGitHub.RepositoryContentGetOptions{Ref: "v1.4.2"}).
 					Return(newMetadataFile("resources/fixtures/.plugin.registry.yaml"), nil, nil)
 
+				mockMetadataNotFound(s, "my-plugin")
+
 				s.On("DownloadReleaseAsset", mock.Anything, "owner", "my-plugin", int64(42), http.DefaultClient).
 					Return(nil, "", errors.New("download error"))
 			}),
@@ -167,8 +181,7 @@ func TestInstaller_Install(t *testing.T) {
 					&goGitHub.RepositoryContentGetOptions{Ref: "v1.4.2"}).
 					Return(newMetadataFile("resources/fixtures/.plugin.registry.yaml"), nil, nil)
 
-				s.On("DownloadReleaseAsset", mock.Anything, "owner", "my-plugin", int64(42), http.DefaultClient).
-					Return(newEmptyFile("my-plugin.tar.gz"), "", nil)
+				mockMetadataNotFound(s, "my-plugin")
 			}),
 			expectedError: "could not create temp dir: mkdir error",
 		},
@@ -181,7 +194,7 @@ func TestInstaller_Install(t *testing.T) {
 
 				fs.On("OpenFile",
 					expectFileNamef("my-plugin-1.4.2-%s-%s.tar.gz", runtime.GOOS, runtime.GOARCH),
-					os.O_CREATE|os.O_RDWR, os.FileMode(0o644)).
+					os.O_CREATE|os.O_EXCL|os.O_RDWR, os.FileMode(0o600)).
 					Return(nil, errors.New("open error"))
 
 				fs.On("RemoveAll", mock.Anything).Return(nil)
@@ -196,6 +209,8 @@ func TestInstaller_Install(t *testing.T) {
 					&goGitHub.RepositoryContentGetOptions{Ref: "v1.4.2"}).
 					Return(newMetadataFile("resources/fixtures/.plugin.registry.yaml"), nil, nil)
 
+				mockMetadataNotFound(s, "my-plugin")
+
 				s.On("DownloadReleaseAsset", mock.Anything, "owner", "my-plugin", int64(42), http.DefaultClient).
 					Return(newEmptyFile("my-plugin.tar.gz"), "", nil)
 			}),
@@ -210,7 +225,7 @@ func TestInstaller_Install(t *testing.T) {
 
 				fs.On("OpenFile",
 					expectFileNamef("my-plugin-1.4.2-%s-%s.tar.gz", runtime.GOOS, runtime.GOARCH),
-					os.O_CREATE|os.O_RDWR, os.FileMode(0o644)).
+					os.O_CREATE|os.O_EXCL|os.O_RDWR, os.FileMode(0o600)).
 					Return(newEmptyFile("my-plugin.tar.gz"), nil)
 
 				fs.On("RemoveAll", mock.Anything).Return(nil)
@@ -225,6 +240,8 @@ func TestInstaller_Install(t *testing.T) {
 					&goGitHub.RepositoryContentGetOptions{Ref: "v1.4.2"}).
 					Return(newMetadataFile("resources/fixtures/.plugin.registry.yaml"), nil, nil)
 
+				mockMetadataNotFound(s, "my-plugin")
+
 				asset := newEmptyFile("my-plugin.tar.gz")
 				_ = asset.Close() // nolint: errcheck
 
@@ -242,7 +259,7 @@ func TestInstaller_Install(t *testing.T) {
 
 				fs.On("OpenFile",
 					expectFileNamef("my-plugin-1.4.2-%s-%s.tar.gz", runtime.GOOS, runtime.GOARCH),
-					os.O_CREATE|os.O_RDWR, os.FileMode(0o644)).
+					os.O_CREATE|os.O_EXCL|os.O_RDWR, os.FileMode(0o600)).
 					Return(newEmptyFile("my-plugin.tar.gz"), nil)
 
 				fs.On("Chmod",
@@ -263,6 +280,8 @@ func TestInstaller_Install(t *testing.T) {
 					&goGitHub.RepositoryContentGetOptions{Ref: "v1.4.2"}).
 					Return(newMetadataFile("resources/fixtures/.plugin.registry.yaml"), nil, nil)
 
+				mockMetadataNotFound(s, "my-plugin")
+
 				s.On("DownloadReleaseAsset", mock.Anything, "owner", "my-plugin", int64(42), http.DefaultClient).
 					Return(newEmptyFile("my-plugin.tar.gz"), "", nil)
 			}),
@@ -277,14 +296,14 @@ func TestInstaller_Install(t *testing.T) {
 
 				fs.On("OpenFile",
 					expectFileNamef("my-plugin-1.4.2-%s-%s.tar.gz", runtime.GOOS, runtime.GOARCH),
-					os.O_CREATE|os.O_RDWR, os.FileMode(0o644)).
+					os.O_CREATE|os.O_EXCL|os.O_RDWR, os.FileMode(0o600)).
 					Return(newEmptyFile("my-plugin.tar.gz"), nil)
 
 				metadataFile := newEmptyFile(".plugin.registry.yaml")
 				_ = metadataFile.Close() // nolint: errcheck
 
 				fs.On("OpenFile",
-					expectFileName(".plugin.registry.yaml"), os.O_CREATE|os.O_RDWR, os.FileMode(0o644)).
+					expectFileName(".plugin.registry.yaml"), os.O_CREATE|os.O_EXCL|os.O_RDWR, os.FileMode(0o600)).
 					Return(metadataFile, nil)
 
 				fs.On("RemoveAll", mock.Anything).Return(nil)
@@ -299,6 +318,8 @@ func TestInstaller_Install(t *testing.T) {
 					&goGitHub.RepositoryContentGetOptions{Ref: "v1.4.2"}).
 					Return(newMetadataFile("resources/fixtures/.plugin.registry.yaml"), nil, nil)
 
+				mockMetadataNotFound(s, "my-plugin")
+
 				s.On("DownloadReleaseAsset", mock.Anything, "owner", "my-plugin", int64(42), http.DefaultClient).
 					Return(newEmptyFile("my-plugin.tar.gz"), "", nil)
 			}),
@@ -312,13 +333,16 @@ func TestInstaller_Install(t *testing.T) {
 					Return(nil)
 
 				fs.On("OpenFile",
-					expectFileName("my-plugin.7z"), os.O_CREATE|os.O_RDWR, os.FileMode(0o644)).
+					expectFileName("my-plugin.7z"), os.O_CREATE|os.O_EXCL|os.O_RDWR, os.FileMode(0o600)).
 					Return(newEmptyFile("my-plugin.7z"), nil)
 
 				fs.On("OpenFile",
-					expectFileName(".plugin.registry.yaml"), os.O_CREATE|os.O_RDWR, os.FileMode(0o644)).
+					expectFileName(".plugin.registry.yaml"), os.O_CREATE|os.O_EXCL|os.O_RDWR, os.FileMode(0o600)).
 					Return(newEmptyFile(".plugin.registry.yaml"), nil)
 
+				fs.On("Stat", "/tmp/my-plugin").
+					Return(nil, os.ErrNotExist)
+
 				fs.On("Stat", expectFileName("my-plugin.7z")).
 					Return(aferomock.NewFileInfo(func(i *aferomock.FileInfo) {
 						i.On("IsDir").Return(false)
@@ -344,6 +368,8 @@ artifacts:
 					&goGitHub.RepositoryContentGetOptions{Ref: "v1.4.2"}).
 					Return(metadataFile, nil, nil)
 
+				mockMetadataNotFound(s, "my-plugin")
+
 				s.On("DownloadReleaseAsset", mock.Anything, "owner", "my-plugin", int64(42), http.DefaultClient).
 					Return(newEmptyFile("my-plugin.7z"), "", nil)
 			}),
@@ -357,13 +383,16 @@ artifacts:
 					Return(nil)
 
 				fs.On("OpenFile",
-					expectFileName("my-plugin.fail"), os.O_CREATE|os.O_RDWR, os.FileMode(0o644)).
+					expectFileName("my-plugin.fail"), os.O_CREATE|os.O_EXCL|os.O_RDWR, os.FileMode(0o600)).
 					Return(newEmptyFile("my-plugin.fail"), nil)
 
 				fs.On("OpenFile",
-					expectFileName(".plugin.registry.yaml"), os.O_CREATE|os.O_RDWR, os.FileMode(0o644)).
+					expectFileName(".plugin.registry.yaml"), os.O_CREATE|os.O_EXCL|os.O_RDWR, os.FileMode(0o600)).
 					Return(newEmptyFile(".plugin.registry.yaml"), nil)
 
+				fs.On("Stat", "/tmp/my-plugin").
+					Return(nil, os.ErrNotExist)
+
 				fs.On("Stat", expectFileName("my-plugin.fail")).Maybe().
 					Return(aferomock.NewFileInfo(func(i *aferomock.FileInfo) {
 						i.On("IsDir").Return(false)
@@ -389,6 +418,8 @@ artifacts:
 					&goGitHub.RepositoryContentGetOptions{Ref: "v1.4.2"}).
 					Return(metadataFile, nil, nil)
 
+				mockMetadataNotFound(s, "my-plugin")
+
 				s.On("DownloadReleaseAsset", mock.Anything, "owner", "my-plugin", int64(42), http.DefaultClient).
 					Return(newEmptyFile("my-plugin.fail"), "", nil)
 			}),
@@ -402,11 +433,11 @@ artifacts:
 					Return(nil)
 
 				fs.On("OpenFile",
-					expectFileName("my-plugin.success"), os.O_CREATE|os.O_RDWR, os.FileMode(0o644)).
+					expectFileName("my-plugin.success"), os.O_CREATE|os.O_EXCL|os.O_RDWR, os.FileMode(0o600)).
 					Return(newEmptyFile("my-plugin.success"), nil)
 
 				fs.On("OpenFile",
-					expectFileName(".plugin.registry.yaml"), os.O_CREATE|os.O_RDWR, os.FileMode(0o644)).
+					expectFileName(".plugin.registry.yaml"), os.O_CREATE|os.O_EXCL|os.O_RDWR, os.FileMode(0o600)).
 					Return(newEmptyFile(".plugin.registry.yaml"), nil)
 
 				fs.On("Stat", expectFileName("my-plugin.success")).Maybe().
@@ -415,6 +446,12 @@ artifacts:
 						i.On("Name").Return("my-plugin.success")
 					}), nil)
 
+				fs.On("Stat", "/tmp/my-plugin").
+					Return(nil, os.ErrNotExist)
+
+				fs.On("OpenFile", "/tmp/my-plugin/.plugin.provenance.json", os.O_CREATE|os.O_TRUNC|os.O_RDWR, os.FileMode(0o644)).
+					Return(newEmptyFile(".plugin.provenance.json"), nil)
+
 				fs.On("RemoveAll", mock.Anything).Return(nil)
 			}),
 			mockService: service.MockRepositoryService(func(s *service.RepositoryService) {
@@ -434,6 +471,8 @@ artifacts:
 					&goGitHub.RepositoryContentGetOptions{Ref: "v1.4.2"}).
 					Return(metadataFile, nil, nil)
 
+				mockMetadataNotFound(s, "my-plugin")
+
 				s.On("DownloadReleaseAsset", mock.Anything, "owner", "my-plugin", int64(42), http.DefaultClient).
 					Return(newEmptyFile("my-plugin.success"), "", nil)
 			}),
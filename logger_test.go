@@ -0,0 +1,96 @@
+package github_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/bool64/ctxd"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	github "github.com/nhatthm/plugin-registry-github"
+	"github.com/nhatthm/plugin-registry-github/mock/service"
+)
+
+func TestInstaller_Install_Logger(t *testing.T) {
+	t.Parallel()
+
+	s := service.MockRepositoryService(mockPlugin(testPlugin{}))(t)
+
+	logger := &ctxd.LoggerMock{}
+
+	i := github.NewInstaller(
+		github.WithFs(afero.NewOsFs()),
+		github.WithService(s),
+		github.WithMetadataSources(github.MetadataFromContents),
+		github.WithLogger(logger),
+	)
+
+	_, err := i.Install(context.Background(), t.TempDir(), "github.com/owner/my-plugin@v1.4.2")
+	require.NoError(t, err)
+
+	messages := make([]string, 0, len(logger.LoggedEntries))
+
+	for _, e := range logger.LoggedEntries {
+		messages = append(messages, e.Level+": "+e.Message)
+
+		assert.Equal(t, "github.com/owner/my-plugin@v1.4.2", e.Data["source"])
+		assert.Equal(t, "owner", e.Data["owner"])
+		assert.Equal(t, "my-plugin", e.Data["repository"])
+	}
+
+	expected := []string{
+		"debug: resolving plugin",
+		"debug: found release",
+		"debug: found plugin metadata",
+		"info: resolved artifact",
+		"debug: downloaded artifact",
+		"debug: detected artifact type",
+		"debug: found installer",
+		"info: installed plugin",
+	}
+
+	assert.Equal(t, expected, messages)
+
+	resolved := logger.LoggedEntries[3].Data
+
+	assert.Equal(t, "v1.4.2", resolved["tag"])
+	assert.Equal(t, "my-plugin.tar.gz", resolved["artifact"])
+	assert.Equal(t, int64(42), resolved["assetID"])
+
+	assert.Equal(t, "tar.gz", logger.LoggedEntries[5].Data["type"])
+	assert.Equal(t, "*fs.ArchiveInstaller", logger.LoggedEntries[6].Data["installer"])
+}
+
+func TestInstaller_ResolveDependencies_Logger(t *testing.T) {
+	t.Parallel()
+
+	logger := &ctxd.LoggerMock{}
+
+	i := github.NewInstaller(
+		github.WithService(newDependencyService(t,
+			testDependencyRelease{repository: "app", tag: "v1.0.0", metadata: dependsOn("lib", "", "z", "")},
+			testDependencyRelease{repository: "lib", tag: "v1.0.0"},
+			testDependencyRelease{repository: "lib", tag: "v2.0.0"},
+			testDependencyRelease{repository: "z", tag: "v1.0.0", metadata: dependsOn("lib", "< 2")},
+		)),
+		github.WithMetadataSources(github.MetadataFromContents),
+		github.WithLogger(logger),
+	)
+
+	_, err := i.ResolveDependencies(context.Background(), "github.com/owner/app@v1.0.0")
+	require.NoError(t, err)
+
+	var lookups []string
+
+	for _, e := range logger.LoggedEntries {
+		if e.Message == "looked up releases" {
+			lookups = append(lookups, fmt.Sprintf("%s: %v", e.Data["repository"], e.Data["cacheHit"]))
+		}
+	}
+
+	// The releases of lib are listed once, and are looked up again when z excludes lib@v2.0.0.
+	assert.Equal(t, []string{"lib: false", "z: false", "lib: true"}, lookups)
+}
@@ -0,0 +1,426 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/bool64/ctxd"
+	"github.com/google/go-github/v35/github"
+	"github.com/nhatthm/plugin-registry/plugin"
+	"go.opentelemetry.io/otel/trace"
+	"gopkg.in/yaml.v3"
+)
+
+// maxMetadataSize is the maximum size of the plugin metadata.
+const maxMetadataSize = 1 << 20
+
+// MetadataSource is a place where the installer looks for the plugin metadata.
+type MetadataSource string
+
+const (
+	// MetadataFromAssets reads the metadata from the release assets.
+	MetadataFromAssets MetadataSource = "assets"
+	// MetadataFromContents reads the metadata from the repository contents at the release tag.
+	MetadataFromContents MetadataSource = "contents"
+	// MetadataFromReleaseBody reads the metadata from a fenced block embedded in the release body.
+	MetadataFromReleaseBody MetadataSource = "release-body"
+)
+
+var (
+	// ErrMetadataNotFound indicates that the plugin metadata is not found in any of the metadata sources.
+	ErrMetadataNotFound = errors.New("plugin metadata not found")
+	// ErrUnknownMetadataSource indicates that the metadata source is not supported.
+	ErrUnknownMetadataSource = errors.New("unknown metadata source")
+	// ErrMetadataTooLarge indicates that the plugin metadata is larger than the maximum size.
+	ErrMetadataTooLarge = errors.New("plugin metadata too large")
+)
+
+// releaseBodyMetadataPattern matches a fenced block like:
+//
+//	```plugin-registry
+//	name: my-plugin
+//	```
+var releaseBodyMetadataPattern = regexp.MustCompile("(?s)```[ \t]*(?:yaml[ \t]+)?plugin-registry[ \t]*\r?\n(.*?)\r?\n[ \t]*```")
+
+// DefaultMetadataSources returns the default order of the metadata sources.
+func DefaultMetadataSources() []MetadataSource {
+	return []MetadataSource{MetadataFromAssets, MetadataFromContents, MetadataFromReleaseBody}
+}
+
+// metadataExtension is the part of the plugin metadata that is not in plugin.Plugin.
+type metadataExtension struct {
+	SmokeTest    *SmokeTest        `yaml:"verify"`
+	Dependencies []Dependency      `yaml:"dependencies"`
+	Hosts        map[string]string `yaml:"hosts"`
+}
+
+// loadReleaseMetadata fetches and loads the plugin metadata of a release.
+func (i *Installer) loadReleaseMetadata(
+	ctx context.Context,
+	owner, repository string,
+	release *github.RepositoryRelease,
+) (*plugin.Plugin, *metadataExtension, error) {
+	data, err := i.fetchReleaseMetadata(ctx, owner, repository, release)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	p, err := loadMetadata(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, rejectRelease(ctxd.WrapError(ctx, err, "could not load plugin metadata"))
+	}
+
+	var ext metadataExtension
+
+	if err := yaml.Unmarshal(data, &ext); err != nil {
+		return nil, nil, rejectRelease(ctxd.WrapError(ctx, err, "could not load plugin metadata"))
+	}
+
+	p.Version = trimVersion(release.GetTagName())
+	p.URL = fmt.Sprintf("https://github.com/%s/%s", owner, repository)
+
+	return p, &ext, nil
+}
+
+// fetchReleaseMetadata fetches and reads the plugin metadata of a release in the metadata phase.
+func (i *Installer) fetchReleaseMetadata(ctx context.Context, owner, repository string, release *github.RepositoryRelease) ([]byte, error) {
+	tag := release.GetTagName()
+
+	ctx, span := i.startSpan(ctx, "fetch metadata",
+		attrOwner.String(owner),
+		attrRepository.String(repository),
+		attrTag.String(tag),
+	)
+
+	metadataCtx, cancel := i.phaseContext(ctx, PhaseMetadata)
+	defer cancel()
+
+	data, err := i.readMetadata(metadataCtx, owner, repository, release)
+	if err != nil {
+		err = ctxd.WrapError(ctx, i.phaseError(ctx, metadataCtx, PhaseMetadata, err), "could not get plugin metadata", "version", tag)
+	}
+
+	endSpan(span, err)
+
+	return data, err
+}
+
+// readMetadata fetches and reads the plugin metadata of a release, in yaml.
+func (i *Installer) readMetadata(ctx context.Context, owner, repository string, release *github.RepositoryRelease) ([]byte, error) {
+	r, format, err := i.fetchMetadata(ctx, owner, repository, release)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := readAllContext(ctx, limitReadCloser(r, maxMetadataSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) > maxMetadataSize {
+		return nil, fmt.Errorf("%w: %s has more than %d bytes", ErrMetadataTooLarge, format.file, maxMetadataSize)
+	}
+
+	data, err = format.toYAML(data)
+	if err != nil {
+		return nil, ctxd.WrapError(ctx, err, "could not decode plugin metadata", "file", format.file)
+	}
+
+	return data, nil
+}
+
+// fetchMetadata looks for the plugin metadata in the configured sources, in order. A source that does not have the
+// metadata is skipped, the first error from a source that could not be read is returned if no other source has it. A
+// source that has the metadata in more than one format, or that is too large, fails right away.
+func (i *Installer) fetchMetadata(
+	ctx context.Context,
+	owner, repository string,
+	release *github.RepositoryRelease,
+) (io.ReadCloser, metadataFormat, error) {
+	var fetchErr error
+
+	for _, src := range i.metadataSources {
+		start := time.Now()
+
+		r, format, err := i.fetchMetadataFrom(ctx, src, owner, repository, release)
+		if err == nil {
+			i.logger.Debug(ctx, "found plugin metadata", "tag", release.GetTagName(), "metadataSource", src,
+				"file", format.file, "duration", time.Since(start))
+
+			return r, format, nil
+		}
+
+		if errors.Is(err, ErrMetadataNotFound) {
+			i.logger.Debug(ctx, "plugin metadata not found", "tag", release.GetTagName(), "metadataSource", src)
+
+			continue
+		}
+
+		if errors.Is(err, ErrAmbiguousMetadata) || errors.Is(err, ErrMetadataTooLarge) {
+			return nil, metadataFormat{}, err
+		}
+
+		if fetchErr == nil {
+			fetchErr = err
+		}
+	}
+
+	if fetchErr != nil {
+		return nil, metadataFormat{}, fetchErr
+	}
+
+	return nil, metadataFormat{}, ErrMetadataNotFound
+}
+
+func (i *Installer) fetchMetadataFrom(
+	ctx context.Context,
+	src MetadataSource,
+	owner, repository string,
+	release *github.RepositoryRelease,
+) (io.ReadCloser, metadataFormat, error) {
+	switch src {
+	case MetadataFromAssets:
+		return i.fetchMetadataFromAssets(ctx, owner, repository, release)
+
+	case MetadataFromContents:
+		return i.fetchMetadataFromContents(ctx, owner, repository, release)
+
+	case MetadataFromReleaseBody:
+		r, err := fetchMetadataFromReleaseBody(release)
+
+		return r, metadataFormats[0], err
+
+	default:
+		return nil, metadataFormat{}, fmt.Errorf("%w: %s", ErrUnknownMetadataSource, src)
+	}
+}
+
+func (i *Installer) fetchMetadataFromAssets(
+	ctx context.Context,
+	owner, repository string,
+	release *github.RepositoryRelease,
+) (io.ReadCloser, metadataFormat, error) {
+	var (
+		assets  []*github.ReleaseAsset
+		formats []metadataFormat
+	)
+
+	for _, f := range metadataFormats {
+		for _, name := range f.assetNames() {
+			if asset, err := findAsset(release, name); err == nil {
+				assets = append(assets, asset)
+				formats = append(formats, f)
+
+				break
+			}
+		}
+	}
+
+	if len(assets) == 0 {
+		return nil, metadataFormat{}, ErrMetadataNotFound
+	}
+
+	if len(assets) > 1 {
+		names := make([]string, 0, len(assets))
+
+		for _, a := range assets {
+			names = append(names, a.GetName())
+		}
+
+		return nil, metadataFormat{}, ambiguousMetadataError(names)
+	}
+
+	if assets[0].GetSize() > maxMetadataSize {
+		return nil, metadataFormat{}, fmt.Errorf("%w: %s has %d bytes", ErrMetadataTooLarge, assets[0].GetName(), assets[0].GetSize())
+	}
+
+	r, _, err := i.service.DownloadReleaseAsset(ctx, owner, repository, assets[0].GetID(), i.downloadClient)
+
+	i.observeAPICall(EndpointDownloadReleaseAsset, nil)
+
+	if err != nil {
+		return nil, metadataFormat{}, err
+	}
+
+	return r, formats[0], nil
+}
+
+// fetchMetadataFromContents reads the metadata from the repository contents. If the contents could be listed, the
+// metadata file is found in the listing, otherwise all the formats are downloaded to find the one that exists.
+func (i *Installer) fetchMetadataFromContents(
+	ctx context.Context,
+	owner, repository string,
+	release *github.RepositoryRelease,
+) (io.ReadCloser, metadataFormat, error) {
+	opts := &github.RepositoryContentGetOptions{Ref: release.GetTagName()}
+
+	if s := i.contentsService(); s != nil {
+		return i.getMetadataContents(ctx, s, owner, repository, opts)
+	}
+
+	var (
+		readers []io.ReadCloser
+		found   []metadataFormat
+	)
+
+	closeAll := func() {
+		for _, r := range readers {
+			_ = r.Close() // nolint: errcheck
+		}
+	}
+
+	for _, f := range metadataFormats {
+		r, err := i.downloadMetadataContents(ctx, owner, repository, f.file, opts)
+		if errors.Is(err, ErrMetadataNotFound) {
+			continue
+		}
+
+		if err != nil {
+			closeAll()
+
+			return nil, metadataFormat{}, err
+		}
+
+		readers = append(readers, r)
+		found = append(found, f)
+	}
+
+	if len(found) == 0 {
+		return nil, metadataFormat{}, ErrMetadataNotFound
+	}
+
+	if len(found) > 1 {
+		closeAll()
+
+		names := make([]string, 0, len(found))
+
+		for _, f := range found {
+			names = append(names, f.file)
+		}
+
+		return nil, metadataFormat{}, ambiguousMetadataError(names)
+	}
+
+	return readers[0], found[0], nil
+}
+
+func (i *Installer) downloadMetadataContents(
+	ctx context.Context,
+	owner, repository, file string,
+	opts *github.RepositoryContentGetOptions,
+) (io.ReadCloser, error) {
+	r, resp, err := i.service.DownloadContents(ctx, owner, repository, file, opts)
+
+	setResponseStatus(trace.SpanFromContext(ctx), resp)
+	i.observeAPICall(EndpointDownloadContents, resp)
+
+	if err != nil {
+		if isNotFound(err) {
+			return nil, ErrMetadataNotFound
+		}
+
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// getMetadataContents lists the root of the repository to find the metadata file, then gets its content.
+func (i *Installer) getMetadataContents(
+	ctx context.Context,
+	s ContentsService,
+	owner, repository string,
+	opts *github.RepositoryContentGetOptions,
+) (io.ReadCloser, metadataFormat, error) {
+	_, dir, resp, err := s.GetContents(ctx, owner, repository, "", opts)
+
+	setResponseStatus(trace.SpanFromContext(ctx), resp)
+	i.observeAPICall(EndpointGetContents, resp)
+
+	if err != nil {
+		if isNotFound(err) {
+			return nil, metadataFormat{}, ErrMetadataNotFound
+		}
+
+		return nil, metadataFormat{}, err
+	}
+
+	var found []metadataFormat
+
+	for _, f := range metadataFormats {
+		for _, c := range dir {
+			if c.GetName() == f.file && c.GetType() != "dir" {
+				found = append(found, f)
+
+				break
+			}
+		}
+	}
+
+	if len(found) == 0 {
+		return nil, metadataFormat{}, ErrMetadataNotFound
+	}
+
+	if len(found) > 1 {
+		names := make([]string, 0, len(found))
+
+		for _, f := range found {
+			names = append(names, f.file)
+		}
+
+		return nil, metadataFormat{}, ambiguousMetadataError(names)
+	}
+
+	file, _, resp, err := s.GetContents(ctx, owner, repository, found[0].file, opts)
+
+	setResponseStatus(trace.SpanFromContext(ctx), resp)
+	i.observeAPICall(EndpointGetContents, resp)
+
+	if err != nil {
+		if isNotFound(err) {
+			return nil, metadataFormat{}, ErrMetadataNotFound
+		}
+
+		return nil, metadataFormat{}, err
+	}
+
+	if file == nil {
+		return nil, metadataFormat{}, ErrMetadataNotFound
+	}
+
+	content, err := file.GetContent()
+	if err != nil {
+		return nil, metadataFormat{}, err
+	}
+
+	return ioutil.NopCloser(strings.NewReader(content)), found[0], nil
+}
+
+// contentsService returns the configured contents service, or the repository service if it could list the contents.
+func (i *Installer) contentsService() ContentsService {
+	if i.contents != nil {
+		return i.contents
+	}
+
+	if s, ok := i.service.(ContentsService); ok {
+		return s
+	}
+
+	return nil
+}
+
+func fetchMetadataFromReleaseBody(release *github.RepositoryRelease) (io.ReadCloser, error) {
+	m := releaseBodyMetadataPattern.FindStringSubmatch(release.GetBody())
+	if m == nil {
+		return nil, ErrMetadataNotFound
+	}
+
+	return ioutil.NopCloser(strings.NewReader(m[1])), nil
+}
@@ -0,0 +1,303 @@
+package github_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	goGitHub "github.com/google/go-github/v35/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	github "github.com/nhatthm/plugin-registry-github"
+	"github.com/nhatthm/plugin-registry-github/mock/service"
+)
+
+func TestInstaller_FetchMetadata(t *testing.T) {
+	t.Parallel()
+
+	contentsOpt := &goGitHub.RepositoryContentGetOptions{Ref: "v1.4.2"}
+	notFound := errors.New("No file named .plugin.registry.yaml found in .")
+
+	testCases := []struct {
+		scenario            string
+		sources             []github.MetadataSource
+		release             *goGitHub.RepositoryRelease
+		mockService         service.RepositoryServiceMocker
+		mockContentsService service.ContentsServiceMocker
+		expectedMetadata    string
+		expectedError       string
+	}{
+		{
+			scenario: "from assets",
+			release:  newRelease("v1.4.2", withAssets("my-plugin.tar.gz", ".plugin.registry.yaml")),
+			mockService: service.MockRepositoryService(func(s *service.RepositoryService) {
+				s.On("DownloadReleaseAsset", mock.Anything, "owner", "my-plugin", int64(43), http.DefaultClient).
+					Return(strings.NewReader("name: from-assets"), "", nil)
+			}),
+			expectedMetadata: "name: from-assets",
+		},
+		{
+			scenario: "from assets without leading dot",
+			release:  newRelease("v1.4.2", withAssets("plugin.registry.yaml")),
+			mockService: service.MockRepositoryService(func(s *service.RepositoryService) {
+				s.On("DownloadReleaseAsset", mock.Anything, "owner", "my-plugin", int64(42), http.DefaultClient).
+					Return(strings.NewReader("name: from-assets"), "", nil)
+			}),
+			expectedMetadata: "name: from-assets",
+		},
+		{
+			scenario: "from json assets",
+			release:  newRelease("v1.4.2", withAssets("my-plugin.tar.gz", "plugin.registry.json")),
+			mockService: service.MockRepositoryService(func(s *service.RepositoryService) {
+				s.On("DownloadReleaseAsset", mock.Anything, "owner", "my-plugin", int64(43), http.DefaultClient).
+					Return(strings.NewReader(`{"name": "from-assets", "tags": ["json"]}`), "", nil)
+			}),
+			expectedMetadata: "name: from-assets\ntags:\n    - json\n",
+		},
+		{
+			scenario: "from toml assets",
+			release:  newRelease("v1.4.2", withAssets(".plugin.registry.toml")),
+			mockService: service.MockRepositoryService(func(s *service.RepositoryService) {
+				s.On("DownloadReleaseAsset", mock.Anything, "owner", "my-plugin", int64(42), http.DefaultClient).
+					Return(strings.NewReader("name = \"from-assets\"\n\n[artifacts.\"linux/amd64\"]\nfile = \"my-plugin.tar.gz\"\n"), "", nil)
+			}),
+			expectedMetadata: "artifacts:\n    linux/amd64:\n        file: my-plugin.tar.gz\nname: from-assets\n",
+		},
+		{
+			scenario:      "ambiguous assets",
+			release:       newRelease("v1.4.2", withAssets("plugin.registry.yaml", ".plugin.registry.toml")),
+			expectedError: "ambiguous plugin metadata: found plugin.registry.yaml, .plugin.registry.toml",
+		},
+		{
+			scenario: "invalid json",
+			release:  newRelease("v1.4.2", withAssets(".plugin.registry.json")),
+			mockService: service.MockRepositoryService(func(s *service.RepositoryService) {
+				s.On("DownloadReleaseAsset", mock.Anything, "owner", "my-plugin", int64(42), http.DefaultClient).
+					Return(strings.NewReader(`{"name": "from-assets"`), "", nil)
+			}),
+			expectedError: "could not decode plugin metadata: unexpected end of JSON input",
+		},
+		{
+			scenario: "too large asset",
+			release: &goGitHub.RepositoryRelease{
+				TagName: goGitHub.String("v1.4.2"),
+				Assets: []*goGitHub.ReleaseAsset{
+					{ID: goGitHub.Int64(42), Name: goGitHub.String(".plugin.registry.yaml"), Size: goGitHub.Int(github.MaxMetadataSize + 1)},
+				},
+			},
+			expectedError: "plugin metadata too large: .plugin.registry.yaml has 1048577 bytes",
+		},
+		{
+			scenario: "too large contents",
+			release:  newRelease("v1.4.2", withBody("```plugin-registry\nname: from-body\n```")),
+			mockService: service.MockRepositoryService(func(s *service.RepositoryService) {
+				s.On("DownloadContents", mock.Anything, "owner", "my-plugin", ".plugin.registry.yaml", contentsOpt).
+					Return(strings.NewReader("name: "+strings.Repeat("a", github.MaxMetadataSize)), nil, nil)
+
+				s.On("DownloadContents", mock.Anything, "owner", "my-plugin", ".plugin.registry.json", contentsOpt).
+					Return(nil, nil, notFound)
+
+				s.On("DownloadContents", mock.Anything, "owner", "my-plugin", ".plugin.registry.toml", contentsOpt).
+					Return(nil, nil, notFound)
+			}),
+			expectedError: "plugin metadata too large: .plugin.registry.yaml has more than 1048576 bytes",
+		},
+		{
+			scenario: "from contents",
+			release:  newRelease("v1.4.2", withAssets("my-plugin.tar.gz")),
+			mockService: service.MockRepositoryService(func(s *service.RepositoryService) {
+				s.On("DownloadContents", mock.Anything, "owner", "my-plugin", ".plugin.registry.yaml", contentsOpt).
+					Return(strings.NewReader("name: from-contents"), nil, nil)
+
+				s.On("DownloadContents", mock.Anything, "owner", "my-plugin", ".plugin.registry.json", contentsOpt).
+					Return(nil, nil, notFound)
+
+				s.On("DownloadContents", mock.Anything, "owner", "my-plugin", ".plugin.registry.toml", contentsOpt).
+					Return(nil, nil, notFound)
+			}),
+			expectedMetadata: "name: from-contents",
+		},
+		{
+			scenario: "from json contents",
+			release:  newRelease("v1.4.2"),
+			mockService: service.MockRepositoryService(func(s *service.RepositoryService) {
+				s.On("DownloadContents", mock.Anything, "owner", "my-plugin", ".plugin.registry.yaml", contentsOpt).
+					Return(nil, nil, notFound)
+
+				s.On("DownloadContents", mock.Anything, "owner", "my-plugin", ".plugin.registry.json", contentsOpt).
+					Return(strings.NewReader(`{"name": "from-contents"}`), nil, nil)
+
+				s.On("DownloadContents", mock.Anything, "owner", "my-plugin", ".plugin.registry.toml", contentsOpt).
+					Return(nil, nil, notFound)
+			}),
+			expectedMetadata: "name: from-contents\n",
+		},
+		{
+			scenario: "ambiguous contents",
+			release:  newRelease("v1.4.2", withBody("```plugin-registry\nname: from-body\n```")),
+			mockService: service.MockRepositoryService(func(s *service.RepositoryService) {
+				s.On("DownloadContents", mock.Anything, "owner", "my-plugin", ".plugin.registry.yaml", contentsOpt).
+					Return(strings.NewReader("name: from-contents"), nil, nil)
+
+				s.On("DownloadContents", mock.Anything, "owner", "my-plugin", ".plugin.registry.json", contentsOpt).
+					Return(nil, nil, notFound)
+
+				s.On("DownloadContents", mock.Anything, "owner", "my-plugin", ".plugin.registry.toml", contentsOpt).
+					Return(strings.NewReader(`name = "from-contents"`), nil, nil)
+			}),
+			expectedError: "ambiguous plugin metadata: found .plugin.registry.yaml, .plugin.registry.toml",
+		},
+		{
+			scenario: "from listed contents",
+			release:  newRelease("v1.4.2"),
+			mockContentsService: service.MockContentsService(func(s *service.ContentsService) {
+				s.On("GetContents", mock.Anything, "owner", "my-plugin", "", contentsOpt).
+					Return(nil, []*goGitHub.RepositoryContent{
+						{Name: goGitHub.String("README.md"), Type: goGitHub.String("file")},
+						{Name: goGitHub.String(".plugin.registry.toml"), Type: goGitHub.String("file")},
+					}, nil, nil)
+
+				s.On("GetContents", mock.Anything, "owner", "my-plugin", ".plugin.registry.toml", contentsOpt).
+					Return(&goGitHub.RepositoryContent{
+						Encoding: goGitHub.String("base64"),
+						Content:  goGitHub.String("bmFtZSA9ICJmcm9tLWNvbnRlbnRzIgo="),
+					}, nil, nil, nil)
+			}),
+			expectedMetadata: "name: from-contents\n",
+		},
+		{
+			scenario: "ambiguous listed contents",
+			release:  newRelease("v1.4.2"),
+			mockContentsService: service.MockContentsService(func(s *service.ContentsService) {
+				s.On("GetContents", mock.Anything, "owner", "my-plugin", "", contentsOpt).
+					Return(nil, []*goGitHub.RepositoryContent{
+						{Name: goGitHub.String(".plugin.registry.json"), Type: goGitHub.String("file")},
+						{Name: goGitHub.String(".plugin.registry.yaml"), Type: goGitHub.String("file")},
+					}, nil, nil)
+			}),
+			expectedError: "ambiguous plugin metadata: found .plugin.registry.yaml, .plugin.registry.json",
+		},
+		{
+			scenario: "listed contents not found",
+			release:  newRelease("v1.4.2", withBody("```plugin-registry\nname: from-body\n```")),
+			mockContentsService: service.MockContentsService(func(s *service.ContentsService) {
+				s.On("GetContents", mock.Anything, "owner", "my-plugin", "", contentsOpt).
+					Return(nil, []*goGitHub.RepositoryContent{
+						{Name: goGitHub.String(".plugin.registry.json"), Type: goGitHub.String("dir")},
+					}, nil, nil)
+			}),
+			expectedMetadata: "name: from-body",
+		},
+		{
+			scenario: "from release body",
+			release:  newRelease("v1.4.2", withBody("Changes\n\n```plugin-registry\nname: from-body\n```\n")),
+			mockService: service.MockRepositoryService(func(s *service.RepositoryService) {
+				s.On("DownloadContents", mock.Anything, "owner", "my-plugin", ".plugin.registry.yaml", contentsOpt).
+					Return(nil, nil, notFound)
+
+				s.On("DownloadContents", mock.Anything, "owner", "my-plugin", ".plugin.registry.json", contentsOpt).
+					Return(nil, nil, notFound)
+
+				s.On("DownloadContents", mock.Anything, "owner", "my-plugin", ".plugin.registry.toml", contentsOpt).
+					Return(nil, nil, notFound)
+			}),
+			expectedMetadata: "name: from-body",
+		},
+		{
+			scenario:         "from release body with yaml info string",
+			sources:          []github.MetadataSource{github.MetadataFromReleaseBody},
+			release:          newRelease("v1.4.2", withBody("```yaml plugin-registry\r\nname: from-body\r\n```")),
+			expectedMetadata: "name: from-body",
+		},
+		{
+			scenario:         "custom order",
+			sources:          []github.MetadataSource{github.MetadataFromReleaseBody, github.MetadataFromAssets},
+			release:          newRelease("v1.4.2", withBody("```plugin-registry\nname: from-body\n```"), withAssets(".plugin.registry.yaml")),
+			expectedMetadata: "name: from-body",
+		},
+		{
+			scenario: "fallback after download error",
+			release:  newRelease("v1.4.2", withBody("```plugin-registry\nname: from-body\n```"), withAssets(".plugin.registry.yaml")),
+			mockService: service.MockRepositoryService(func(s *service.RepositoryService) {
+				s.On("DownloadReleaseAsset", mock.Anything, "owner", "my-plugin", int64(42), http.DefaultClient).
+					Return(nil, "", errors.New("download error"))
+
+				s.On("DownloadContents", mock.Anything, "owner", "my-plugin", ".plugin.registry.yaml", contentsOpt).
+					Return(nil, nil, notFound)
+
+				s.On("DownloadContents", mock.Anything, "owner", "my-plugin", ".plugin.registry.json", contentsOpt).
+					Return(nil, nil, notFound)
+
+				s.On("DownloadContents", mock.Anything, "owner", "my-plugin", ".plugin.registry.toml", contentsOpt).
+					Return(nil, nil, notFound)
+			}),
+			expectedMetadata: "name: from-body",
+		},
+		{
+			scenario: "download error",
+			release:  newRelease("v1.4.2"),
+			mockService: service.MockRepositoryService(func(s *service.RepositoryService) {
+				s.On("DownloadContents", mock.Anything, "owner", "my-plugin", ".plugin.registry.yaml", contentsOpt).
+					Return(nil, nil, errors.New("download error"))
+			}),
+			expectedError: "download error",
+		},
+		{
+			scenario: "not found",
+			release:  newRelease("v1.4.2", withBody("no metadata")),
+			mockService: service.MockRepositoryService(func(s *service.RepositoryService) {
+				s.On("DownloadContents", mock.Anything, "owner", "my-plugin", ".plugin.registry.yaml", contentsOpt).
+					Return(nil, nil, notFound)
+
+				s.On("DownloadContents", mock.Anything, "owner", "my-plugin", ".plugin.registry.json", contentsOpt).
+					Return(nil, nil, notFound)
+
+				s.On("DownloadContents", mock.Anything, "owner", "my-plugin", ".plugin.registry.toml", contentsOpt).
+					Return(nil, nil, notFound)
+			}),
+			expectedError: "plugin metadata not found",
+		},
+		{
+			scenario:      "unknown source",
+			sources:       []github.MetadataSource{"unknown"},
+			release:       newRelease("v1.4.2"),
+			expectedError: "unknown metadata source: unknown",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			if tc.mockService == nil {
+				tc.mockService = service.NoMockRepositoryService
+			}
+
+			options := []github.Option{github.WithService(tc.mockService(t))}
+
+			if tc.mockContentsService != nil {
+				options = append(options, github.WithContentsService(tc.mockContentsService(t)))
+			}
+
+			if tc.sources != nil {
+				options = append(options, github.WithMetadataSources(tc.sources...))
+			}
+
+			i := github.NewInstaller(options...)
+
+			data, err := github.ReadMetadata(i, context.Background(), "owner", "my-plugin", tc.release)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedMetadata, string(data))
+			} else {
+				assert.Nil(t, data)
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/go-github/v35/github"
+)
+
+// Outcomes of an installation.
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+)
+
+// Endpoints of the github api that are called by the installer.
+const (
+	EndpointGetLatestRelease     = "get_latest_release"
+	EndpointGetReleaseByTag      = "get_release_by_tag"
+	EndpointListReleases         = "list_releases"
+	EndpointDownloadContents     = "download_contents"
+	EndpointGetContents          = "get_contents"
+	EndpointGetCommitSHA1        = "get_commit_sha1"
+	EndpointDownloadReleaseAsset = "download_release_asset"
+	EndpointSearchRepositories   = "search_repositories"
+)
+
+// Metrics collects the metrics of the installer. The methods could be called concurrently.
+type Metrics interface {
+	// ObserveInstall records an installation by its outcome and the type of the error, that is empty on success.
+	ObserveInstall(outcome, errorType string)
+	// ObserveDownload records the bytes and the duration of a downloaded asset.
+	ObserveDownload(bytes int64, duration time.Duration)
+	// ObserveAPICall records a call to the github api with the rate limit of the response, that is zero if it is
+	// unknown.
+	ObserveAPICall(endpoint string, rate github.Rate)
+	// ObserveDependencyReleases records a lookup of the releases of a dependency while resolving the dependencies of a
+	// plugin. The releases are listed once per resolution, so the lookup is a hit when the same dependency is selected
+	// again in the resolution. Nothing is kept across the resolutions, and a plugin without dependencies records none.
+	ObserveDependencyReleases(hit bool)
+}
+
+// NoOpMetrics is a Metrics that records nothing.
+type NoOpMetrics struct{}
+
+// ObserveInstall satisfies Metrics.
+func (NoOpMetrics) ObserveInstall(string, string) {}
+
+// ObserveDownload satisfies Metrics.
+func (NoOpMetrics) ObserveDownload(int64, time.Duration) {}
+
+// ObserveAPICall satisfies Metrics.
+func (NoOpMetrics) ObserveAPICall(string, github.Rate) {}
+
+// ObserveDependencyReleases satisfies Metrics.
+func (NoOpMetrics) ObserveDependencyReleases(bool) {}
+
+// observeInstall records the outcome of an installation.
+func (i *Installer) observeInstall(err error) {
+	if err != nil {
+		i.metrics.ObserveInstall(OutcomeFailure, errorType(err))
+
+		return
+	}
+
+	i.metrics.ObserveInstall(OutcomeSuccess, "")
+}
+
+// observeAPICall records a call to the github api.
+func (i *Installer) observeAPICall(endpoint string, resp *github.Response) {
+	var rate github.Rate
+
+	if resp != nil {
+		rate = resp.Rate
+	}
+
+	i.metrics.ObserveAPICall(endpoint, rate)
+}
+
+// errorType returns a short and stable type of the error, to be used as a label.
+func errorType(err error) string {
+	var (
+		rateLimitErr      *github.RateLimitError
+		abuseRateLimitErr *github.AbuseRateLimitError
+		errResp           *github.ErrorResponse
+	)
+
+	switch {
+	case errors.Is(err, ErrTimeout):
+		return "timeout"
+
+	case errors.Is(err, ErrDownloadStalled):
+		return "download_stalled"
+
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+
+	case errors.Is(err, context.DeadlineExceeded):
+		return "deadline_exceeded"
+
+	case errors.Is(err, ErrNotGithub), errors.Is(err, ErrMissingOwner), errors.Is(err, ErrMissingRepository):
+		return "invalid_source"
+
+	case errors.Is(err, ErrInvalidAssetName):
+		return "invalid_asset_name"
+
+	case errors.Is(err, ErrAssetTooLarge):
+		return "asset_too_large"
+
+	case errors.Is(err, ErrInsufficientSpace):
+		return "insufficient_space"
+
+	case errors.Is(err, ErrMetadataNotFound):
+		return "metadata_not_found"
+
+	case errors.Is(err, ErrMetadataTooLarge):
+		return "metadata_too_large"
+
+	case errors.Is(err, ErrIncompatibleHost):
+		return "incompatible_host"
+
+	case errors.Is(err, ErrDependencyConflict), errors.Is(err, ErrDependencyCycle):
+		return "dependency"
+
+	case errors.Is(err, ErrAttestationNotFound), errors.Is(err, ErrAttestationFailed):
+		return "attestation"
+
+	case errors.Is(err, ErrArtifactNotFound):
+		return "artifact_not_found"
+
+	case errors.As(err, &rateLimitErr), errors.As(err, &abuseRateLimitErr):
+		return "rate_limit"
+
+	case isNotFound(err):
+		return "not_found"
+
+	case errors.As(err, &errResp):
+		if errResp.Response != nil && errResp.Response.StatusCode >= http.StatusInternalServerError {
+			return "api_server_error"
+		}
+
+		return "api_error"
+	}
+
+	return "other"
+}
@@ -0,0 +1,164 @@
+package github
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v35/github"
+)
+
+// InstallCount is the number of installations by outcome and error type.
+type InstallCount struct {
+	Outcome   string
+	ErrorType string
+	Count     int64
+}
+
+// MetricsSnapshot is a copy of the metrics collected by an InMemoryMetrics.
+type MetricsSnapshot struct {
+	Installs []InstallCount
+
+	DownloadedBytes  int64
+	Downloads        int64
+	DownloadDuration time.Duration
+
+	// APICalls is the number of calls to the github api by endpoint.
+	APICalls map[string]int64
+	// RateLimit and RateRemaining are from the latest response that has a rate limit, they are zero if there is none.
+	RateLimit     int
+	RateRemaining int
+	RateReset     time.Time
+
+	// DependencyReleaseHits and DependencyReleaseMisses are the lookups of the releases of the dependencies that reuse
+	// the releases listed in the same resolution, and that list them.
+	DependencyReleaseHits   int64
+	DependencyReleaseMisses int64
+}
+
+// DependencyReleaseHitRatio returns the ratio of the hits to the lookups of the releases of the dependencies, or zero if
+// there is no lookup.
+func (s MetricsSnapshot) DependencyReleaseHitRatio() float64 {
+	lookups := s.DependencyReleaseHits + s.DependencyReleaseMisses
+	if lookups == 0 {
+		return 0
+	}
+
+	return float64(s.DependencyReleaseHits) / float64(lookups)
+}
+
+type installKey struct {
+	outcome   string
+	errorType string
+}
+
+var _ Metrics = (*InMemoryMetrics)(nil)
+
+// InMemoryMetrics collects the metrics of the installer in memory.
+type InMemoryMetrics struct {
+	mu sync.Mutex
+
+	installs map[installKey]int64
+
+	downloadedBytes  int64
+	downloads        int64
+	downloadDuration time.Duration
+
+	apiCalls map[string]int64
+	rate     github.Rate
+
+	dependencyReleaseHits   int64
+	dependencyReleaseMisses int64
+}
+
+// ObserveInstall satisfies Metrics.
+func (m *InMemoryMetrics) ObserveInstall(outcome, errorType string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.installs == nil {
+		m.installs = make(map[installKey]int64)
+	}
+
+	m.installs[installKey{outcome: outcome, errorType: errorType}]++
+}
+
+// ObserveDownload satisfies Metrics.
+func (m *InMemoryMetrics) ObserveDownload(bytes int64, duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.downloadedBytes += bytes
+	m.downloads++
+	m.downloadDuration += duration
+}
+
+// ObserveAPICall satisfies Metrics.
+func (m *InMemoryMetrics) ObserveAPICall(endpoint string, rate github.Rate) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.apiCalls == nil {
+		m.apiCalls = make(map[string]int64)
+	}
+
+	m.apiCalls[endpoint]++
+
+	if rate.Limit > 0 {
+		m.rate = rate
+	}
+}
+
+// ObserveDependencyReleases satisfies Metrics.
+func (m *InMemoryMetrics) ObserveDependencyReleases(hit bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if hit {
+		m.dependencyReleaseHits++
+	} else {
+		m.dependencyReleaseMisses++
+	}
+}
+
+// Snapshot returns a copy of the collected metrics. The installs are sorted by outcome and error type.
+func (m *InMemoryMetrics) Snapshot() MetricsSnapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s := MetricsSnapshot{
+		Installs:                make([]InstallCount, 0, len(m.installs)),
+		DownloadedBytes:         m.downloadedBytes,
+		Downloads:               m.downloads,
+		DownloadDuration:        m.downloadDuration,
+		APICalls:                make(map[string]int64, len(m.apiCalls)),
+		RateLimit:               m.rate.Limit,
+		RateRemaining:           m.rate.Remaining,
+		RateReset:               m.rate.Reset.Time,
+		DependencyReleaseHits:   m.dependencyReleaseHits,
+		DependencyReleaseMisses: m.dependencyReleaseMisses,
+	}
+
+	for k, v := range m.installs {
+		s.Installs = append(s.Installs, InstallCount{Outcome: k.outcome, ErrorType: k.errorType, Count: v})
+	}
+
+	sort.Slice(s.Installs, func(i, j int) bool {
+		if s.Installs[i].Outcome != s.Installs[j].Outcome {
+			return s.Installs[i].Outcome < s.Installs[j].Outcome
+		}
+
+		return s.Installs[i].ErrorType < s.Installs[j].ErrorType
+	})
+
+	for k, v := range m.apiCalls {
+		s.APICalls[k] = v
+	}
+
+	return s
+}
+
+// NewInMemoryMetrics creates a new InMemoryMetrics.
+func NewInMemoryMetrics() *InMemoryMetrics {
+	return &InMemoryMetrics{}
+}
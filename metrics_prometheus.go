@@ -0,0 +1,136 @@
+package github
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// prometheusContentType is the content type of the prometheus text format.
+const prometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// metricsNamespace is the prefix of the prometheus metrics.
+const metricsNamespace = "plugin_registry_github_"
+
+// WritePrometheus writes the metrics in the prometheus text format.
+func WritePrometheus(w io.Writer, s MetricsSnapshot) error {
+	bw := bufio.NewWriter(w)
+	pw := &prometheusWriter{w: bw}
+
+	pw.header("installs_total", "counter", "Number of installations by outcome and error type.")
+
+	for _, c := range s.Installs {
+		pw.sample("installs_total", labels("outcome", c.Outcome, "error_type", c.ErrorType), formatInt(c.Count))
+	}
+
+	pw.header("downloaded_bytes_total", "counter", "Number of downloaded bytes.")
+	pw.sample("downloaded_bytes_total", "", formatInt(s.DownloadedBytes))
+
+	pw.header("download_duration_seconds", "summary", "Duration of the downloads.")
+	pw.sample("download_duration_seconds_sum", "", formatFloat(s.DownloadDuration.Seconds()))
+	pw.sample("download_duration_seconds_count", "", formatInt(s.Downloads))
+
+	pw.header("api_calls_total", "counter", "Number of calls to the github api by endpoint.")
+
+	endpoints := make([]string, 0, len(s.APICalls))
+
+	for e := range s.APICalls {
+		endpoints = append(endpoints, e)
+	}
+
+	sort.Strings(endpoints)
+
+	for _, e := range endpoints {
+		pw.sample("api_calls_total", labels("endpoint", e), formatInt(s.APICalls[e]))
+	}
+
+	if s.RateLimit > 0 {
+		pw.header("rate_limit", "gauge", "Rate limit of the github api.")
+		pw.sample("rate_limit", "", strconv.Itoa(s.RateLimit))
+
+		pw.header("rate_limit_remaining", "gauge", "Remaining rate limit of the github api.")
+		pw.sample("rate_limit_remaining", "", strconv.Itoa(s.RateRemaining))
+	}
+
+	pw.header("dependency_release_hits_total", "counter", "Number of lookups of the releases of a dependency that reuse the releases listed in the same resolution.")
+	pw.sample("dependency_release_hits_total", "", formatInt(s.DependencyReleaseHits))
+
+	pw.header("dependency_release_misses_total", "counter", "Number of lookups of the releases of a dependency that list them.")
+	pw.sample("dependency_release_misses_total", "", formatInt(s.DependencyReleaseMisses))
+
+	pw.header("dependency_release_hit_ratio", "gauge", "Ratio of the hits to the lookups of the releases of the dependencies.")
+	pw.sample("dependency_release_hit_ratio", "", formatFloat(s.DependencyReleaseHitRatio()))
+
+	if pw.err != nil {
+		return pw.err
+	}
+
+	return bw.Flush()
+}
+
+// NewPrometheusHandler creates a http handler that exposes the metrics in the prometheus text format.
+func NewPrometheusHandler(m *InMemoryMetrics) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", prometheusContentType)
+
+		_ = WritePrometheus(w, m.Snapshot()) // nolint: errcheck
+	})
+}
+
+// prometheusWriter writes the metrics and keeps the first error.
+type prometheusWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (w *prometheusWriter) header(name, typ, help string) {
+	w.printf("# HELP %s%s %s\n# TYPE %s%s %s\n", metricsNamespace, name, help, metricsNamespace, name, typ)
+}
+
+func (w *prometheusWriter) sample(name, labels, value string) {
+	w.printf("%s%s%s %s\n", metricsNamespace, name, labels, value)
+}
+
+func (w *prometheusWriter) printf(format string, args ...interface{}) {
+	if w.err != nil {
+		return
+	}
+
+	_, w.err = fmt.Fprintf(w.w, format, args...)
+}
+
+// labels formats the pairs of label names and values.
+func labels(pairs ...string) string {
+	var sb strings.Builder
+
+	sb.WriteByte('{')
+
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+
+		sb.WriteString(pairs[i])
+		sb.WriteString(`="`)
+		sb.WriteString(labelValueReplacer.Replace(pairs[i+1]))
+		sb.WriteByte('"')
+	}
+
+	sb.WriteByte('}')
+
+	return sb.String()
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+func formatInt(v int64) string {
+	return strconv.FormatInt(v, 10)
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
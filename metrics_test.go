@@ -0,0 +1,216 @@
+package github_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	goGitHub "github.com/google/go-github/v35/github"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	github "github.com/nhatthm/plugin-registry-github"
+	"github.com/nhatthm/plugin-registry-github/mock/service"
+)
+
+func TestInstaller_Install_Metrics(t *testing.T) {
+	t.Parallel()
+
+	fixture := "resources/fixtures/gzip/my-plugin.tar.gz"
+
+	stat, err := os.Stat(fixture)
+	require.NoError(t, err)
+
+	resp := &goGitHub.Response{Rate: goGitHub.Rate{Limit: 5000, Remaining: 4990}}
+
+	s := service.MockRepositoryService(mockPlugin(testPlugin{response: resp}))(t)
+
+	metrics := github.NewInMemoryMetrics()
+
+	i := github.NewInstaller(
+		github.WithFs(afero.NewOsFs()),
+		github.WithService(s),
+		github.WithMetadataSources(github.MetadataFromContents),
+		github.WithMetrics(metrics),
+	)
+
+	_, err = i.Install(context.Background(), t.TempDir(), "github.com/owner/my-plugin@v1.4.2")
+	require.NoError(t, err)
+
+	_, err = i.Install(context.Background(), t.TempDir(), "github.com/owner")
+	require.Error(t, err)
+
+	snapshot := metrics.Snapshot()
+
+	expectedInstalls := []github.InstallCount{
+		{Outcome: github.OutcomeFailure, ErrorType: "invalid_source", Count: 1},
+		{Outcome: github.OutcomeSuccess, Count: 1},
+	}
+
+	expectedAPICalls := map[string]int64{
+		github.EndpointGetReleaseByTag:      1,
+		github.EndpointDownloadContents:     3,
+		github.EndpointDownloadReleaseAsset: 1,
+	}
+
+	assert.Equal(t, expectedInstalls, snapshot.Installs)
+	assert.Equal(t, stat.Size(), snapshot.DownloadedBytes)
+	assert.Equal(t, int64(1), snapshot.Downloads)
+	assert.Equal(t, expectedAPICalls, snapshot.APICalls)
+	assert.Equal(t, 5000, snapshot.RateLimit)
+	assert.Equal(t, 4990, snapshot.RateRemaining)
+}
+
+func TestInstaller_ResolveDependencies_MetricsDependencyReleases(t *testing.T) {
+	t.Parallel()
+
+	metrics := github.NewInMemoryMetrics()
+
+	i := github.NewInstaller(
+		github.WithService(newDependencyService(t,
+			testDependencyRelease{repository: "app", tag: "v1.0.0", metadata: dependsOn("lib", "", "z", "")},
+			testDependencyRelease{repository: "lib", tag: "v1.0.0"},
+			testDependencyRelease{repository: "lib", tag: "v2.0.0"},
+			testDependencyRelease{repository: "z", tag: "v1.0.0", metadata: dependsOn("lib", "< 2")},
+		)),
+		github.WithMetadataSources(github.MetadataFromContents),
+		github.WithMetrics(metrics),
+	)
+
+	_, err := i.ResolveDependencies(context.Background(), "github.com/owner/app@v1.0.0")
+	require.NoError(t, err)
+
+	snapshot := metrics.Snapshot()
+
+	// The releases of lib are listed once, and are looked up again when z excludes lib@v2.0.0.
+	assert.Equal(t, int64(1), snapshot.DependencyReleaseHits)
+	assert.Equal(t, int64(2), snapshot.DependencyReleaseMisses)
+	assert.InDelta(t, 1.0/3, snapshot.DependencyReleaseHitRatio(), 1e-9)
+}
+
+func TestInstaller_Install_MetricsErrorType(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario          string
+		err               error
+		expectedErrorType string
+	}{
+		{
+			scenario:          "not found",
+			err:               &goGitHub.ErrorResponse{Response: newHTTPResponse(http.StatusNotFound)},
+			expectedErrorType: "not_found",
+		},
+		{
+			scenario:          "server error",
+			err:               &goGitHub.ErrorResponse{Response: newHTTPResponse(http.StatusBadGateway)},
+			expectedErrorType: "api_server_error",
+		},
+		{
+			scenario:          "rate limit",
+			err:               &goGitHub.RateLimitError{Response: newHTTPResponse(http.StatusForbidden)},
+			expectedErrorType: "rate_limit",
+		},
+		{
+			scenario:          "canceled",
+			err:               context.Canceled,
+			expectedErrorType: "canceled",
+		},
+		{
+			scenario:          "other",
+			err:               errors.New("get error"),
+			expectedErrorType: "other",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			s := service.MockRepositoryService(func(s *service.RepositoryService) {
+				s.On("GetReleaseByTag", mock.Anything, "owner", "my-plugin", "v1.4.2").
+					Return(nil, nil, tc.err)
+			})(t)
+
+			metrics := github.NewInMemoryMetrics()
+
+			i := github.NewInstaller(
+				github.WithFs(afero.NewMemMapFs()),
+				github.WithService(s),
+				github.WithMetrics(metrics),
+			)
+
+			_, err := i.Install(context.Background(), "/tmp", "github.com/owner/my-plugin@v1.4.2")
+			require.Error(t, err)
+
+			expected := []github.InstallCount{
+				{Outcome: github.OutcomeFailure, ErrorType: tc.expectedErrorType, Count: 1},
+			}
+
+			assert.Equal(t, expected, metrics.Snapshot().Installs)
+		})
+	}
+}
+
+func TestNewPrometheusHandler(t *testing.T) {
+	t.Parallel()
+
+	metrics := github.NewInMemoryMetrics()
+
+	metrics.ObserveInstall(github.OutcomeSuccess, "")
+	metrics.ObserveInstall(github.OutcomeSuccess, "")
+	metrics.ObserveInstall(github.OutcomeFailure, "timeout")
+	metrics.ObserveDownload(1024, 1500*time.Millisecond)
+	metrics.ObserveAPICall(github.EndpointGetReleaseByTag, goGitHub.Rate{Limit: 5000, Remaining: 4999})
+	metrics.ObserveAPICall(github.EndpointDownloadReleaseAsset, goGitHub.Rate{})
+	metrics.ObserveDependencyReleases(true)
+	metrics.ObserveDependencyReleases(true)
+	metrics.ObserveDependencyReleases(true)
+	metrics.ObserveDependencyReleases(false)
+
+	rec := httptest.NewRecorder()
+
+	github.NewPrometheusHandler(metrics).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	expected := `# HELP plugin_registry_github_installs_total Number of installations by outcome and error type.
+# TYPE plugin_registry_github_installs_total counter
+plugin_registry_github_installs_total{outcome="failure",error_type="timeout"} 1
+plugin_registry_github_installs_total{outcome="success",error_type=""} 2
+# HELP plugin_registry_github_downloaded_bytes_total Number of downloaded bytes.
+# TYPE plugin_registry_github_downloaded_bytes_total counter
+plugin_registry_github_downloaded_bytes_total 1024
+# HELP plugin_registry_github_download_duration_seconds Duration of the downloads.
+# TYPE plugin_registry_github_download_duration_seconds summary
+plugin_registry_github_download_duration_seconds_sum 1.5
+plugin_registry_github_download_duration_seconds_count 1
+# HELP plugin_registry_github_api_calls_total Number of calls to the github api by endpoint.
+# TYPE plugin_registry_github_api_calls_total counter
+plugin_registry_github_api_calls_total{endpoint="download_release_asset"} 1
+plugin_registry_github_api_calls_total{endpoint="get_release_by_tag"} 1
+# HELP plugin_registry_github_rate_limit Rate limit of the github api.
+# TYPE plugin_registry_github_rate_limit gauge
+plugin_registry_github_rate_limit 5000
+# HELP plugin_registry_github_rate_limit_remaining Remaining rate limit of the github api.
+# TYPE plugin_registry_github_rate_limit_remaining gauge
+plugin_registry_github_rate_limit_remaining 4999
+# HELP plugin_registry_github_dependency_release_hits_total Number of lookups of the releases of a dependency that reuse the releases listed in the same resolution.
+# TYPE plugin_registry_github_dependency_release_hits_total counter
+plugin_registry_github_dependency_release_hits_total 3
+# HELP plugin_registry_github_dependency_release_misses_total Number of lookups of the releases of a dependency that list them.
+# TYPE plugin_registry_github_dependency_release_misses_total counter
+plugin_registry_github_dependency_release_misses_total 1
+# HELP plugin_registry_github_dependency_release_hit_ratio Ratio of the hits to the lookups of the releases of the dependencies.
+# TYPE plugin_registry_github_dependency_release_hit_ratio gauge
+plugin_registry_github_dependency_release_hit_ratio 0.75
+`
+
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Equal(t, expected, rec.Body.String())
+}
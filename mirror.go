@@ -0,0 +1,148 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/bool64/ctxd"
+	"github.com/google/go-github/v35/github"
+	"github.com/nhatthm/plugin-registry/plugin"
+)
+
+// Mirror downloads the releases of the sources, with all their assets and the plugin metadata, into a directory on the
+// file system of the installer. The directory could be served by a DirectoryService to install the same sources
+// without internet access.
+func (i *Installer) Mirror(ctx context.Context, sources []string, dir string) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	for _, source := range sources {
+		if err := i.mirror(ctx, source, dir); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (i *Installer) mirror(ctx context.Context, source, dir string) error {
+	owner, repository, version, err := parseURL(source)
+	if err != nil {
+		return parseError(err, source)
+	}
+
+	ctx = withSource(ctx, source, owner, repository)
+
+	release, err := i.getRelease(ctx, owner, repository, version)
+	if err != nil {
+		return err
+	}
+
+	// The commit of the tag is recorded, so the plugins that are installed from the mirror have it in their provenance.
+	if sha := i.tagCommit(ctx, owner, repository, release.GetTagName()); sha != "" {
+		release.TargetCommitish = &sha
+	}
+
+	releaseDir := releaseDir(dir, owner, repository, release.GetTagName())
+
+	if err := i.fs.RemoveAll(releaseDir); err != nil {
+		return ctxd.WrapError(ctx, err, "could not clean release dir", "dir", releaseDir)
+	}
+
+	if err := i.fs.MkdirAll(releaseDir, 0o755); err != nil {
+		return ctxd.WrapError(ctx, err, "could not create release dir", "dir", releaseDir)
+	}
+
+	metadata, err := i.fetchReleaseMetadata(ctx, owner, repository, release)
+	if err != nil {
+		return err
+	}
+
+	assets := make([]*github.ReleaseAsset, 0, len(release.Assets))
+
+	for _, asset := range release.Assets {
+		mirrored, err := i.mirrorAsset(ctx, owner, repository, asset, releaseDir)
+		if err != nil {
+			return err
+		}
+
+		if mirrored {
+			assets = append(assets, asset)
+		}
+	}
+
+	release.Assets = assets
+
+	// The metadata is written after the assets, so it is not overwritten by an asset of the same name.
+	if err := writeFile(i.fs, filepath.Join(releaseDir, plugin.MetadataFile), bytes.NewReader(metadata)); err != nil {
+		return ctxd.WrapError(ctx, err, "could not write plugin metadata")
+	}
+
+	data, err := json.MarshalIndent(release, "", "  ")
+	if err != nil {
+		return ctxd.WrapError(ctx, err, "could not marshal release")
+	}
+
+	// The release is written last, so an interrupted mirror does not leave a release without its files.
+	if err := writeFile(i.fs, filepath.Join(releaseDir, releaseFile), bytes.NewReader(data)); err != nil {
+		return ctxd.WrapError(ctx, err, "could not write release")
+	}
+
+	i.logger.Info(ctx, "mirrored release", "tag", release.GetTagName(), "assets", len(release.Assets), "dir", releaseDir)
+
+	return nil
+}
+
+// mirrorAsset downloads an asset into the release dir. An asset whose name could not be a file of the release dir is
+// skipped, and is not mirrored.
+func (i *Installer) mirrorAsset(ctx context.Context, owner, repository string, asset *github.ReleaseAsset, dir string) (bool, error) {
+	ctx = context.WithValue(ctx, contextKey("asset"), asset)
+
+	name := asset.GetName()
+
+	err := validateAssetName(name)
+	if err == nil && name == releaseFile {
+		err = fmt.Errorf("%w %q", ErrInvalidAssetName, name)
+	}
+
+	if err != nil {
+		i.logger.Warn(ctx, "skipped asset", "assetID", asset.GetID(), "error", err)
+
+		return false, nil
+	}
+
+	if err := i.checkAsset(asset, dir); err != nil {
+		return false, ctxd.WrapError(ctx, err, "could not mirror artifact")
+	}
+
+	downloadCtx, cancel := i.phaseContext(ctx, PhaseDownload)
+	defer cancel()
+
+	start := time.Now()
+
+	r, _, err := i.service.DownloadReleaseAsset(downloadCtx, owner, repository, asset.GetID(), i.downloadClient)
+
+	i.observeAPICall(EndpointDownloadReleaseAsset, nil)
+
+	if err != nil {
+		return false, ctxd.WrapError(ctx, i.phaseError(ctx, downloadCtx, PhaseDownload, err), "could not download artifact")
+	}
+
+	counter := &byteCounter{}
+
+	err = writeFileWith(i.fs, filepath.Join(dir, name), r, os.O_CREATE|os.O_TRUNC|os.O_RDWR, 0o644,
+		counter.wrap(i.downloadCopier(contextCopier(downloadCtx, i.minThroughput))))
+
+	i.metrics.ObserveDownload(counter.bytes, time.Since(start))
+
+	if err != nil {
+		return false, ctxd.WrapError(ctx, i.phaseError(ctx, downloadCtx, PhaseDownload, err), "could not write artifact")
+	}
+
+	return true, nil
+}
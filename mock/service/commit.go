@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-github/v35/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// CommitServiceMocker is CommitService mocker.
+type CommitServiceMocker func(tb testing.TB) *CommitService
+
+// NoMockCommitService is no mock CommitService.
+var NoMockCommitService = MockCommitService()
+
+// CommitService is a github.CommitService.
+type CommitService struct {
+	mock.Mock
+}
+
+// GetCommitSHA1 satisfies github.CommitService.
+func (s *CommitService) GetCommitSHA1(
+	ctx context.Context,
+	owner, repo, ref, lastSHA string,
+) (sha string, resp *github.Response, err error) {
+	ret := s.Called(ctx, owner, repo, ref, lastSHA)
+
+	sha = ret.String(0)
+	ret2 := ret.Get(1)
+	err = ret.Error(2)
+
+	if ret2 != nil {
+		resp = ret2.(*github.Response) // nolint: errcheck
+	}
+
+	return
+}
+
+// mockCommitService mocks github.CommitService interface.
+func mockCommitService(mocks ...func(s *CommitService)) *CommitService {
+	s := &CommitService{}
+
+	for _, m := range mocks {
+		m(s)
+	}
+
+	return s
+}
+
+// MockCommitService creates CommitService mock with cleanup to ensure all the expectations are met.
+func MockCommitService(mocks ...func(s *CommitService)) CommitServiceMocker {
+	return func(tb testing.TB) *CommitService {
+		tb.Helper()
+
+		s := mockCommitService(mocks...)
+
+		tb.Cleanup(func() {
+			assert.True(tb, s.Mock.AssertExpectations(tb))
+		})
+
+		return s
+	}
+}
@@ -0,0 +1,68 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-github/v35/github"
+	"github.com/nhatthm/plugin-registry-github/mock/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetCommitSHA1(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario         string
+		mockService      service.CommitServiceMocker
+		expectedSHA      string
+		expectedResponse *github.Response
+		expectedError    string
+	}{
+		{
+			scenario: "sha is not empty",
+			mockService: service.MockCommitService(func(s *service.CommitService) {
+				s.On("GetCommitSHA1", context.Background(), "owner", "repo", "v1.0.0", "").
+					Return("6dcb09b5b57875f334f61aebed695e2e4193db5e", nil, nil)
+			}),
+			expectedSHA: "6dcb09b5b57875f334f61aebed695e2e4193db5e",
+		},
+		{
+			scenario: "response is not nil",
+			mockService: service.MockCommitService(func(s *service.CommitService) {
+				s.On("GetCommitSHA1", context.Background(), "owner", "repo", "v1.0.0", "").
+					Return("", &github.Response{FirstPage: 1}, nil)
+			}),
+			expectedResponse: &github.Response{FirstPage: 1},
+		},
+		{
+			scenario: "error is not nil",
+			mockService: service.MockCommitService(func(s *service.CommitService) {
+				s.On("GetCommitSHA1", context.Background(), "owner", "repo", "v1.0.0", "").
+					Return("", nil, errors.New("error"))
+			}),
+			expectedError: "error",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			s := tc.mockService(t)
+
+			sha, resp, err := s.GetCommitSHA1(context.Background(), "owner", "repo", "v1.0.0", "")
+
+			assert.Equal(t, tc.expectedSHA, sha)
+			assert.Equal(t, tc.expectedResponse, resp)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-github/v35/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// ContentsServiceMocker is ContentsService mocker.
+type ContentsServiceMocker func(tb testing.TB) *ContentsService
+
+// NoMockContentsService is no mock ContentsService.
+var NoMockContentsService = MockContentsService()
+
+// ContentsService is a github.ContentsService.
+type ContentsService struct {
+	mock.Mock
+}
+
+// GetContents satisfies github.ContentsService.
+func (s *ContentsService) GetContents(
+	ctx context.Context,
+	owner, repo, path string,
+	opts *github.RepositoryContentGetOptions,
+) (fileContent *github.RepositoryContent, directoryContent []*github.RepositoryContent, resp *github.Response, err error) {
+	ret := s.Called(ctx, owner, repo, path, opts)
+
+	ret0 := ret.Get(0)
+	ret1 := ret.Get(1)
+	ret2 := ret.Get(2)
+	err = ret.Error(3)
+
+	if ret0 != nil {
+		fileContent = ret0.(*github.RepositoryContent) // nolint: errcheck
+	}
+
+	if ret1 != nil {
+		directoryContent = ret1.([]*github.RepositoryContent) // nolint: errcheck
+	}
+
+	if ret2 != nil {
+		resp = ret2.(*github.Response) // nolint: errcheck
+	}
+
+	return
+}
+
+// mockContentsService mocks github.ContentsService interface.
+func mockContentsService(mocks ...func(s *ContentsService)) *ContentsService {
+	s := &ContentsService{}
+
+	for _, m := range mocks {
+		m(s)
+	}
+
+	return s
+}
+
+// MockContentsService creates ContentsService mock with cleanup to ensure all the expectations are met.
+func MockContentsService(mocks ...func(s *ContentsService)) ContentsServiceMocker {
+	return func(tb testing.TB) *ContentsService {
+		tb.Helper()
+
+		s := mockContentsService(mocks...)
+
+		tb.Cleanup(func() {
+			assert.True(tb, s.Mock.AssertExpectations(tb))
+		})
+
+		return s
+	}
+}
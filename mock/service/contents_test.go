@@ -0,0 +1,78 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-github/v35/github"
+	"github.com/nhatthm/plugin-registry-github/mock/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetContents(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario          string
+		mockService       service.ContentsServiceMocker
+		expectedFile      *github.RepositoryContent
+		expectedDirectory []*github.RepositoryContent
+		expectedResponse  *github.Response
+		expectedError     string
+	}{
+		{
+			scenario: "file is not nil",
+			mockService: service.MockContentsService(func(s *service.ContentsService) {
+				s.On("GetContents", context.Background(), "owner", "repo", "file", (*github.RepositoryContentGetOptions)(nil)).
+					Return(&github.RepositoryContent{Name: github.String("file")}, nil, nil, nil)
+			}),
+			expectedFile: &github.RepositoryContent{Name: github.String("file")},
+		},
+		{
+			scenario: "directory is not nil",
+			mockService: service.MockContentsService(func(s *service.ContentsService) {
+				s.On("GetContents", context.Background(), "owner", "repo", "file", (*github.RepositoryContentGetOptions)(nil)).
+					Return(nil, []*github.RepositoryContent{{Name: github.String("file")}}, nil, nil)
+			}),
+			expectedDirectory: []*github.RepositoryContent{{Name: github.String("file")}},
+		},
+		{
+			scenario: "response is not nil",
+			mockService: service.MockContentsService(func(s *service.ContentsService) {
+				s.On("GetContents", context.Background(), "owner", "repo", "file", (*github.RepositoryContentGetOptions)(nil)).
+					Return(nil, nil, &github.Response{FirstPage: 1}, nil)
+			}),
+			expectedResponse: &github.Response{FirstPage: 1},
+		},
+		{
+			scenario: "error is not nil",
+			mockService: service.MockContentsService(func(s *service.ContentsService) {
+				s.On("GetContents", context.Background(), "owner", "repo", "file", (*github.RepositoryContentGetOptions)(nil)).
+					Return(nil, nil, nil, errors.New("error"))
+			}),
+			expectedError: "error",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			s := tc.mockService(t)
+
+			file, dir, resp, err := s.GetContents(context.Background(), "owner", "repo", "file", nil)
+
+			assert.Equal(t, tc.expectedFile, file)
+			assert.Equal(t, tc.expectedDirectory, dir)
+			assert.Equal(t, tc.expectedResponse, resp)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-github/v35/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// ReleaseServiceMocker is ReleaseService mocker.
+type ReleaseServiceMocker func(tb testing.TB) *ReleaseService
+
+// NoMockReleaseService is no mock ReleaseService.
+var NoMockReleaseService = MockReleaseService()
+
+// ReleaseService is a github.ReleaseService.
+type ReleaseService struct {
+	mock.Mock
+}
+
+// ListReleases satisfies github.ReleaseService.
+func (s *ReleaseService) ListReleases(
+	ctx context.Context,
+	owner, repo string,
+	opts *github.ListOptions,
+) (releases []*github.RepositoryRelease, resp *github.Response, err error) {
+	ret := s.Called(ctx, owner, repo, opts)
+
+	ret1 := ret.Get(0)
+	ret2 := ret.Get(1)
+	err = ret.Error(2)
+
+	if ret1 != nil {
+		releases = ret1.([]*github.RepositoryRelease) // nolint: errcheck
+	}
+
+	if ret2 != nil {
+		resp = ret2.(*github.Response) // nolint: errcheck
+	}
+
+	return
+}
+
+// mockReleaseService mocks github.ReleaseService interface.
+func mockReleaseService(mocks ...func(s *ReleaseService)) *ReleaseService {
+	s := &ReleaseService{}
+
+	for _, m := range mocks {
+		m(s)
+	}
+
+	return s
+}
+
+// MockReleaseService creates ReleaseService mock with cleanup to ensure all the expectations are met.
+func MockReleaseService(mocks ...func(s *ReleaseService)) ReleaseServiceMocker {
+	return func(tb testing.TB) *ReleaseService {
+		tb.Helper()
+
+		s := mockReleaseService(mocks...)
+
+		tb.Cleanup(func() {
+			assert.True(tb, s.Mock.AssertExpectations(tb))
+		})
+
+		return s
+	}
+}
@@ -0,0 +1,70 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-github/v35/github"
+	"github.com/nhatthm/plugin-registry-github/mock/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListReleases(t *testing.T) {
+	t.Parallel()
+
+	opt := &github.ListOptions{Page: 2}
+
+	testCases := []struct {
+		scenario         string
+		mockService      service.ReleaseServiceMocker
+		expectedReleases []*github.RepositoryRelease
+		expectedResponse *github.Response
+		expectedError    string
+	}{
+		{
+			scenario: "releases is not nil",
+			mockService: service.MockReleaseService(func(s *service.ReleaseService) {
+				s.On("ListReleases", context.Background(), "owner", "repo", opt).
+					Return([]*github.RepositoryRelease{{}}, nil, nil)
+			}),
+			expectedReleases: []*github.RepositoryRelease{{}},
+		},
+		{
+			scenario: "response is not nil",
+			mockService: service.MockReleaseService(func(s *service.ReleaseService) {
+				s.On("ListReleases", context.Background(), "owner", "repo", opt).
+					Return(nil, &github.Response{FirstPage: 1}, nil)
+			}),
+			expectedResponse: &github.Response{FirstPage: 1},
+		},
+		{
+			scenario: "error is not nil",
+			mockService: service.MockReleaseService(func(s *service.ReleaseService) {
+				s.On("ListReleases", context.Background(), "owner", "repo", opt).
+					Return(nil, nil, errors.New("error"))
+			}),
+			expectedError: "error",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			s := tc.mockService(t)
+
+			releases, resp, err := s.ListReleases(context.Background(), "owner", "repo", opt)
+
+			assert.Equal(t, tc.expectedReleases, releases)
+			assert.Equal(t, tc.expectedResponse, resp)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
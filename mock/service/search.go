@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-github/v35/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// SearchServiceMocker is SearchService mocker.
+type SearchServiceMocker func(tb testing.TB) *SearchService
+
+// NoMockSearchService is no mock SearchService.
+var NoMockSearchService = MockSearchService()
+
+// SearchService is a github.SearchService.
+type SearchService struct {
+	mock.Mock
+}
+
+// Repositories satisfies github.SearchService.
+func (s *SearchService) Repositories(
+	ctx context.Context,
+	query string,
+	opts *github.SearchOptions,
+) (result *github.RepositoriesSearchResult, resp *github.Response, err error) {
+	ret := s.Called(ctx, query, opts)
+
+	ret1 := ret.Get(0)
+	ret2 := ret.Get(1)
+	err = ret.Error(2)
+
+	if ret1 != nil {
+		result = ret1.(*github.RepositoriesSearchResult) // nolint: errcheck
+	}
+
+	if ret2 != nil {
+		resp = ret2.(*github.Response) // nolint: errcheck
+	}
+
+	return
+}
+
+// mockSearchService mocks github.SearchService interface.
+func mockSearchService(mocks ...func(s *SearchService)) *SearchService {
+	s := &SearchService{}
+
+	for _, m := range mocks {
+		m(s)
+	}
+
+	return s
+}
+
+// MockSearchService creates SearchService mock with cleanup to ensure all the expectations are met.
+func MockSearchService(mocks ...func(s *SearchService)) SearchServiceMocker {
+	return func(tb testing.TB) *SearchService {
+		tb.Helper()
+
+		s := mockSearchService(mocks...)
+
+		tb.Cleanup(func() {
+			assert.True(tb, s.Mock.AssertExpectations(tb))
+		})
+
+		return s
+	}
+}
@@ -0,0 +1,70 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-github/v35/github"
+	"github.com/nhatthm/plugin-registry-github/mock/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepositories(t *testing.T) {
+	t.Parallel()
+
+	opt := &github.SearchOptions{}
+
+	testCases := []struct {
+		scenario         string
+		mockService      service.SearchServiceMocker
+		expectedResult   *github.RepositoriesSearchResult
+		expectedResponse *github.Response
+		expectedError    string
+	}{
+		{
+			scenario: "result is not nil",
+			mockService: service.MockSearchService(func(s *service.SearchService) {
+				s.On("Repositories", context.Background(), "topic:plugin", opt).
+					Return(&github.RepositoriesSearchResult{}, nil, nil)
+			}),
+			expectedResult: &github.RepositoriesSearchResult{},
+		},
+		{
+			scenario: "response is not nil",
+			mockService: service.MockSearchService(func(s *service.SearchService) {
+				s.On("Repositories", context.Background(), "topic:plugin", opt).
+					Return(nil, &github.Response{FirstPage: 1}, nil)
+			}),
+			expectedResponse: &github.Response{FirstPage: 1},
+		},
+		{
+			scenario: "error is not nil",
+			mockService: service.MockSearchService(func(s *service.SearchService) {
+				s.On("Repositories", context.Background(), "topic:plugin", opt).
+					Return(nil, nil, errors.New("error"))
+			}),
+			expectedError: "error",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			s := tc.mockService(t)
+
+			result, resp, err := s.Repositories(context.Background(), "topic:plugin", opt)
+
+			assert.Equal(t, tc.expectedResult, result)
+			assert.Equal(t, tc.expectedResponse, resp)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
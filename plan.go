@@ -0,0 +1,124 @@
+package github
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+
+	"github.com/bool64/ctxd"
+	"github.com/google/go-github/v35/github"
+	fsCtx "github.com/nhatthm/plugin-registry/context"
+	"github.com/nhatthm/plugin-registry/installer"
+	"github.com/nhatthm/plugin-registry/plugin"
+	"github.com/spf13/afero"
+)
+
+// Plan describes what the installer would install for a source, without downloading the artifact.
+type Plan struct {
+	Source     string
+	Owner      string
+	Repository string
+	Tag        string
+
+	Plugin   *plugin.Plugin
+	Artifact plugin.Artifact
+	// Target is the platform that the plugin is installed for.
+	Target plugin.ArtifactIdentifier
+
+	AssetID          int64
+	AssetName        string
+	AssetSize        int
+	AssetContentType string
+	DownloadURL      string
+
+	// SmokeTest is the verification of the installed plugin that is declared in the metadata, if any.
+	SmokeTest *SmokeTest
+	// Dependencies are the plugins that the plugin needs, as declared in the metadata.
+	Dependencies []Dependency
+	// Hosts are the versions of the host applications that the plugin is compatible with, as declared in the metadata.
+	Hosts map[string]string
+
+	// Installer is the filesystem installer that would install the downloaded artifact. It is only set by
+	// Installer.Resolve() and is bound to an in-memory file system, it is meant for inspection only.
+	Installer installer.Installer
+
+	release *github.RepositoryRelease
+	asset   *github.ReleaseAsset
+}
+
+// Resolve resolves the release, the metadata and the artifact of the plugin without downloading or writing anything.
+func (i *Installer) Resolve(ctx context.Context, source string) (*Plan, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	ctx, plan, err := i.resolve(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+
+	if plan.Installer, err = findPlanInstaller(ctx, plan); err != nil {
+		return nil, ctxd.WrapError(ctx, err, "could not find installer", "asset", plan.AssetName)
+	}
+
+	return plan, nil
+}
+
+func newPlan(
+	owner, repository string,
+	p *plugin.Plugin,
+	artifact plugin.Artifact,
+	release *github.RepositoryRelease,
+	asset *github.ReleaseAsset,
+) *Plan {
+	return &Plan{
+		Owner:            owner,
+		Repository:       repository,
+		Tag:              release.GetTagName(),
+		Plugin:           p,
+		Artifact:         artifact,
+		AssetID:          asset.GetID(),
+		AssetName:        asset.GetName(),
+		AssetSize:        asset.GetSize(),
+		AssetContentType: asset.GetContentType(),
+		DownloadURL:      asset.GetBrowserDownloadURL(),
+		release:          release,
+		asset:            asset,
+	}
+}
+
+// findPlanInstaller stages an empty artifact and the metadata in memory, the same way Installer.Install() does, to find
+// out which filesystem installer would handle it. The artifact is not downloaded, so its type is guessed from its name.
+func findPlanInstaller(ctx context.Context, plan *Plan) (installer.Installer, error) {
+	fs := afero.NewMemMapFs()
+	dir := filepath.Join(afero.GetTempDir(fs, ""), "plugin-registry-github")
+	file := filepath.Join(dir, plan.AssetName)
+	source := installSource(dir, file)
+
+	if t := guessFileType(plan.AssetName); t != fileTypeUnknown {
+		if err := validateAssetName(plan.Plugin.Name); err != nil {
+			return nil, err
+		}
+
+		file, source = stagedArtifact(dir, t, plan.Plugin.Name)
+	}
+
+	if err := writeFile(fs, file, strings.NewReader("")); err != nil {
+		return nil, err
+	}
+
+	if err := writeMetadata(fs, dir, plan.Plugin); err != nil {
+		return nil, err
+	}
+
+	return installer.Find(fsCtx.WithFs(ctx, fs), source)
+}
+
+// installSource returns the source for the filesystem installers. An archive is installed from the file itself while a
+// binary is installed from its directory.
+func installSource(dir, assetFile string) string {
+	if filepath.Ext(assetFile) == "" {
+		return dir
+	}
+
+	return assetFile
+}
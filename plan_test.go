@@ -0,0 +1,170 @@
+package github_test
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+
+	goGitHub "github.com/google/go-github/v35/github"
+	fs "github.com/nhatthm/plugin-registry-fs"
+	"github.com/nhatthm/plugin-registry/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	github "github.com/nhatthm/plugin-registry-github"
+	"github.com/nhatthm/plugin-registry-github/mock/service"
+)
+
+// mockResolvableArtifact mocks a release having the artifact of the runtime.
+func mockResolvableArtifact(assetName string) service.RepositoryServiceMocker {
+	return service.MockRepositoryService(mockPlugin(testPlugin{assetName: assetName, noDownload: true}))
+}
+
+func TestInstaller_Resolve(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario          string
+		mockService       service.RepositoryServiceMocker
+		source            string
+		expectedAssetName string
+		expectedInstaller interface{}
+		expectedError     string
+	}{
+		{
+			scenario:      "could not parse url",
+			source:        "/tmp/plugin.zip",
+			expectedError: "could not parse url: not a github url",
+		},
+		{
+			scenario: "could not get release",
+			source:   "github.com/owner/my-plugin@v1.4.2",
+			mockService: service.MockRepositoryService(func(s *service.RepositoryService) {
+				s.On("GetReleaseByTag", mock.Anything, "owner", "my-plugin", "v1.4.2").
+					Return(nil, nil, errors.New("get error"))
+			}),
+			expectedError: "could not get release: get error",
+		},
+		{
+			scenario:      "no supported installer",
+			source:        "github.com/owner/my-plugin@v1.4.2",
+			mockService:   mockResolvableArtifact("my-plugin.7z"),
+			expectedError: "could not find installer: no supported installer",
+		},
+		{
+			scenario: "archive",
+			source:   "github.com/owner/my-plugin@v1.4.2",
+			mockService: service.MockRepositoryService(func(s *service.RepositoryService) {
+				s.On("GetReleaseByTag", mock.Anything, "owner", "my-plugin", "v1.4.2").
+					Return(newReleaseWithArtifactf("v1.4.2", "my-plugin-1.4.2-%s-%s.tar.gz", runtime.GOOS, runtime.GOARCH), nil, nil)
+
+				s.On("DownloadContents", mock.Anything, "owner", "my-plugin", ".plugin.registry.yaml",
+					&goGitHub.RepositoryContentGetOptions{Ref: "v1.4.2"}).
+					Return(newMetadataFile("resources/fixtures/.plugin.registry.yaml"), nil, nil)
+
+				mockMetadataNotFound(s, "my-plugin")
+			}),
+			expectedAssetName: "my-plugin-1.4.2-" + runtime.GOOS + "-" + runtime.GOARCH + ".tar.gz",
+			expectedInstaller: &fs.ArchiveInstaller{},
+		},
+		{
+			scenario:          "binary",
+			source:            "github.com/owner/my-plugin@v1.4.2",
+			mockService:       mockResolvableArtifact("my-plugin"),
+			expectedAssetName: "my-plugin",
+			expectedInstaller: &fs.Installer{},
+		},
+		{
+			scenario:          "binary with another name",
+			source:            "github.com/owner/my-plugin@v1.4.2",
+			mockService:       mockResolvableArtifact("my-plugin-1.4.2-linux-amd64"),
+			expectedAssetName: "my-plugin-1.4.2-linux-amd64",
+			expectedInstaller: &fs.Installer{},
+		},
+		{
+			scenario:          "windows binary",
+			source:            "github.com/owner/my-plugin@v1.4.2",
+			mockService:       mockResolvableArtifact("my-plugin.exe"),
+			expectedAssetName: "my-plugin.exe",
+			expectedInstaller: &fs.Installer{},
+		},
+		{
+			scenario:          "gzip binary",
+			source:            "github.com/owner/my-plugin@v1.4.2",
+			mockService:       mockResolvableArtifact("my-plugin-1.4.2.gz"),
+			expectedAssetName: "my-plugin-1.4.2.gz",
+			expectedInstaller: &fs.ArchiveInstaller{},
+		},
+		{
+			scenario:          "xz binary",
+			source:            "github.com/owner/my-plugin@v1.4.2",
+			mockService:       mockResolvableArtifact("my-plugin.xz"),
+			expectedAssetName: "my-plugin.xz",
+			expectedInstaller: &fs.Installer{},
+		},
+		{
+			scenario:          "tar",
+			source:            "github.com/owner/my-plugin@v1.4.2",
+			mockService:       mockResolvableArtifact("my-plugin.tar"),
+			expectedAssetName: "my-plugin.tar",
+			expectedInstaller: &fs.ArchiveInstaller{},
+		},
+		{
+			scenario:          "tar.xz",
+			source:            "github.com/owner/my-plugin@v1.4.2",
+			mockService:       mockResolvableArtifact("my-plugin.tar.xz"),
+			expectedAssetName: "my-plugin.tar.xz",
+			expectedInstaller: &fs.ArchiveInstaller{},
+		},
+		{
+			scenario:          "tar.zst",
+			source:            "github.com/owner/my-plugin@v1.4.2",
+			mockService:       mockResolvableArtifact("my-plugin.tar.zst"),
+			expectedAssetName: "my-plugin.tar.zst",
+			expectedInstaller: &fs.ArchiveInstaller{},
+		},
+		{
+			scenario:          "tar.bz2",
+			source:            "github.com/owner/my-plugin@v1.4.2",
+			mockService:       mockResolvableArtifact("my-plugin.tar.bz2"),
+			expectedAssetName: "my-plugin.tar.bz2",
+			expectedInstaller: &fs.ArchiveInstaller{},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			if tc.mockService == nil {
+				tc.mockService = service.NoMockRepositoryService
+			}
+
+			i := github.NewInstaller(github.WithService(tc.mockService(t)))
+
+			plan, err := i.Resolve(context.Background(), tc.source)
+
+			if tc.expectedError != "" {
+				assert.Nil(t, plan)
+				assert.EqualError(t, err, tc.expectedError)
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.source, plan.Source)
+			assert.Equal(t, "owner", plan.Owner)
+			assert.Equal(t, "my-plugin", plan.Repository)
+			assert.Equal(t, "v1.4.2", plan.Tag)
+			assert.Equal(t, "1.4.2", plan.Plugin.Version)
+			assert.Equal(t, plugin.Artifact{File: tc.expectedAssetName}, plan.Artifact)
+			assert.Equal(t, int64(42), plan.AssetID)
+			assert.Equal(t, tc.expectedAssetName, plan.AssetName)
+			assert.IsType(t, tc.expectedInstaller, plan.Installer)
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"time"
+
+	"github.com/bool64/ctxd"
+	"github.com/nhatthm/plugin-registry/plugin"
+	"github.com/spf13/afero"
+)
+
+// ProvenanceFile is the name of the file in the plugin dir that records where the plugin comes from.
+const ProvenanceFile = ".plugin.provenance.json"
+
+// modulePath is the path of this module, to find the version of the installer.
+const modulePath = "github.com/nhatthm/plugin-registry-github"
+
+// Provenance records where an installed plugin comes from.
+type Provenance struct {
+	Source           string    `json:"source"`
+	Host             string    `json:"host"`
+	Owner            string    `json:"owner"`
+	Repository       string    `json:"repository"`
+	Tag              string    `json:"tag"`
+	Commit           string    `json:"commit,omitempty"`
+	ReleaseID        int64     `json:"release_id"`
+	AssetID          int64     `json:"asset_id"`
+	AssetName        string    `json:"asset_name"`
+	AssetURL         string    `json:"asset_url"`
+	SHA256           string    `json:"sha256"`
+	InstallerVersion string    `json:"installer_version"`
+	InstalledAt      time.Time `json:"installed_at"`
+}
+
+// ReadProvenance reads the provenance of the plugin that is installed in the directory.
+func ReadProvenance(fs afero.Fs, dir string) (*Provenance, error) {
+	data, err := afero.ReadFile(fs, filepath.Join(dir, ProvenanceFile))
+	if err != nil {
+		return nil, err
+	}
+
+	var p Provenance
+
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+// writeProvenance writes the provenance into the dir of the installed plugin.
+func (i *Installer) writeProvenance(ctx context.Context, plan *Plan, d *download, dest string, p *plugin.Plugin) error {
+	if err := validateAssetName(p.Name); err != nil {
+		return ctxd.WrapError(ctx, err, "could not write provenance")
+	}
+
+	prov := Provenance{
+		Source:           plan.Source,
+		Host:             i.host(),
+		Owner:            plan.Owner,
+		Repository:       plan.Repository,
+		Tag:              plan.Tag,
+		Commit:           i.tagCommit(ctx, plan.Owner, plan.Repository, plan.Tag),
+		ReleaseID:        plan.release.GetID(),
+		AssetID:          plan.AssetID,
+		AssetName:        plan.AssetName,
+		AssetURL:         plan.DownloadURL,
+		SHA256:           d.sha256,
+		InstallerVersion: installerVersion(),
+		InstalledAt:      time.Now().UTC(),
+	}
+
+	data, err := json.MarshalIndent(prov, "", "  ")
+	if err != nil {
+		return ctxd.WrapError(ctx, err, "could not marshal provenance")
+	}
+
+	path := filepath.Join(dest, p.Name, ProvenanceFile)
+
+	if err := writeFileWith(i.fs, path, bytes.NewReader(data), os.O_CREATE|os.O_TRUNC|os.O_RDWR, 0o644, io.Copy); err != nil {
+		return ctxd.WrapError(ctx, err, "could not write provenance", "path", path)
+	}
+
+	return nil
+}
+
+// tagCommit returns the sha of the commit of the tag, or an empty string if it could not be resolved.
+func (i *Installer) tagCommit(ctx context.Context, owner, repository, tag string) string {
+	s := i.commitService()
+	if s == nil {
+		return ""
+	}
+
+	apiCtx, cancel := i.phaseContext(ctx, PhaseAPI)
+	defer cancel()
+
+	sha, resp, err := s.GetCommitSHA1(apiCtx, owner, repository, tag, "")
+	i.observeAPICall(EndpointGetCommitSHA1, resp)
+
+	if err != nil {
+		i.logger.Warn(ctx, "could not get commit of tag", "tag", tag, "error", i.phaseError(ctx, apiCtx, PhaseAPI, err))
+
+		return ""
+	}
+
+	return sha
+}
+
+// commitService returns the configured commit service, or the repository service if it could resolve the commits.
+func (i *Installer) commitService() CommitService {
+	if i.commits != nil {
+		return i.commits
+	}
+
+	if s, ok := i.service.(CommitService); ok {
+		return s
+	}
+
+	return nil
+}
+
+// host returns the host of the github api.
+func (i *Installer) host() string {
+	if i.baseURL != nil {
+		return i.baseURL.Host
+	}
+
+	return githubHostname
+}
+
+// installerVersion returns the version of this module in the build.
+func installerVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+
+	if info.Main.Path == modulePath {
+		return info.Main.Version
+	}
+
+	for _, m := range info.Deps {
+		if m.Path != modulePath {
+			continue
+		}
+
+		if m.Replace != nil {
+			return m.Replace.Version
+		}
+
+		return m.Version
+	}
+
+	return ""
+}
+
+// hashCopier returns a copyFunc that also computes the hash of the copied data.
+func hashCopier(h hash.Hash, copyFn copyFunc) copyFunc {
+	return func(dst io.Writer, src io.Reader) (int64, error) {
+		return copyFn(io.MultiWriter(dst, h), src)
+	}
+}
@@ -0,0 +1,126 @@
+package github_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	github "github.com/nhatthm/plugin-registry-github"
+	"github.com/nhatthm/plugin-registry-github/mock/service"
+)
+
+func TestInstaller_Install_Provenance(t *testing.T) {
+	t.Parallel()
+
+	data, err := ioutil.ReadFile("resources/fixtures/gzip/my-plugin.tar.gz")
+	require.NoError(t, err)
+
+	sum := sha256.Sum256(data)
+
+	testCases := []struct {
+		scenario       string
+		commitService  service.CommitServiceMocker
+		expectedCommit string
+	}{
+		{
+			scenario:      "no commit service",
+			commitService: nil,
+		},
+		{
+			scenario: "commit",
+			commitService: service.MockCommitService(func(s *service.CommitService) {
+				s.On("GetCommitSHA1", mock.Anything, "owner", "my-plugin", "v1.4.2", "").
+					Return("6dcb09b5b57875f334f61aebed695e2e4193db5e", nil, nil)
+			}),
+			expectedCommit: "6dcb09b5b57875f334f61aebed695e2e4193db5e",
+		},
+		{
+			scenario: "commit is not found",
+			commitService: service.MockCommitService(func(s *service.CommitService) {
+				s.On("GetCommitSHA1", mock.Anything, "owner", "my-plugin", "v1.4.2", "").
+					Return("", nil, errors.New("get error"))
+			}),
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			osFs := afero.NewOsFs()
+			dest := t.TempDir()
+
+			options := []github.Option{
+				github.WithFs(osFs),
+				github.WithService(service.MockRepositoryService(mockPlugin(testPlugin{}))(t)),
+				github.WithMetadataSources(github.MetadataFromContents),
+			}
+
+			if tc.commitService != nil {
+				options = append(options, github.WithCommitService(tc.commitService(t)))
+			}
+
+			start := time.Now().UTC()
+
+			p, err := github.NewInstaller(options...).
+				Install(context.Background(), dest, "github.com/owner/my-plugin@v1.4.2")
+			require.NoError(t, err)
+
+			provenance, err := github.ReadProvenance(osFs, filepath.Join(dest, p.Name))
+			require.NoError(t, err)
+
+			assert.Equal(t, "github.com/owner/my-plugin@v1.4.2", provenance.Source)
+			assert.Equal(t, "github.com", provenance.Host)
+			assert.Equal(t, "owner", provenance.Owner)
+			assert.Equal(t, "my-plugin", provenance.Repository)
+			assert.Equal(t, "v1.4.2", provenance.Tag)
+			assert.Equal(t, tc.expectedCommit, provenance.Commit)
+			assert.Equal(t, int64(42), provenance.AssetID)
+			assert.Equal(t, "my-plugin.tar.gz", provenance.AssetName)
+			assert.Equal(t, hex.EncodeToString(sum[:]), provenance.SHA256)
+			assert.False(t, provenance.InstalledAt.Before(start.Truncate(time.Second)))
+		})
+	}
+}
+
+func TestReadProvenance_NotFound(t *testing.T) {
+	t.Parallel()
+
+	_, err := github.ReadProvenance(afero.NewMemMapFs(), "/plugins/my-plugin")
+
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+}
+
+func TestDirectoryService_GetCommitSHA1(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+
+	require.NoError(t, afero.WriteFile(fs, "/mirror/owner/my-plugin/releases/v1.4.2/release.json",
+		[]byte(`{"tag_name":"v1.4.2","target_commitish":"6dcb09b5b57875f334f61aebed695e2e4193db5e"}`), 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/mirror/owner/my-plugin/releases/v1.4.1/release.json",
+		[]byte(`{"tag_name":"v1.4.1","target_commitish":"master"}`), 0o644))
+
+	s := github.NewDirectoryService(fs, "/mirror")
+
+	sha, _, err := s.GetCommitSHA1(context.Background(), "owner", "my-plugin", "v1.4.2", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "6dcb09b5b57875f334f61aebed695e2e4193db5e", sha)
+
+	_, _, err = s.GetCommitSHA1(context.Background(), "owner", "my-plugin", "v1.4.1", "")
+
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+}
@@ -0,0 +1,172 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bool64/ctxd"
+	"github.com/google/go-github/v35/github"
+	"github.com/nhatthm/plugin-registry/plugin"
+)
+
+const (
+	// DefaultSearchTopic is the default topic of the plugin repositories.
+	DefaultSearchTopic = "plugin-registry-plugin"
+	// DefaultSearchMaxResults is the default number of the repositories that Installer.Search() returns.
+	DefaultSearchMaxResults = 100
+
+	// maxSearchPageSize is the largest page of the github search api.
+	maxSearchPageSize = 100
+)
+
+// ErrMissingPluginName indicates that the plugin metadata has no name.
+var ErrMissingPluginName = errors.New("missing plugin name")
+
+// SearchResult is a plugin repository found by Installer.Search().
+type SearchResult struct {
+	// Source is the source to install the plugin, for example github.com/owner/repository.
+	Source      string
+	Owner       string
+	Repository  string
+	Description string
+	Stars       int
+	LatestTag   string
+
+	// Plugin is the metadata of the latest release, only set when the metadata validation is enabled.
+	Plugin *plugin.Plugin
+	// MetadataError is the reason why the metadata of the latest release is not valid, only set when the metadata
+	// validation is enabled.
+	MetadataError error
+	// Error is the reason why the latest release could not be found, other than the repository having no release.
+	Error error
+}
+
+// Search searches for the plugin repositories that match the query and have the configured topic. It returns at most
+// the number of results of WithSearchMaxResults(). A repository whose latest release could not be found is still
+// returned, with the error.
+func (i *Installer) Search(ctx context.Context, query string) ([]SearchResult, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	q := strings.TrimSpace(query)
+
+	if i.searchTopic != "" {
+		q = strings.TrimSpace(fmt.Sprintf("%s topic:%s", q, i.searchTopic))
+	}
+
+	ctx = context.WithValue(ctx, contextKey("query"), q)
+
+	repos, err := i.searchRepositories(ctx, q)
+	if err != nil {
+		return nil, ctxd.WrapError(ctx, err, "could not search repositories")
+	}
+
+	i.logger.Debug(ctx, "found plugin repositories", "query", q, "count", len(repos))
+
+	results := make([]SearchResult, 0, len(repos))
+
+	for _, r := range repos {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		results = append(results, i.searchResult(ctx, r))
+	}
+
+	return results, nil
+}
+
+func (i *Installer) searchRepositories(ctx context.Context, query string) ([]*github.Repository, error) {
+	var repos []*github.Repository
+
+	perPage := maxSearchPageSize
+	if i.searchMaxResults < perPage {
+		perPage = i.searchMaxResults
+	}
+
+	opts := &github.SearchOptions{ListOptions: github.ListOptions{PerPage: perPage}}
+
+	for {
+		apiCtx, cancel := i.phaseContext(ctx, PhaseAPI)
+		result, resp, err := i.search.Repositories(apiCtx, query, opts)
+		i.observeAPICall(EndpointSearchRepositories, resp)
+		err = i.phaseError(ctx, apiCtx, PhaseAPI, err)
+
+		cancel()
+
+		if err != nil {
+			return nil, err
+		}
+
+		repos = append(repos, result.Repositories...)
+
+		if len(repos) >= i.searchMaxResults {
+			return repos[:i.searchMaxResults], nil
+		}
+
+		if resp == nil || resp.NextPage == 0 {
+			return repos, nil
+		}
+
+		opts.Page = resp.NextPage
+	}
+}
+
+func (i *Installer) searchResult(ctx context.Context, r *github.Repository) SearchResult {
+	owner := r.GetOwner().GetLogin()
+	repository := r.GetName()
+
+	result := SearchResult{
+		Source:      fmt.Sprintf("%s/%s/%s", githubHostname, owner, repository),
+		Owner:       owner,
+		Repository:  repository,
+		Description: r.GetDescription(),
+		Stars:       r.GetStargazersCount(),
+	}
+
+	apiCtx, cancel := i.phaseContext(ctx, PhaseAPI)
+	release, resp, err := i.service.GetLatestRelease(apiCtx, owner, repository)
+	i.observeAPICall(EndpointGetLatestRelease, resp)
+	err = i.phaseError(ctx, apiCtx, PhaseAPI, err)
+
+	cancel()
+
+	if err != nil {
+		if isNotFound(err) {
+			if i.searchValidation {
+				result.MetadataError = err
+			}
+
+			return result
+		}
+
+		result.Error = ctxd.WrapError(ctx, err, "could not find latest release", "owner", owner, "repository", repository)
+
+		i.logger.Warn(ctx, "could not find latest release", "owner", owner, "repository", repository, "error", err)
+
+		return result
+	}
+
+	result.LatestTag = release.GetTagName()
+
+	if i.searchValidation && result.LatestTag != "" {
+		result.Plugin, result.MetadataError = i.validateMetadata(ctx, owner, repository, release)
+	}
+
+	return result
+}
+
+func (i *Installer) validateMetadata(ctx context.Context, owner, repository string, release *github.RepositoryRelease) (*plugin.Plugin, error) {
+	p, _, err := i.loadReleaseMetadata(ctx, owner, repository, release)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.Name == "" {
+		return nil, ErrMissingPluginName
+	}
+
+	return p, nil
+}
@@ -0,0 +1,264 @@
+package github_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	goGitHub "github.com/google/go-github/v35/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	github "github.com/nhatthm/plugin-registry-github"
+	"github.com/nhatthm/plugin-registry-github/mock/service"
+)
+
+func newRepository(owner, name string, stars int) *goGitHub.Repository {
+	return &goGitHub.Repository{
+		Owner:           &goGitHub.User{Login: &owner},
+		Name:            &name,
+		Description:     stringPtrf("%s plugin", name),
+		StargazersCount: &stars,
+	}
+}
+
+func TestInstaller_Search(t *testing.T) {
+	t.Parallel()
+
+	notFound := &goGitHub.ErrorResponse{Response: &http.Response{StatusCode: http.StatusNotFound}, Message: "Not Found"}
+
+	testCases := []struct {
+		scenario          string
+		options           []github.Option
+		mockSearchService service.SearchServiceMocker
+		mockService       service.RepositoryServiceMocker
+		expectedResult    []github.SearchResult
+		expectedError     string
+	}{
+		{
+			scenario: "could not search",
+			mockSearchService: service.MockSearchService(func(s *service.SearchService) {
+				s.On("Repositories", mock.Anything, "n26 topic:plugin-registry-plugin", mock.Anything).
+					Return(nil, nil, errors.New("search error"))
+			}),
+			expectedError: "could not search repositories: search error",
+		},
+		{
+			scenario: "could not get latest release",
+			mockSearchService: service.MockSearchService(func(s *service.SearchService) {
+				s.On("Repositories", mock.Anything, "n26 topic:plugin-registry-plugin", mock.Anything).
+					Return(&goGitHub.RepositoriesSearchResult{
+						Repositories: []*goGitHub.Repository{newRepository("owner", "n26", 42)},
+					}, nil, nil)
+			}),
+			mockService: service.MockRepositoryService(func(s *service.RepositoryService) {
+				s.On("GetLatestRelease", mock.Anything, "owner", "n26").
+					Return(nil, nil, errors.New("get error"))
+			}),
+			expectedResult: []github.SearchResult{
+				{
+					Source:      "github.com/owner/n26",
+					Owner:       "owner",
+					Repository:  "n26",
+					Description: "n26 plugin",
+					Stars:       42,
+					Error:       errors.New("could not find latest release: get error"),
+				},
+			},
+		},
+		{
+			scenario: "max results",
+			options:  []github.Option{github.WithSearchMaxResults(2)},
+			mockSearchService: service.MockSearchService(func(s *service.SearchService) {
+				s.On("Repositories", mock.Anything, "n26 topic:plugin-registry-plugin",
+					&goGitHub.SearchOptions{ListOptions: goGitHub.ListOptions{PerPage: 2}}).Once().
+					Return(&goGitHub.RepositoriesSearchResult{
+						Repositories: []*goGitHub.Repository{
+							newRepository("owner", "n26", 42),
+							newRepository("owner", "n26-cli", 7),
+						},
+					}, &goGitHub.Response{NextPage: 2}, nil)
+			}),
+			mockService: service.MockRepositoryService(func(s *service.RepositoryService) {
+				s.On("GetLatestRelease", mock.Anything, "owner", "n26").
+					Return(newRelease("v1.4.2"), nil, nil)
+
+				s.On("GetLatestRelease", mock.Anything, "owner", "n26-cli").
+					Return(newRelease("v0.1.0"), nil, nil)
+			}),
+			expectedResult: []github.SearchResult{
+				{
+					Source:      "github.com/owner/n26",
+					Owner:       "owner",
+					Repository:  "n26",
+					Description: "n26 plugin",
+					Stars:       42,
+					LatestTag:   "v1.4.2",
+				},
+				{
+					Source:      "github.com/owner/n26-cli",
+					Owner:       "owner",
+					Repository:  "n26-cli",
+					Description: "n26-cli plugin",
+					Stars:       7,
+					LatestTag:   "v0.1.0",
+				},
+			},
+		},
+		{
+			scenario: "without validation",
+			mockSearchService: service.MockSearchService(func(s *service.SearchService) {
+				s.On("Repositories", mock.Anything, "n26 topic:plugin-registry-plugin",
+					&goGitHub.SearchOptions{ListOptions: goGitHub.ListOptions{PerPage: 100, Page: 0}}).Once().
+					Return(&goGitHub.RepositoriesSearchResult{
+						Repositories: []*goGitHub.Repository{newRepository("owner", "n26", 42)},
+					}, &goGitHub.Response{NextPage: 2}, nil)
+
+				s.On("Repositories", mock.Anything, "n26 topic:plugin-registry-plugin",
+					&goGitHub.SearchOptions{ListOptions: goGitHub.ListOptions{PerPage: 100, Page: 2}}).Once().
+					Return(&goGitHub.RepositoriesSearchResult{
+						Repositories: []*goGitHub.Repository{newRepository("owner", "n26-cli", 7)},
+					}, &goGitHub.Response{}, nil)
+			}),
+			mockService: service.MockRepositoryService(func(s *service.RepositoryService) {
+				s.On("GetLatestRelease", mock.Anything, "owner", "n26").
+					Return(newRelease("v1.4.2"), nil, nil)
+
+				s.On("GetLatestRelease", mock.Anything, "owner", "n26-cli").
+					Return(nil, nil, notFound)
+			}),
+			expectedResult: []github.SearchResult{
+				{
+					Source:      "github.com/owner/n26",
+					Owner:       "owner",
+					Repository:  "n26",
+					Description: "n26 plugin",
+					Stars:       42,
+					LatestTag:   "v1.4.2",
+				},
+				{
+					Source:      "github.com/owner/n26-cli",
+					Owner:       "owner",
+					Repository:  "n26-cli",
+					Description: "n26-cli plugin",
+					Stars:       7,
+				},
+			},
+		},
+		{
+			scenario: "with validation",
+			options: []github.Option{
+				github.WithSearchTopic("my-topic"),
+				github.WithSearchMetadataValidation(),
+			},
+			mockSearchService: service.MockSearchService(func(s *service.SearchService) {
+				s.On("Repositories", mock.Anything, "n26 topic:my-topic", mock.Anything).
+					Return(&goGitHub.RepositoriesSearchResult{
+						Repositories: []*goGitHub.Repository{
+							newRepository("owner", "n26", 42),
+							newRepository("owner", "n26-cli", 7),
+							newRepository("owner", "n26-nameless", 1),
+						},
+					}, nil, nil)
+			}),
+			mockService: service.MockRepositoryService(func(s *service.RepositoryService) {
+				s.On("GetLatestRelease", mock.Anything, "owner", "n26").
+					Return(newRelease("v1.4.2"), nil, nil)
+
+				s.On("DownloadContents", mock.Anything, "owner", "n26", ".plugin.registry.yaml",
+					&goGitHub.RepositoryContentGetOptions{Ref: "v1.4.2"}).
+					Return(newMetadataFileFromString("name: n26"), nil, nil)
+
+				mockMetadataNotFound(s, "n26")
+
+				s.On("GetLatestRelease", mock.Anything, "owner", "n26-cli").
+					Return(nil, nil, notFound)
+
+				s.On("GetLatestRelease", mock.Anything, "owner", "n26-nameless").
+					Return(newRelease("v0.1.0"), nil, nil)
+
+				s.On("DownloadContents", mock.Anything, "owner", "n26-nameless", ".plugin.registry.yaml",
+					&goGitHub.RepositoryContentGetOptions{Ref: "v0.1.0"}).
+					Return(newMetadataFileFromString("description: nameless"), nil, nil)
+
+				mockMetadataNotFound(s, "n26-nameless")
+			}),
+			expectedResult: []github.SearchResult{
+				{
+					Source:      "github.com/owner/n26",
+					Owner:       "owner",
+					Repository:  "n26",
+					Description: "n26 plugin",
+					Stars:       42,
+					LatestTag:   "v1.4.2",
+				},
+				{
+					Source:        "github.com/owner/n26-cli",
+					Owner:         "owner",
+					Repository:    "n26-cli",
+					Description:   "n26-cli plugin",
+					Stars:         7,
+					MetadataError: notFound,
+				},
+				{
+					Source:        "github.com/owner/n26-nameless",
+					Owner:         "owner",
+					Repository:    "n26-nameless",
+					Description:   "n26-nameless plugin",
+					Stars:         1,
+					LatestTag:     "v0.1.0",
+					MetadataError: github.ErrMissingPluginName,
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			if tc.mockService == nil {
+				tc.mockService = service.NoMockRepositoryService
+			}
+
+			options := append([]github.Option{
+				github.WithService(tc.mockService(t)),
+				github.WithSearchService(tc.mockSearchService(t)),
+			}, tc.options...)
+
+			result, err := github.NewInstaller(options...).Search(context.Background(), "n26")
+
+			if tc.expectedError != "" {
+				assert.Nil(t, result)
+				assert.EqualError(t, err, tc.expectedError)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Len(t, result, len(tc.expectedResult))
+
+			for i, expected := range tc.expectedResult {
+				actual := result[i]
+
+				if actual.Plugin != nil {
+					assert.Equal(t, expected.Repository, actual.Plugin.Name)
+					assert.Equal(t, "https://github.com/owner/"+expected.Repository, actual.Plugin.URL)
+
+					actual.Plugin = nil
+				}
+
+				if expected.Error != nil {
+					assert.EqualError(t, actual.Error, expected.Error.Error())
+
+					expected.Error, actual.Error = nil, nil
+				}
+
+				assert.Equal(t, expected, actual)
+			}
+		})
+	}
+}
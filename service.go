@@ -15,3 +15,23 @@ type RepositoryService interface {
 	GetReleaseByTag(ctx context.Context, owner, repo, tag string) (*github.RepositoryRelease, *github.Response, error)
 	DownloadReleaseAsset(ctx context.Context, owner, repo string, id int64, followRedirectsClient *http.Client) (rc io.ReadCloser, redirectURL string, err error)
 }
+
+// ReleaseService is a wrapper around *github.RepositoriesService for listing the releases.
+type ReleaseService interface {
+	ListReleases(ctx context.Context, owner, repo string, opts *github.ListOptions) ([]*github.RepositoryRelease, *github.Response, error)
+}
+
+// CommitService is a wrapper around *github.RepositoryService for resolving the commit of a ref.
+type CommitService interface {
+	GetCommitSHA1(ctx context.Context, owner, repo, ref, lastSHA string) (string, *github.Response, error)
+}
+
+// ContentsService is a wrapper around *github.RepositoriesService for listing and reading the repository contents.
+type ContentsService interface {
+	GetContents(ctx context.Context, owner, repo, path string, opts *github.RepositoryContentGetOptions) (*github.RepositoryContent, []*github.RepositoryContent, *github.Response, error)
+}
+
+// SearchService is a wrapper around *github.SearchService.
+type SearchService interface {
+	Repositories(ctx context.Context, query string, opts *github.SearchOptions) (*github.RepositoriesSearchResult, *github.Response, error)
+}
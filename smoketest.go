@@ -0,0 +1,153 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/bool64/ctxd"
+	"github.com/nhatthm/plugin-registry/plugin"
+)
+
+const (
+	// DefaultSmokeTestTimeout is the default timeout of a smoke test.
+	DefaultSmokeTestTimeout = 10 * time.Second
+
+	// maxSmokeTestOutput is the maximum size of the output of a smoke test that is kept.
+	maxSmokeTestOutput = 64 << 10
+)
+
+// ErrSmokeTestFailed indicates that the installed plugin does not pass its smoke test.
+var ErrSmokeTestFailed = errors.New("smoke test failed")
+
+// SmokeTest is the verification of the installed plugin, it is declared in the metadata:
+//
+//	verify:
+//	  args: ["--version"]
+//	  expectVersion: true
+//
+// The entrypoint is the path of the binary, relative to the plugin dir, it is the name of the plugin by default.
+type SmokeTest struct {
+	Entrypoint    string   `yaml:"entrypoint"`
+	Args          []string `yaml:"args"`
+	ExpectVersion bool     `yaml:"expectVersion"`
+}
+
+// WithSmokeTest runs the smoke test that is declared in the metadata after installing a plugin, with an empty
+// environment. The plugin is removed if the smoke test fails or does not finish in time. If the timeout is not
+// positive, DefaultSmokeTestTimeout is used. The smoke test is skipped when the plugin is installed for another platform.
+func WithSmokeTest(timeout time.Duration) Option {
+	return func(i *Installer) {
+		if timeout <= 0 {
+			timeout = DefaultSmokeTestTimeout
+		}
+
+		i.smokeTestEnabled = true
+		i.smokeTestTimeout = timeout
+	}
+}
+
+// smokeTest runs the smoke test of the installed plugin, if it is enabled and declared in the metadata.
+func (i *Installer) smokeTest(ctx context.Context, plan *Plan, dest string, p *plugin.Plugin) error {
+	if !i.smokeTestEnabled || plan.SmokeTest == nil {
+		return nil
+	}
+
+	if plan.Target != plugin.RuntimeArtifactIdentifier() {
+		i.logger.Info(ctx, "skipped smoke test", "name", p.Name, "target", plan.Target.String())
+
+		return nil
+	}
+
+	ctx, span := i.startSpan(ctx, "smoke test")
+
+	err := i.runSmokeTest(ctx, plan.SmokeTest, dest, p)
+
+	endSpan(span, err)
+
+	if err != nil {
+		return ctxd.WrapError(ctx, err, "could not verify plugin")
+	}
+
+	i.logger.Info(ctx, "passed smoke test", "name", p.Name, "version", p.Version)
+
+	return nil
+}
+
+func (i *Installer) runSmokeTest(ctx context.Context, t *SmokeTest, dest string, p *plugin.Plugin) error {
+	pluginDir := filepath.Join(dest, p.Name)
+
+	entrypoint := t.Entrypoint
+	if entrypoint == "" {
+		entrypoint = p.Name
+	}
+
+	entrypoint = filepath.Join(pluginDir, entrypoint)
+
+	if entrypoint == pluginDir || !isSubPath(pluginDir, entrypoint) {
+		return fmt.Errorf("%w: entrypoint %q is outside of the plugin dir", ErrSmokeTestFailed, t.Entrypoint)
+	}
+
+	path, ok := realPath(i.fs, entrypoint)
+	if !ok {
+		return fmt.Errorf("%w: the plugin is not on the disk", ErrSmokeTestFailed)
+	}
+
+	dir, _ := realPath(i.fs, pluginDir) // nolint: errcheck
+
+	ctx, cancel := context.WithTimeout(ctx, i.smokeTestTimeout)
+	defer cancel()
+
+	out := &limitedBuffer{max: maxSmokeTestOutput}
+
+	cmd := exec.CommandContext(ctx, path, t.Args...) // nolint: gosec
+	cmd.Dir = dir
+	cmd.Env = []string{}
+	cmd.Stdout = out
+	cmd.Stderr = out
+
+	err := cmd.Run()
+
+	switch {
+	case ctx.Err() != nil:
+		return fmt.Errorf("%w: %s did not finish in %s", ErrSmokeTestFailed, entrypoint, i.smokeTestTimeout)
+
+	case err != nil:
+		return fmt.Errorf("%w: %s: %s: %s", ErrSmokeTestFailed, entrypoint, err.Error(), strings.TrimSpace(out.String()))
+	}
+
+	if t.ExpectVersion && !strings.Contains(out.String(), p.Version) {
+		return fmt.Errorf("%w: output of %s does not contain version %q: %s",
+			ErrSmokeTestFailed, entrypoint, p.Version, strings.TrimSpace(out.String()))
+	}
+
+	return nil
+}
+
+// limitedBuffer keeps the first bytes that are written and discards the rest.
+type limitedBuffer struct {
+	buf bytes.Buffer
+	max int
+}
+
+// Write satisfies io.Writer.
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if n := b.max - b.buf.Len(); n > 0 {
+		if n > len(p) {
+			n = len(p)
+		}
+
+		_, _ = b.buf.Write(p[:n]) // nolint: errcheck
+	}
+
+	return len(p), nil
+}
+
+func (b *limitedBuffer) String() string {
+	return b.buf.String()
+}
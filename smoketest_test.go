@@ -0,0 +1,232 @@
+package github_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/nhatthm/aferoassert"
+	"github.com/nhatthm/plugin-registry/plugin"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	github "github.com/nhatthm/plugin-registry-github"
+	"github.com/nhatthm/plugin-registry-github/mock/service"
+)
+
+func TestInstaller_Install_SmokeTest(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("the smoke test scripts need a posix shell")
+	}
+
+	const verify = "verify:\n  args: [\"--version\"]\n  expectVersion: true\n"
+
+	testCases := []struct {
+		scenario      string
+		metadata      string
+		script        string
+		options       []github.Option
+		expectedError string
+	}{
+		{
+			scenario: "passed",
+			metadata: verify,
+			script:   "#!/bin/sh\n[ -z \"$HOME\" ] || exit 4\n[ \"$1\" = \"--version\" ] || exit 5\necho \"my-plugin version 1.4.2\"\n",
+			options:  []github.Option{github.WithSmokeTest(0)},
+		},
+		{
+			scenario: "no smoke test in metadata",
+			script:   "#!/bin/sh\nexit 1\n",
+			options:  []github.Option{github.WithSmokeTest(0)},
+		},
+		{
+			scenario: "smoke test is disabled",
+			metadata: verify,
+			script:   "#!/bin/sh\nexit 1\n",
+		},
+		{
+			scenario:      "exit code",
+			metadata:      verify,
+			script:        "#!/bin/sh\necho oops >&2\nexit 3\n",
+			options:       []github.Option{github.WithSmokeTest(0)},
+			expectedError: "exit status 3: oops",
+		},
+		{
+			scenario:      "version mismatch",
+			metadata:      verify,
+			script:        "#!/bin/sh\necho \"my-plugin version 1.4.1\"\n",
+			options:       []github.Option{github.WithSmokeTest(0)},
+			expectedError: `does not contain version "1.4.2": my-plugin version 1.4.1`,
+		},
+		{
+			scenario:      "timeout",
+			metadata:      verify,
+			script:        "#!/bin/sh\nwhile :; do :; done\n",
+			options:       []github.Option{github.WithSmokeTest(100 * time.Millisecond)},
+			expectedError: "did not finish in 100ms",
+		},
+		{
+			scenario:      "entrypoint outside of the plugin dir",
+			metadata:      "verify:\n  entrypoint: ../my-plugin\n",
+			script:        "#!/bin/sh\n",
+			options:       []github.Option{github.WithSmokeTest(0)},
+			expectedError: `could not verify plugin: smoke test failed: entrypoint "../my-plugin" is outside of the plugin dir`,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			dest := t.TempDir()
+
+			i := github.NewInstaller(append(tc.options,
+				github.WithFs(afero.NewOsFs()),
+				github.WithService(service.MockRepositoryService(mockPlugin(testPlugin{
+					assetName:     "my-plugin",
+					asset:         []byte(tc.script),
+					extraMetadata: tc.metadata,
+				}))(t)),
+				github.WithMetadataSources(github.MetadataFromContents),
+			)...)
+
+			_, err := i.Install(context.Background(), dest, "github.com/owner/my-plugin@v1.4.2")
+
+			_, statErr := os.Stat(filepath.Join(dest, "my-plugin", "my-plugin"))
+
+			if tc.expectedError == "" {
+				require.NoError(t, err)
+				assert.NoError(t, statErr)
+
+				return
+			}
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.expectedError)
+			assert.True(t, errors.Is(err, github.ErrSmokeTestFailed))
+			assert.True(t, os.IsNotExist(statErr))
+		})
+	}
+}
+
+func TestInstaller_Install_SmokeTestNotOnDisk(t *testing.T) {
+	t.Parallel()
+
+	s := service.MockRepositoryService(mockPlugin(testPlugin{extraMetadata: "verify:\n  args: [\"--version\"]\n"}))(t)
+
+	i := github.NewInstaller(
+		github.WithFs(afero.NewMemMapFs()),
+		github.WithService(s),
+		github.WithMetadataSources(github.MetadataFromContents),
+		github.WithSmokeTest(0),
+	)
+
+	_, err := i.Install(context.Background(), "/plugins", "github.com/owner/my-plugin@v1.4.2")
+
+	assert.EqualError(t, err, "could not verify plugin: smoke test failed: the plugin is not on the disk")
+	assert.True(t, errors.Is(err, github.ErrSmokeTestFailed))
+}
+
+func TestInstaller_Install_RestorePreviousVersion(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("the smoke test scripts need a posix shell")
+	}
+
+	const (
+		previous = "#!/bin/sh\necho \"my-plugin version 1.4.1\"\n"
+		verify   = "verify:\n  args: [\"--version\"]\n  expectVersion: true\n"
+	)
+
+	errDenied := errors.New("denied")
+
+	testCases := []struct {
+		scenario        string
+		fs              afero.Fs
+		plugin          testPlugin
+		options         []github.Option
+		expectedContent string
+		expectedError   string
+	}{
+		{
+			scenario: "smoke test passed",
+			fs:       afero.NewOsFs(),
+			plugin: testPlugin{
+				assetName:     "my-plugin",
+				asset:         []byte("#!/bin/sh\necho \"my-plugin version 1.4.2\"\n"),
+				extraMetadata: verify,
+			},
+			options:         []github.Option{github.WithSmokeTest(0)},
+			expectedContent: "#!/bin/sh\necho \"my-plugin version 1.4.2\"\n",
+		},
+		{
+			scenario: "smoke test failed",
+			fs:       afero.NewOsFs(),
+			plugin: testPlugin{
+				assetName:     "my-plugin",
+				asset:         []byte("#!/bin/sh\nexit 1\n"),
+				extraMetadata: verify,
+			},
+			options:         []github.Option{github.WithSmokeTest(0)},
+			expectedContent: previous,
+			expectedError:   "exit status 1",
+		},
+		{
+			scenario: "after install hook denied",
+			fs:       afero.NewMemMapFs(),
+			options: []github.Option{github.WithHooks(github.Hooks{
+				AfterInstall: func(context.Context, *github.Plan, string, *plugin.Plugin) error {
+					return errDenied
+				},
+			})},
+			expectedContent: previous,
+			expectedError:   "aborted after install: denied",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			dest := t.TempDir()
+			dir := filepath.Join(dest, "my-plugin")
+
+			require.NoError(t, tc.fs.MkdirAll(dir, 0o755))
+			require.NoError(t, afero.WriteFile(tc.fs, filepath.Join(dir, "my-plugin"), []byte(previous), 0o755))
+			require.NoError(t, afero.WriteFile(tc.fs, filepath.Join(dir, plugin.MetadataFile), []byte("name: my-plugin\nversion: 1.4.1\n"), 0o644))
+
+			i := github.NewInstaller(append(tc.options,
+				github.WithFs(tc.fs),
+				github.WithService(service.MockRepositoryService(mockPlugin(tc.plugin))(t)),
+				github.WithMetadataSources(github.MetadataFromContents),
+			)...)
+
+			_, err := i.Install(context.Background(), dest, "github.com/owner/my-plugin@v1.4.2")
+
+			if tc.expectedError == "" {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedError)
+			}
+
+			aferoassert.FileContent(t, tc.fs, filepath.Join(dir, "my-plugin"), tc.expectedContent)
+
+			// The backup is removed.
+			entries, err := afero.ReadDir(tc.fs, dest)
+			require.NoError(t, err)
+			require.Len(t, entries, 1)
+			assert.Equal(t, "my-plugin", entries[0].Name())
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// copyFunc copies from src to dst.
+type copyFunc func(dst io.Writer, src io.Reader) (int64, error)
+
+// throughput is the minimum number of bytes per second that must be downloaded in every window.
+type throughput struct {
+	bytesPerSecond int64
+	window         time.Duration
+}
+
+func (t throughput) enabled() bool {
+	return t.bytesPerSecond > 0 && t.window > 0
+}
+
+// contextCopier returns a copyFunc that stops when the context is done or when the throughput stays under the minimum
+// for a whole window. The source is closed when the context is done, so a stalled read does not block forever.
+func contextCopier(ctx context.Context, minThroughput throughput) copyFunc {
+	return func(dst io.Writer, src io.Reader) (int64, error) {
+		return copyContext(ctx, dst, src, minThroughput)
+	}
+}
+
+func copyContext(ctx context.Context, dst io.Writer, src io.Reader, minThroughput throughput) (int64, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg      sync.WaitGroup
+		read    int64
+		stalled int32
+	)
+
+	done := make(chan struct{})
+
+	if c, ok := src.(io.Closer); ok {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			select {
+			case <-ctx.Done():
+				_ = c.Close() // nolint: errcheck
+
+			case <-done:
+			}
+		}()
+	}
+
+	if minThroughput.enabled() {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if watchThroughput(ctx, done, &read, minThroughput) {
+				atomic.StoreInt32(&stalled, 1)
+				cancel()
+			}
+		}()
+	}
+
+	n, err := io.Copy(dst, &contextReader{ctx: ctx, r: src, n: &read})
+
+	close(done)
+	wg.Wait()
+
+	if atomic.LoadInt32(&stalled) == 1 {
+		return n, fmt.Errorf("%w: less than %d bytes/s in %s", ErrDownloadStalled, minThroughput.bytesPerSecond, minThroughput.window)
+	}
+
+	if err != nil && ctx.Err() != nil {
+		return n, ctx.Err()
+	}
+
+	return n, err
+}
+
+// watchThroughput returns true if less than the minimum throughput is read in a window.
+func watchThroughput(ctx context.Context, done <-chan struct{}, read *int64, minThroughput throughput) bool {
+	ticker := time.NewTicker(minThroughput.window)
+	defer ticker.Stop()
+
+	minBytes := float64(minThroughput.bytesPerSecond) * minThroughput.window.Seconds()
+
+	var last int64
+
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+
+		case <-done:
+			return false
+
+		case <-ticker.C:
+			n := atomic.LoadInt64(read)
+
+			if float64(n-last) < minBytes {
+				return true
+			}
+
+			last = n
+		}
+	}
+}
+
+// contextReader is an io.Reader that stops reading when the context is done.
+type contextReader struct {
+	ctx context.Context // nolint: containedctx
+	r   io.Reader
+	n   *int64
+}
+
+// Read satisfies io.Reader.
+func (r *contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	n, err := r.r.Read(p)
+
+	atomic.AddInt64(r.n, int64(n))
+
+	return n, err
+}
+
+// readAllContext reads until EOF or until the context is done, then closes the reader.
+func readAllContext(ctx context.Context, r io.Reader) ([]byte, error) {
+	var buf bytes.Buffer
+
+	_, err := copyContext(ctx, &buf, r, throughput{})
+
+	if c, ok := r.(io.Closer); ok {
+		_ = c.Close() // nolint: errcheck
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// limitedReadCloser reads at most a number of bytes and closes the underlying reader.
+type limitedReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// limitReadCloser returns a reader that stops after n bytes. The reader is still closed, so the read could be
+// interrupted when the context is done.
+func limitReadCloser(r io.ReadCloser, n int64) io.ReadCloser {
+	return limitedReadCloser{Reader: io.LimitReader(r, n), Closer: r}
+}
+
+// byteCounter counts the bytes that are copied by a copyFunc.
+type byteCounter struct {
+	bytes int64
+}
+
+// wrap returns a copyFunc that counts the copied bytes.
+func (c *byteCounter) wrap(copyFn copyFunc) copyFunc {
+	return func(dst io.Writer, src io.Reader) (int64, error) {
+		n, err := copyFn(dst, src)
+
+		c.bytes += n
+
+		return n, err
+	}
+}
@@ -0,0 +1,62 @@
+package github
+
+import (
+	"context"
+	"os"
+
+	"github.com/nhatthm/plugin-registry/plugin"
+)
+
+// defaultArtifactFile is the artifact of a platform that is not declared in the metadata.
+const defaultArtifactFile = "${name}-${version}-${os}-${arch}.tar.gz"
+
+type targetKey struct{}
+
+// WithTarget installs the plugins for another platform instead of the runtime, for example to populate a plugin dir
+// for a docker image. The artifacts are resolved for the target and the smoke test is skipped.
+func WithTarget(os, arch string) Option {
+	return func(i *Installer) {
+		i.target = plugin.NewArtifactIdentifier(os, arch)
+	}
+}
+
+// ContextWithTarget overrides the target platform of the installer for the calls with the context.
+func ContextWithTarget(ctx context.Context, os, arch string) context.Context {
+	return context.WithValue(ctx, targetKey{}, plugin.NewArtifactIdentifier(os, arch))
+}
+
+// targetOf returns the target platform of the call: the one in the context, the one of the installer, or the runtime.
+func (i *Installer) targetOf(ctx context.Context) plugin.ArtifactIdentifier {
+	if t, ok := ctx.Value(targetKey{}).(plugin.ArtifactIdentifier); ok {
+		return t
+	}
+
+	if i.target != (plugin.ArtifactIdentifier{}) {
+		return i.target
+	}
+
+	return plugin.RuntimeArtifactIdentifier()
+}
+
+// targetArtifact returns the artifact of the target platform, like plugin.RuntimeArtifact() but for the target.
+func targetArtifact(p *plugin.Plugin, target plugin.ArtifactIdentifier) plugin.Artifact {
+	if a, ok := p.Artifacts[target]; ok {
+		return a
+	}
+
+	if a, ok := p.Artifacts[plugin.NewArtifactIdentifier(target.OS, "")]; ok {
+		return a
+	}
+
+	return plugin.Artifact{File: defaultArtifactFile}
+}
+
+// executableMode returns the mode of the plugin binary for the target platform. The windows binaries do not need the
+// executable bit.
+func executableMode(target plugin.ArtifactIdentifier) os.FileMode {
+	if target.OS == "windows" {
+		return 0o644
+	}
+
+	return 0o755
+}
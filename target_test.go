@@ -0,0 +1,179 @@
+package github_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/nhatthm/plugin-registry/plugin"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	github "github.com/nhatthm/plugin-registry-github"
+	"github.com/nhatthm/plugin-registry-github/mock/service"
+)
+
+const crossTargetMetadata = `name: my-plugin
+version: 1.4.2
+artifacts:
+  linux/arm64:
+    file: my-plugin-linux-arm64.tar.gz
+  windows:
+    file: my-plugin-${os}-${arch}.zip
+verify:
+  args: ["--version"]
+`
+
+func mockCrossTargetPlugin(assetName string) service.RepositoryServiceMocker {
+	return service.MockRepositoryService(mockPlugin(testPlugin{
+		assetName: assetName,
+		asset:     []byte("#!/bin/sh\nexit 1\n"),
+		metadata:  crossTargetMetadata,
+		maybe:     true,
+	}))
+}
+
+func TestInstaller_Resolve_Target(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario          string
+		options           []github.Option
+		context           func(ctx context.Context) context.Context
+		assetName         string
+		expectedTarget    plugin.ArtifactIdentifier
+		expectedAssetName string
+	}{
+		{
+			scenario:          "runtime",
+			assetName:         "my-plugin-1.4.2-" + runtime.GOOS + "-" + runtime.GOARCH + ".tar.gz",
+			expectedTarget:    plugin.RuntimeArtifactIdentifier(),
+			expectedAssetName: "my-plugin-1.4.2-" + runtime.GOOS + "-" + runtime.GOARCH + ".tar.gz",
+		},
+		{
+			scenario:          "target",
+			options:           []github.Option{github.WithTarget("linux", "arm64")},
+			assetName:         "my-plugin-linux-arm64.tar.gz",
+			expectedTarget:    plugin.NewArtifactIdentifier("linux", "arm64"),
+			expectedAssetName: "my-plugin-linux-arm64.tar.gz",
+		},
+		{
+			scenario:          "target without arch",
+			options:           []github.Option{github.WithTarget("windows", "amd64")},
+			assetName:         "my-plugin-windows-amd64.zip",
+			expectedTarget:    plugin.NewArtifactIdentifier("windows", "amd64"),
+			expectedAssetName: "my-plugin-windows-amd64.zip",
+		},
+		{
+			scenario:          "target is not in metadata",
+			options:           []github.Option{github.WithTarget("freebsd", "riscv64")},
+			assetName:         "my-plugin-1.4.2-freebsd-riscv64.tar.gz",
+			expectedTarget:    plugin.NewArtifactIdentifier("freebsd", "riscv64"),
+			expectedAssetName: "my-plugin-1.4.2-freebsd-riscv64.tar.gz",
+		},
+		{
+			scenario: "context overrides target",
+			options:  []github.Option{github.WithTarget("windows", "amd64")},
+			context: func(ctx context.Context) context.Context {
+				return github.ContextWithTarget(ctx, "linux", "arm64")
+			},
+			assetName:         "my-plugin-linux-arm64.tar.gz",
+			expectedTarget:    plugin.NewArtifactIdentifier("linux", "arm64"),
+			expectedAssetName: "my-plugin-linux-arm64.tar.gz",
+		},
+		{
+			scenario: "fallback of target",
+			options: []github.Option{
+				github.WithTarget("windows", "arm64"),
+			},
+			assetName:         "my-plugin-windows-amd64.zip",
+			expectedTarget:    plugin.NewArtifactIdentifier("windows", "arm64"),
+			expectedAssetName: "my-plugin-windows-amd64.zip",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+
+			if tc.context != nil {
+				ctx = tc.context(ctx)
+			}
+
+			i := github.NewInstaller(append(tc.options,
+				github.WithService(mockCrossTargetPlugin(tc.assetName)(t)),
+				github.WithMetadataSources(github.MetadataFromContents),
+			)...)
+
+			plan, err := i.Resolve(ctx, "github.com/owner/my-plugin@v1.4.2")
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expectedTarget, plan.Target)
+			assert.Equal(t, tc.expectedAssetName, plan.AssetName)
+		})
+	}
+}
+
+func TestInstaller_Install_Target(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("the file modes are not supported on windows")
+	}
+
+	testCases := []struct {
+		scenario     string
+		os           string
+		arch         string
+		assetName    string
+		expectedMode os.FileMode
+	}{
+		{
+			scenario:     "linux",
+			os:           "linux",
+			arch:         "arm64",
+			assetName:    "my-plugin-linux-arm64.tar.gz",
+			expectedMode: 0o755,
+		},
+		{
+			scenario:     "windows",
+			os:           "windows",
+			arch:         "amd64",
+			assetName:    "my-plugin-windows-amd64.zip",
+			expectedMode: 0o644,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			dest := t.TempDir()
+
+			i := github.NewInstaller(
+				github.WithFs(afero.NewOsFs()),
+				github.WithService(mockCrossTargetPlugin(tc.assetName)(t)),
+				github.WithMetadataSources(github.MetadataFromContents),
+				// The smoke test would fail, but it is skipped for another platform.
+				github.WithSmokeTest(0),
+			)
+
+			ctx := github.ContextWithTarget(context.Background(), tc.os, tc.arch)
+
+			_, err := i.Install(ctx, dest, "github.com/owner/my-plugin@v1.4.2")
+			require.NoError(t, err)
+
+			info, err := os.Stat(filepath.Join(dest, "my-plugin", "my-plugin"))
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expectedMode, info.Mode().Perm())
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Phase is a step of the installation.
+type Phase string
+
+const (
+	// PhaseAPI is the calls to the github api to find the releases.
+	PhaseAPI Phase = "api"
+	// PhaseMetadata is the fetching and loading of the plugin metadata.
+	PhaseMetadata Phase = "metadata"
+	// PhaseDownload is the download of the artifact.
+	PhaseDownload Phase = "download"
+	// PhaseInstall is the installation of the downloaded artifact by the filesystem installers.
+	PhaseInstall Phase = "install"
+)
+
+var (
+	// ErrTimeout indicates that a phase of the installation exceeded its deadline.
+	ErrTimeout = errors.New("timeout")
+	// ErrDownloadStalled indicates that the download throughput stayed under the minimum.
+	ErrDownloadStalled = errors.New("download stalled")
+)
+
+// Timeouts are the deadlines of the phases of the installation. A zero duration means no deadline.
+type Timeouts struct {
+	API      time.Duration
+	Metadata time.Duration
+	Download time.Duration
+	Install  time.Duration
+}
+
+func (t Timeouts) of(phase Phase) time.Duration {
+	switch phase {
+	case PhaseAPI:
+		return t.API
+
+	case PhaseMetadata:
+		return t.Metadata
+
+	case PhaseDownload:
+		return t.Download
+
+	case PhaseInstall:
+		return t.Install
+	}
+
+	return 0
+}
+
+// phaseContext returns the context with the deadline of the phase, if any.
+func (i *Installer) phaseContext(ctx context.Context, phase Phase) (context.Context, context.CancelFunc) {
+	if d := i.timeouts.of(phase); d > 0 {
+		return context.WithTimeout(ctx, d)
+	}
+
+	return context.WithCancel(ctx)
+}
+
+// phaseError turns the error into ErrTimeout if the phase exceeded its own deadline.
+func (i *Installer) phaseError(ctx, phaseCtx context.Context, phase Phase, err error) error {
+	if err == nil || ctx.Err() != nil || !errors.Is(phaseCtx.Err(), context.DeadlineExceeded) {
+		return err
+	}
+
+	return fmt.Errorf("%w: %s phase exceeded %s", ErrTimeout, phase, i.timeouts.of(phase))
+}
@@ -0,0 +1,122 @@
+package github_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	github "github.com/nhatthm/plugin-registry-github"
+	"github.com/nhatthm/plugin-registry-github/mock/service"
+)
+
+// newStalledReader returns a reader that blocks until it is closed.
+func newStalledReader() io.ReadCloser {
+	r, _ := io.Pipe()
+
+	return r
+}
+
+func TestInstaller_Install_Timeout(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario      string
+		mockService   service.RepositoryServiceMocker
+		options       []github.Option
+		timeout       time.Duration
+		expectedError string
+		expectedIs    error
+	}{
+		{
+			scenario: "api timeout",
+			mockService: service.MockRepositoryService(func(s *service.RepositoryService) {
+				s.On("GetReleaseByTag", mock.Anything, "owner", "my-plugin", "v1.4.2").
+					Run(func(args mock.Arguments) {
+						<-args.Get(0).(context.Context).Done()
+					}).
+					Return(nil, nil, context.DeadlineExceeded)
+			}),
+			options:       []github.Option{github.WithTimeouts(github.Timeouts{API: 50 * time.Millisecond})},
+			expectedError: "could not get release: timeout: api phase exceeded 50ms",
+			expectedIs:    github.ErrTimeout,
+		},
+		{
+			scenario: "metadata timeout",
+			mockService: service.MockRepositoryService(func(s *service.RepositoryService) {
+				s.On("GetReleaseByTag", mock.Anything, "owner", "my-plugin", "v1.4.2").
+					Return(newReleaseWithArtifact("v1.4.2", "my-plugin.tar.gz"), nil, nil)
+
+				s.On("DownloadContents", mock.Anything, "owner", "my-plugin", ".plugin.registry.yaml", mock.Anything).
+					Return(newStalledReader(), nil, nil)
+
+				mockMetadataNotFound(s, "my-plugin")
+			}),
+			options:       []github.Option{github.WithTimeouts(github.Timeouts{Metadata: 50 * time.Millisecond})},
+			expectedError: "could not get plugin metadata: timeout: metadata phase exceeded 50ms",
+			expectedIs:    github.ErrTimeout,
+		},
+		{
+			scenario: "download timeout",
+			mockService: service.MockRepositoryService(mockPlugin(testPlugin{noDownload: true}), func(s *service.RepositoryService) {
+				s.On("DownloadReleaseAsset", mock.Anything, "owner", "my-plugin", int64(42), mock.Anything).
+					Return(newStalledReader(), "", nil)
+			}),
+			options:       []github.Option{github.WithTimeouts(github.Timeouts{Download: 50 * time.Millisecond})},
+			expectedError: "could not write artifact: timeout: download phase exceeded 50ms",
+			expectedIs:    github.ErrTimeout,
+		},
+		{
+			scenario: "download stalled",
+			mockService: service.MockRepositoryService(mockPlugin(testPlugin{noDownload: true}), func(s *service.RepositoryService) {
+				s.On("DownloadReleaseAsset", mock.Anything, "owner", "my-plugin", int64(42), mock.Anything).
+					Return(newStalledReader(), "", nil)
+			}),
+			options:       []github.Option{github.WithMinThroughput(1024, 20*time.Millisecond)},
+			expectedError: "could not write artifact: download stalled: less than 1024 bytes/s in 20ms",
+			expectedIs:    github.ErrDownloadStalled,
+		},
+		{
+			scenario: "context is done",
+			mockService: service.MockRepositoryService(mockPlugin(testPlugin{noDownload: true}), func(s *service.RepositoryService) {
+				s.On("DownloadReleaseAsset", mock.Anything, "owner", "my-plugin", int64(42), mock.Anything).
+					Return(newStalledReader(), "", nil)
+			}),
+			options:       []github.Option{github.WithTimeouts(github.Timeouts{Download: time.Minute})},
+			timeout:       50 * time.Millisecond,
+			expectedError: "could not write artifact: context deadline exceeded",
+			expectedIs:    context.DeadlineExceeded,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+
+			if tc.timeout > 0 {
+				var cancel context.CancelFunc
+
+				ctx, cancel = context.WithTimeout(ctx, tc.timeout)
+				defer cancel()
+			}
+
+			i := github.NewInstaller(append(tc.options,
+				github.WithFs(afero.NewMemMapFs()),
+				github.WithService(tc.mockService(t)),
+			)...)
+
+			_, err := i.Install(ctx, "/tmp", "github.com/owner/my-plugin@v1.4.2")
+
+			assert.EqualError(t, err, tc.expectedError)
+			assert.True(t, errors.Is(err, tc.expectedIs))
+		})
+	}
+}
@@ -0,0 +1,540 @@
+package github
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+var errNoSigningTime = errors.New("no transparency log entry or timestamp")
+
+// Object identifiers of the RFC 3161 timestamps and of the CMS signed data that carries them.
+var (
+	oidSignedData    = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	oidTSTInfo       = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 1, 4}
+	oidContentType   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 3}
+	oidMessageDigest = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}
+	oidSHA256        = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidSHA384        = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 2}
+	oidSHA512        = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 3}
+)
+
+// tlogEntry is an entry of a transparency log in a Sigstore bundle, the numbers are strings in the json encoding of the
+// protobuf messages.
+type tlogEntry struct {
+	LogIndex json.Number `json:"logIndex"`
+	LogID    struct {
+		KeyID string `json:"keyId"`
+	} `json:"logId"`
+	IntegratedTime   json.Number `json:"integratedTime"`
+	InclusionPromise *struct {
+		SignedEntryTimestamp string `json:"signedEntryTimestamp"`
+	} `json:"inclusionPromise"`
+	CanonicalizedBody string `json:"canonicalizedBody"`
+}
+
+// tlogBody is the part of the body of a dsse or an intoto entry that binds the entry to the attestation, to its
+// signature and to its certificate.
+type tlogBody struct {
+	Kind string `json:"kind"`
+	Spec struct {
+		PayloadHash *tlogHash       `json:"payloadHash"`
+		Signatures  []tlogSignature `json:"signatures"`
+		Content     struct {
+			PayloadHash *tlogHash `json:"payloadHash"`
+			Envelope    struct {
+				Signatures []struct {
+					Sig       string `json:"sig"`
+					PublicKey string `json:"publicKey"`
+				} `json:"signatures"`
+			} `json:"envelope"`
+		} `json:"content"`
+	} `json:"spec"`
+}
+
+// tlogSignature is a signature of an entry, with the base64 encoded PEM of the certificate or the key that verifies it.
+type tlogSignature struct {
+	Signature string `json:"signature"`
+	Verifier  string `json:"verifier"`
+}
+
+type tlogHash struct {
+	Algorithm string `json:"algorithm"`
+	Value     string `json:"value"`
+}
+
+// signingTime returns the time the signature was made, that is the integrated time of an entry of a trusted
+// transparency log for the signature and the certificate, or the time of a timestamp of a trusted timestamp authority.
+func (p *AttestationPolicy) signingTime(a signedAttestation, payload, sig []byte, cert *x509.Certificate) (time.Time, error) {
+	err := errNoSigningTime
+
+	for _, e := range a.tlogEntries {
+		t, tlogErr := p.verifyTlogEntry(e, payload, sig, cert)
+		if tlogErr == nil {
+			return t, nil
+		}
+
+		err = tlogErr
+	}
+
+	for _, ts := range a.timestamps {
+		t, tsErr := p.verifyTimestamp(ts, sig)
+		if tsErr == nil {
+			return t, nil
+		}
+
+		err = tsErr
+	}
+
+	return time.Time{}, err
+}
+
+// verifyTlogEntry verifies the signed entry timestamp of the entry with the keys of the trusted logs, and that the
+// entry is the one of the payload, of the signature and of the certificate.
+func (p *AttestationPolicy) verifyTlogEntry(e tlogEntry, payload, sig []byte, cert *x509.Certificate) (time.Time, error) {
+	if e.InclusionPromise == nil {
+		return time.Time{}, errors.New("transparency log entry has no signed entry timestamp") // nolint: goerr113
+	}
+
+	logID, err := decodeBase64(e.LogID.KeyID)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("could not decode log id: %w", err)
+	}
+
+	logIndex, err := e.LogIndex.Int64()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid log index: %w", err)
+	}
+
+	integratedTime, err := e.IntegratedTime.Int64()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid integrated time: %w", err)
+	}
+
+	set, err := decodeBase64(e.InclusionPromise.SignedEntryTimestamp)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("could not decode signed entry timestamp: %w", err)
+	}
+
+	// The signed entry timestamp is the signature of the canonical json of the entry, the keys are sorted.
+	data, err := json.Marshal(struct {
+		Body           string `json:"body"`
+		IntegratedTime int64  `json:"integratedTime"`
+		LogID          string `json:"logID"`
+		LogIndex       int64  `json:"logIndex"`
+	}{
+		Body:           e.CanonicalizedBody,
+		IntegratedTime: integratedTime,
+		LogID:          hex.EncodeToString(logID),
+		LogIndex:       logIndex,
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if !p.verifyWithLogKeys(data, set) {
+		return time.Time{}, errors.New("transparency log entry is not signed by a trusted log") // nolint: goerr113
+	}
+
+	if err := checkTlogBody(e.CanonicalizedBody, payload, sig, cert); err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Unix(integratedTime, 0), nil
+}
+
+func (p *AttestationPolicy) verifyWithLogKeys(data, sig []byte) bool {
+	for _, k := range p.TransparencyLogKeys {
+		if verifySignature(k, data, sig) == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkTlogBody checks that the entry is the one of the payload, and that it logs the signature with the certificate,
+// otherwise the entry of another signature of the same payload would give its time to the certificate.
+func checkTlogBody(body string, payload, sig []byte, cert *x509.Certificate) error {
+	data, err := decodeBase64(body)
+	if err != nil {
+		return fmt.Errorf("could not decode transparency log entry: %w", err)
+	}
+
+	var b tlogBody
+
+	if err := json.Unmarshal(data, &b); err != nil {
+		return fmt.Errorf("could not parse transparency log entry: %w", err)
+	}
+
+	h := b.Spec.PayloadHash
+	signatures := b.Spec.Signatures
+
+	if b.Kind == "intoto" {
+		h = b.Spec.Content.PayloadHash
+		signatures = make([]tlogSignature, 0, len(b.Spec.Content.Envelope.Signatures))
+
+		for _, s := range b.Spec.Content.Envelope.Signatures {
+			signatures = append(signatures, tlogSignature{Signature: s.Sig, Verifier: s.PublicKey})
+		}
+	}
+
+	sum := sha256.Sum256(payload)
+
+	if h == nil || h.Algorithm != "sha256" || h.Value != hex.EncodeToString(sum[:]) {
+		return fmt.Errorf("transparency log entry of kind %q is not the one of the attestation", b.Kind) // nolint: goerr113
+	}
+
+	for _, s := range signatures {
+		if matchTlogSignature(s.Signature, sig) && matchTlogVerifier(s.Verifier, cert) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("transparency log entry of kind %q is not the one of the signature", b.Kind) // nolint: goerr113
+}
+
+// matchTlogSignature checks whether the signature of an entry is the signature. The intoto entries encode the
+// signature of the envelope in base64 once more.
+func matchTlogSignature(encoded string, sig []byte) bool {
+	data, err := decodeBase64(encoded)
+	if err != nil {
+		return false
+	}
+
+	if bytes.Equal(data, sig) {
+		return true
+	}
+
+	data, err = decodeBase64(string(data))
+
+	return err == nil && bytes.Equal(data, sig)
+}
+
+// matchTlogVerifier checks whether the verifier of an entry is the certificate, or its public key.
+func matchTlogVerifier(encoded string, cert *x509.Certificate) bool {
+	data, err := decodeBase64(encoded)
+	if err != nil {
+		return false
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return false
+	}
+
+	switch block.Type {
+	case "CERTIFICATE":
+		return bytes.Equal(block.Bytes, cert.Raw)
+
+	case "PUBLIC KEY":
+		return bytes.Equal(block.Bytes, cert.RawSubjectPublicKeyInfo)
+	}
+
+	return false
+}
+
+// timeStampResp is a RFC 3161 timestamp response.
+type timeStampResp struct {
+	Status struct {
+		Status int
+	}
+	TimeStampToken asn1.RawValue `asn1:"optional"`
+}
+
+// contentInfo is a CMS content info, see RFC 5652.
+type contentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"explicit,tag:0"`
+}
+
+type signedData struct {
+	Version          int
+	DigestAlgorithms asn1.RawValue
+	EncapContentInfo struct {
+		EContentType asn1.ObjectIdentifier
+		EContent     []byte `asn1:"explicit,optional,tag:0"`
+	}
+	Certificates asn1.RawValue `asn1:"optional,tag:0"`
+	CRLs         asn1.RawValue `asn1:"optional,tag:1"`
+	SignerInfos  []signerInfo  `asn1:"set"`
+}
+
+type signerInfo struct {
+	Version            int
+	SID                asn1.RawValue
+	DigestAlgorithm    pkix.AlgorithmIdentifier
+	SignedAttrs        asn1.RawValue `asn1:"optional,tag:0"`
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	Signature          []byte
+}
+
+type issuerAndSerialNumber struct {
+	Issuer       asn1.RawValue
+	SerialNumber *big.Int
+}
+
+type cmsAttribute struct {
+	Type   asn1.ObjectIdentifier
+	Values asn1.RawValue
+}
+
+// tstInfo is the content of a RFC 3161 timestamp, the optional fields that are not needed are ignored.
+type tstInfo struct {
+	Version        int
+	Policy         asn1.ObjectIdentifier
+	MessageImprint struct {
+		HashAlgorithm pkix.AlgorithmIdentifier
+		HashedMessage []byte
+	}
+	SerialNumber *big.Int
+	GenTime      time.Time `asn1:"generalized"`
+}
+
+// verifyTimestamp verifies a RFC 3161 timestamp of the signature against the trusted timestamp authorities, and returns
+// its time. The timestamp is either a response or the token of a response.
+func (p *AttestationPolicy) verifyTimestamp(der, sig []byte) (time.Time, error) {
+	if p.TimestampAuthorities == nil {
+		return time.Time{}, errors.New("no trusted timestamp authorities") // nolint: goerr113
+	}
+
+	token, err := timestampToken(der)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("could not parse timestamp: %w", err)
+	}
+
+	var sd signedData
+
+	if _, err := asn1.Unmarshal(token.Content.Bytes, &sd); err != nil {
+		return time.Time{}, fmt.Errorf("could not parse timestamp: %w", err)
+	}
+
+	if !sd.EncapContentInfo.EContentType.Equal(oidTSTInfo) || len(sd.SignerInfos) != 1 {
+		return time.Time{}, errors.New("could not parse timestamp: not a timestamp token") // nolint: goerr113
+	}
+
+	var info tstInfo
+
+	if _, err := asn1.Unmarshal(sd.EncapContentInfo.EContent, &info); err != nil {
+		return time.Time{}, fmt.Errorf("could not parse timestamp: %w", err)
+	}
+
+	imprintHash, err := hashAlgorithm(info.MessageImprint.HashAlgorithm)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if !bytes.Equal(hashData(imprintHash, sig), info.MessageImprint.HashedMessage) {
+		return time.Time{}, errors.New("timestamp is not the one of the signature") // nolint: goerr113
+	}
+
+	certs, err := x509.ParseCertificates(sd.Certificates.Bytes)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("could not parse timestamp certificates: %w", err)
+	}
+
+	if err := p.verifyTimestampSigner(sd, certs, info.GenTime); err != nil {
+		return time.Time{}, err
+	}
+
+	return info.GenTime, nil
+}
+
+// timestampToken returns the token of a timestamp response, or the token itself.
+func timestampToken(der []byte) (contentInfo, error) {
+	var token contentInfo
+
+	if _, err := asn1.Unmarshal(der, &token); err != nil {
+		var resp timeStampResp
+
+		if _, err := asn1.Unmarshal(der, &resp); err != nil {
+			return contentInfo{}, err
+		}
+
+		// 0 is granted, 1 is granted with modifications.
+		if resp.Status.Status > 1 {
+			return contentInfo{}, fmt.Errorf("timestamp is not granted, status %d", resp.Status.Status) // nolint: goerr113
+		}
+
+		if _, err := asn1.Unmarshal(resp.TimeStampToken.FullBytes, &token); err != nil {
+			return contentInfo{}, err
+		}
+	}
+
+	if !token.ContentType.Equal(oidSignedData) {
+		return contentInfo{}, errors.New("not a signed data") // nolint: goerr113
+	}
+
+	return token, nil
+}
+
+// verifyTimestampSigner verifies the signature of the timestamp, and the certificate of the timestamp authority at the
+// time of the timestamp.
+func (p *AttestationPolicy) verifyTimestampSigner(sd signedData, certs []*x509.Certificate, genTime time.Time) error {
+	si := sd.SignerInfos[0]
+
+	signer := findSigner(si.SID, certs)
+	if signer == nil {
+		return errors.New("timestamp signer certificate not found") // nolint: goerr113
+	}
+
+	intermediates := x509.NewCertPool()
+
+	for _, c := range certs {
+		intermediates.AddCert(c)
+	}
+
+	if _, err := signer.Verify(x509.VerifyOptions{
+		Roots:         p.TimestampAuthorities,
+		Intermediates: intermediates,
+		CurrentTime:   genTime,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageTimeStamping},
+	}); err != nil {
+		return fmt.Errorf("untrusted timestamp authority: %w", err)
+	}
+
+	h, err := hashAlgorithm(si.DigestAlgorithm)
+	if err != nil {
+		return err
+	}
+
+	content := sd.EncapContentInfo.EContent
+
+	// The signed attributes are signed with their universal SET tag, instead of their implicit tag.
+	if len(si.SignedAttrs.FullBytes) > 0 {
+		if err := checkSignedAttributes(si.SignedAttrs.Bytes, hashData(h, content)); err != nil {
+			return err
+		}
+
+		content = append([]byte{0x31}, si.SignedAttrs.FullBytes[1:]...)
+	}
+
+	if err := verifyDigestSignature(signer.PublicKey, h, hashData(h, content), si.Signature); err != nil {
+		return fmt.Errorf("invalid timestamp signature: %w", err)
+	}
+
+	return nil
+}
+
+// findSigner finds the certificate of the signer by its issuer and serial number, or its subject key id.
+func findSigner(sid asn1.RawValue, certs []*x509.Certificate) *x509.Certificate {
+	var ias issuerAndSerialNumber
+
+	isIssuerAndSerial := sid.Class == asn1.ClassUniversal
+	if isIssuerAndSerial {
+		if _, err := asn1.Unmarshal(sid.FullBytes, &ias); err != nil {
+			return nil
+		}
+	}
+
+	for _, c := range certs {
+		if isIssuerAndSerial {
+			if bytes.Equal(c.RawIssuer, ias.Issuer.FullBytes) && c.SerialNumber.Cmp(ias.SerialNumber) == 0 {
+				return c
+			}
+
+			continue
+		}
+
+		if len(c.SubjectKeyId) > 0 && bytes.Equal(c.SubjectKeyId, sid.Bytes) {
+			return c
+		}
+	}
+
+	return nil
+}
+
+// checkSignedAttributes checks that the signed attributes are the ones of the timestamp content with the digest.
+func checkSignedAttributes(data, digest []byte) error {
+	var contentType, messageDigest bool
+
+	for len(data) > 0 {
+		var (
+			a   cmsAttribute
+			err error
+		)
+
+		if data, err = asn1.Unmarshal(data, &a); err != nil {
+			return fmt.Errorf("could not parse timestamp attributes: %w", err)
+		}
+
+		switch {
+		case a.Type.Equal(oidContentType):
+			var oid asn1.ObjectIdentifier
+
+			_, err = asn1.Unmarshal(a.Values.Bytes, &oid)
+			contentType = err == nil && oid.Equal(oidTSTInfo)
+
+		case a.Type.Equal(oidMessageDigest):
+			var value []byte
+
+			_, err = asn1.Unmarshal(a.Values.Bytes, &value)
+			messageDigest = err == nil && bytes.Equal(value, digest)
+		}
+	}
+
+	if !contentType || !messageDigest {
+		return errors.New("timestamp attributes do not match its content") // nolint: goerr113
+	}
+
+	return nil
+}
+
+func hashAlgorithm(alg pkix.AlgorithmIdentifier) (crypto.Hash, error) {
+	switch {
+	case alg.Algorithm.Equal(oidSHA256):
+		return crypto.SHA256, nil
+
+	case alg.Algorithm.Equal(oidSHA384):
+		return crypto.SHA384, nil
+
+	case alg.Algorithm.Equal(oidSHA512):
+		return crypto.SHA512, nil
+	}
+
+	return 0, fmt.Errorf("unsupported timestamp hash algorithm %s", alg.Algorithm.String()) // nolint: goerr113
+}
+
+func hashData(h crypto.Hash, data []byte) []byte {
+	w := h.New()
+	_, _ = w.Write(data) // nolint: errcheck
+
+	return w.Sum(nil)
+}
+
+// verifyDigestSignature verifies a signature of a digest with a public key.
+func verifyDigestSignature(pub crypto.PublicKey, h crypto.Hash, digest, sig []byte) error {
+	switch pub := pub.(type) {
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(pub, digest, sig) {
+			return errInvalidSignature
+		}
+
+		return nil
+
+	case *rsa.PublicKey:
+		if rsa.VerifyPKCS1v15(pub, h, digest, sig) == nil {
+			return nil
+		}
+
+		if rsa.VerifyPSS(pub, h, digest, sig, nil) == nil {
+			return nil
+		}
+
+		return errInvalidSignature
+	}
+
+	return fmt.Errorf("%w: unsupported key type %T", errInvalidSignature, pub)
+}
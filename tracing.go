@@ -0,0 +1,114 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/google/go-github/v35/github"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the name of the tracer of the installer.
+const tracerName = "github.com/nhatthm/plugin-registry-github"
+
+// Attributes of the spans.
+const (
+	attrSource     = attribute.Key("plugin.source")
+	attrOwner      = attribute.Key("github.owner")
+	attrRepository = attribute.Key("github.repository")
+	attrTag        = attribute.Key("github.tag")
+	attrAssetID    = attribute.Key("github.asset.id")
+	attrAssetName  = attribute.Key("github.asset.name")
+	attrAssetSize  = attribute.Key("github.asset.size")
+)
+
+// startSpan starts a span of the installation.
+func (i *Installer) startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return i.tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// endSpan ends the span and records the error, if any.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
+
+// setResponseStatus sets the http status of the github api response to the span.
+func setResponseStatus(span trace.Span, resp *github.Response) {
+	if resp != nil && resp.Response != nil {
+		span.SetAttributes(semconv.HTTPStatusCodeKey.Int(resp.StatusCode))
+	}
+}
+
+func assetAttributes(asset *github.ReleaseAsset) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attrAssetID.Int64(asset.GetID()),
+		attrAssetName.String(asset.GetName()),
+		attrAssetSize.Int(asset.GetSize()),
+	}
+}
+
+// tracingTransport starts a client span for every request and injects the trace context into the request headers.
+type tracingTransport struct {
+	base       http.RoundTripper
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+// RoundTrip satisfies http.RoundTripper.
+func (t *tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, span := t.tracer.Start(req.Context(), "HTTP "+req.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.HTTPMethodKey.String(req.Method),
+			semconv.HTTPURLKey.String(redactURL(req.URL)),
+		),
+	)
+	defer span.End()
+
+	req = req.Clone(ctx)
+
+	t.propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return nil, err
+	}
+
+	span.SetAttributes(semconv.HTTPStatusCodeKey.Int(resp.StatusCode))
+	span.SetStatus(semconv.SpanStatusFromHTTPStatusCodeAndSpanKind(resp.StatusCode, trace.SpanKindClient))
+
+	return resp, nil
+}
+
+// redactURL removes the credentials and the query, that could have a token for downloading an asset, from the url.
+func redactURL(u *url.URL) string {
+	redacted := *u
+	redacted.User = nil
+	redacted.RawQuery = ""
+	redacted.Fragment = ""
+
+	return redacted.String()
+}
+
+// textMapPropagator returns the configured propagator or the global one.
+func (i *Installer) textMapPropagator() propagation.TextMapPropagator {
+	if i.propagator != nil {
+		return i.propagator
+	}
+
+	return otel.GetTextMapPropagator()
+}
@@ -0,0 +1,146 @@
+package github_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	github "github.com/nhatthm/plugin-registry-github"
+	"github.com/nhatthm/plugin-registry-github/mock/service"
+)
+
+func spanAttributes(s sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value)
+
+	for _, kv := range s.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+
+	return attrs
+}
+
+func TestInstaller_Install_Tracing(t *testing.T) {
+	t.Parallel()
+
+	s := service.MockRepositoryService(mockPlugin(testPlugin{}))(t)
+
+	sr := tracetest.NewSpanRecorder()
+
+	i := github.NewInstaller(
+		github.WithFs(afero.NewOsFs()),
+		github.WithService(s),
+		github.WithMetadataSources(github.MetadataFromContents),
+		github.WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))),
+	)
+
+	_, err := i.Install(context.Background(), t.TempDir(), "github.com/owner/my-plugin@v1.4.2")
+	require.NoError(t, err)
+
+	spans := sr.Ended()
+	names := make([]string, 0, len(spans))
+
+	for _, s := range spans {
+		names = append(names, s.Name())
+	}
+
+	expected := []string{"parse", "resolve release", "fetch metadata", "verify", "download asset", "fs install", "install"}
+
+	assert.Equal(t, expected, names)
+
+	root := spans[len(spans)-1]
+
+	for _, s := range spans[:len(spans)-1] {
+		assert.Equal(t, root.SpanContext().TraceID(), s.SpanContext().TraceID())
+		assert.Equal(t, root.SpanContext().SpanID(), s.Parent().SpanID())
+	}
+
+	download := spanAttributes(spans[4])
+
+	assert.Equal(t, "owner", download["github.owner"].AsString())
+	assert.Equal(t, "my-plugin", download["github.repository"].AsString())
+	assert.Equal(t, "v1.4.2", download["github.tag"].AsString())
+	assert.Equal(t, int64(42), download["github.asset.id"].AsInt64())
+	assert.Equal(t, "my-plugin.tar.gz", download["github.asset.name"].AsString())
+
+	assert.Equal(t, "*fs.ArchiveInstaller", spanAttributes(spans[5])["plugin.installer"].AsString())
+}
+
+func TestInstaller_Install_TracingError(t *testing.T) {
+	t.Parallel()
+
+	sr := tracetest.NewSpanRecorder()
+
+	i := github.NewInstaller(
+		github.WithService(service.NoMockRepositoryService(t)),
+		github.WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))),
+	)
+
+	_, err := i.Install(context.Background(), t.TempDir(), "/tmp/plugin.zip")
+	require.Error(t, err)
+
+	spans := sr.Ended()
+
+	require.Len(t, spans, 2)
+
+	for _, s := range spans {
+		assert.Equal(t, "could not parse url: not a github url", s.Status().Description)
+	}
+}
+
+func TestInstaller_Resolve_TracingTransport(t *testing.T) {
+	t.Parallel()
+
+	var traceParent atomic.Value
+
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceParent.Store(r.Header.Get("traceparent"))
+
+		w.WriteHeader(http.StatusNotFound)
+
+		_, _ = w.Write([]byte(`{"message":"Not Found"}`)) // nolint: errcheck
+	}))
+
+	t.Cleanup(svr.Close)
+
+	baseURL, err := url.Parse(svr.URL + "/")
+	require.NoError(t, err)
+
+	sr := tracetest.NewSpanRecorder()
+
+	i := github.NewInstaller(
+		github.WithBaseURL(baseURL),
+		github.WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))),
+		github.WithPropagator(propagation.TraceContext{}),
+	)
+
+	_, err = i.Resolve(context.Background(), "github.com/owner/my-plugin@v1.4.2?token=secret")
+	require.Error(t, err)
+
+	spans := sr.Ended()
+
+	require.Len(t, spans, 3)
+	assert.Equal(t, "parse", spans[0].Name())
+	assert.Equal(t, "HTTP GET", spans[1].Name())
+	assert.Equal(t, "resolve release", spans[2].Name())
+
+	httpSpan := spanAttributes(spans[1])
+
+	assert.Equal(t, int64(http.StatusNotFound), httpSpan["http.status_code"].AsInt64())
+	assert.Equal(t, svr.URL+"/repos/owner/my-plugin/releases/tags/v1.4.2", httpSpan["http.url"].AsString())
+	assert.Equal(t, int64(http.StatusNotFound), spanAttributes(spans[2])["http.status_code"].AsInt64())
+
+	expectedTraceParent := "00-" + spans[1].SpanContext().TraceID().String() + "-" + spans[1].SpanContext().SpanID().String() + "-01"
+
+	assert.Equal(t, expectedTraceParent, traceParent.Load())
+}
@@ -0,0 +1,109 @@
+package github
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// URLRewriter rewrites the url of a request to github, for example to go through an artifact proxy. It returns false if
+// the url is not rewritten.
+type URLRewriter func(u *url.URL) (*url.URL, bool)
+
+// RewriteURLPrefix rewrites the urls that start with the prefix.
+func RewriteURLPrefix(from, to string) URLRewriter {
+	return func(u *url.URL) (*url.URL, bool) {
+		s := u.String()
+
+		if !strings.HasPrefix(s, from) {
+			return u, false
+		}
+
+		rewritten, err := url.Parse(to + strings.TrimPrefix(s, from))
+		if err != nil {
+			return u, false
+		}
+
+		return rewritten, true
+	}
+}
+
+// rewriteTransport rewrites the url of the requests with the first matched rewriter, then sets the headers configured
+// for the host of the rewritten url.
+type rewriteTransport struct {
+	base      http.RoundTripper
+	rewriters []URLRewriter
+	headers   map[string]http.Header
+}
+
+// RoundTrip satisfies http.RoundTripper.
+func (t *rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+
+	for _, rewrite := range t.rewriters {
+		if u, ok := rewrite(req.URL); ok {
+			req.URL = u
+			req.Host = ""
+
+			break
+		}
+	}
+
+	for k, v := range t.headers[req.URL.Host] {
+		req.Header[k] = v
+	}
+
+	return t.base.RoundTrip(req)
+}
+
+// newHTTPClients creates the client for the github api and the client for downloading the assets. They must not be the
+// same instance because github.RepositoriesService.DownloadReleaseAsset() changes the redirect policy of the api client
+// while using the download client.
+func (i *Installer) newHTTPClients() (apiClient *http.Client, downloadClient *http.Client) {
+	if i.httpClient == nil && len(i.urlRewriters) == 0 && len(i.hostHeaders) == 0 && i.tracerProvider == nil {
+		return nil, http.DefaultClient
+	}
+
+	base := i.httpClient
+	if base == nil {
+		base = &http.Client{}
+	}
+
+	transport := base.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	// The tracing transport is under the rewrite transport to trace the requests that are actually sent.
+	if i.tracerProvider != nil {
+		transport = &tracingTransport{
+			base:       transport,
+			tracer:     i.tracer,
+			propagator: i.textMapPropagator(),
+		}
+	}
+
+	if len(i.urlRewriters) > 0 || len(i.hostHeaders) > 0 {
+		transport = &rewriteTransport{
+			base:      transport,
+			rewriters: i.urlRewriters,
+			headers:   i.hostHeaders,
+		}
+	}
+
+	apiClient = &http.Client{
+		Transport:     transport,
+		CheckRedirect: base.CheckRedirect,
+		Jar:           base.Jar,
+		Timeout:       base.Timeout,
+	}
+
+	downloadClient = &http.Client{
+		Transport:     transport,
+		CheckRedirect: base.CheckRedirect,
+		Jar:           base.Jar,
+		Timeout:       base.Timeout,
+	}
+
+	return apiClient, downloadClient
+}
@@ -0,0 +1,132 @@
+package github_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"runtime"
+	"sync/atomic"
+	"testing"
+
+	goGitHub "github.com/google/go-github/v35/github"
+	"github.com/nhatthm/aferoassert"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	github "github.com/nhatthm/plugin-registry-github"
+)
+
+func TestRewriteURLPrefix(t *testing.T) {
+	t.Parallel()
+
+	rewrite := github.RewriteURLPrefix("https://objects.githubusercontent.com/", "https://proxy.example.com/github/")
+
+	u, ok := rewrite(&url.URL{Scheme: "https", Host: "objects.githubusercontent.com", Path: "/asset/42", RawQuery: "token=1"})
+
+	assert.True(t, ok)
+	assert.Equal(t, "https://proxy.example.com/github/asset/42?token=1", u.String())
+
+	u, ok = rewrite(&url.URL{Scheme: "https", Host: "api.github.com", Path: "/repos"})
+
+	assert.False(t, ok)
+	assert.Equal(t, "https://api.github.com/repos", u.String())
+}
+
+func TestIntegrationInstaller_Install_URLRewrite(t *testing.T) {
+	t.Parallel()
+
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer proxy-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		switch r.URL.Path {
+		case "/github-api/repos/owner/my-plugin/releases/tags/v1.4.2":
+			release := newReleaseWithArtifactAndContentType("v1.4.2", "my-plugin.tar.gz", "application/gzip")
+			release.Body = stringPtrf("```plugin-registry\nname: my-plugin\nartifacts:\n  %s/%s:\n    file: my-plugin.tar.gz\n```",
+				runtime.GOOS, runtime.GOARCH)
+
+			_ = json.NewEncoder(w).Encode(release) // nolint: errcheck
+
+		case "/github-api/repos/owner/my-plugin/releases/assets/42":
+			http.Redirect(w, r, "https://objects.githubusercontent.com/assets/42?token=secret", http.StatusFound)
+
+		case "/github-assets/assets/42":
+			http.ServeFile(w, r, "resources/fixtures/gzip/my-plugin.tar.gz")
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	t.Cleanup(proxy.Close)
+
+	proxyURL, err := url.Parse(proxy.URL)
+	require.NoError(t, err)
+
+	apiURL, err := url.Parse("https://api.github.com/")
+	require.NoError(t, err)
+
+	osFs := afero.NewOsFs()
+	dest := t.TempDir()
+
+	i := github.NewInstaller(
+		github.WithFs(osFs),
+		github.WithBaseURL(apiURL),
+		github.WithMetadataSources(github.MetadataFromReleaseBody),
+		github.WithURLRewrite("https://api.github.com/", proxy.URL+"/github-api/"),
+		github.WithURLRewrite("https://objects.githubusercontent.com/", proxy.URL+"/github-assets/"),
+		github.WithHostHeader(proxyURL.Host, "Authorization", "Bearer proxy-token"),
+	)
+
+	result, err := i.Install(context.Background(), dest, "github.com/owner/my-plugin@v1.4.2")
+	require.NoError(t, err)
+
+	assert.Equal(t, "my-plugin", result.Name)
+
+	aferoassert.FileContent(t, osFs, filepath.Join(dest, result.Name, result.Name), "#!/bin/bash\n")
+}
+
+func TestNewInstaller_HTTPClient(t *testing.T) {
+	t.Parallel()
+
+	var called int32
+
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Custom") == "custom" {
+			atomic.StoreInt32(&called, 1)
+		}
+
+		_ = json.NewEncoder(w).Encode(goGitHub.RepositoryRelease{}) // nolint: errcheck
+	}))
+
+	t.Cleanup(svr.Close)
+
+	baseURL, err := url.Parse(svr.URL + "/")
+	require.NoError(t, err)
+
+	c := &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		r.Header.Set("X-Custom", "custom")
+
+		return http.DefaultTransport.RoundTrip(r)
+	})}
+
+	i := github.NewInstaller(github.WithBaseURL(baseURL), github.WithHTTPClient(c))
+
+	_, err = i.Resolve(context.Background(), "github.com/owner/my-plugin")
+
+	assert.EqualError(t, err, "latest release has no tag name")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&called))
+}
+
+type roundTripperFunc func(r *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
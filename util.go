@@ -3,8 +3,10 @@ package github
 import (
 	"errors"
 	"io"
+	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/google/go-github/v35/github"
 	"github.com/nhatthm/plugin-registry/plugin"
@@ -16,14 +18,24 @@ import (
 var ErrArtifactNotFound = errors.New("artifact not found")
 
 func writeFile(fs afero.Fs, path string, r io.Reader) error {
-	f, err := fs.OpenFile(path, os.O_CREATE|os.O_RDWR, os.FileMode(0o644))
+	return writeFileWith(fs, path, r, os.O_CREATE|os.O_TRUNC|os.O_RDWR, 0o644, io.Copy)
+}
+
+// writeTempFile creates a new file that only the current user could read and write.
+func writeTempFile(fs afero.Fs, path string, r io.Reader, copyFn copyFunc) error {
+	return writeFileWith(fs, path, r, os.O_CREATE|os.O_EXCL|os.O_RDWR, 0o600, copyFn)
+}
+
+// writeFileWith writes the content to the file using the copyFunc, for example to stop when the context is done.
+func writeFileWith(fs afero.Fs, path string, r io.Reader, flag int, perm os.FileMode, copyFn copyFunc) error {
+	f, err := fs.OpenFile(path, flag, perm)
 	if err != nil {
 		return err
 	}
 
 	defer f.Close() // nolint: errcheck
 
-	if _, err := io.Copy(f, r); err != nil {
+	if _, err := copyFn(f, r); err != nil {
 		return err
 	}
 
@@ -34,6 +46,32 @@ func writeFile(fs afero.Fs, path string, r io.Reader) error {
 	return nil
 }
 
+// movePath moves the file or the dir to the new path. A dir is moved file by file, because not every afero.Fs moves the
+// content of a renamed dir.
+func movePath(fs afero.Fs, from, to string) error {
+	err := afero.Walk(fs, from, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(from, path)
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			return fs.MkdirAll(filepath.Join(to, rel), info.Mode().Perm())
+		}
+
+		return fs.Rename(path, filepath.Join(to, rel))
+	})
+	if err != nil {
+		return err
+	}
+
+	return fs.RemoveAll(from)
+}
+
 func loadMetadata(r io.Reader) (*plugin.Plugin, error) {
 	var p plugin.Plugin
 
@@ -51,7 +89,7 @@ func loadMetadata(r io.Reader) (*plugin.Plugin, error) {
 }
 
 func writeMetadata(fs afero.Fs, path string, p *plugin.Plugin) error {
-	return writeFile(fs, filepath.Join(path, plugin.MetadataFile), newYamlReader(p))
+	return writeTempFile(fs, filepath.Join(path, plugin.MetadataFile), newYamlReader(p), io.Copy)
 }
 
 func findAsset(r *github.RepositoryRelease, file string) (*github.ReleaseAsset, error) {
@@ -68,6 +106,23 @@ func findAsset(r *github.RepositoryRelease, file string) (*github.ReleaseAsset,
 	return nil, ErrArtifactNotFound
 }
 
+// isNotFound checks whether the error from the github api means that the resource does not exist.
+func isNotFound(err error) bool {
+	if errors.Is(err, os.ErrNotExist) {
+		return true
+	}
+
+	var errResp *github.ErrorResponse
+
+	if errors.As(err, &errResp) && errResp.Response != nil {
+		return errResp.Response.StatusCode == http.StatusNotFound
+	}
+
+	// github.RepositoriesService.DownloadContents() does not return an ErrorResponse when the file is not in the
+	// directory listing.
+	return strings.HasPrefix(err.Error(), "No file named ")
+}
+
 func chmod(fs afero.Fs, contentType *string, path string, fileMode os.FileMode) error {
 	if contentType == nil {
 		return nil
@@ -82,3 +137,13 @@ func chmod(fs afero.Fs, contentType *string, path string, fileMode os.FileMode)
 		return nil
 	}
 }
+
+// isSubPath checks whether the path is the parent or inside the parent.
+func isSubPath(parent, path string) bool {
+	rel, err := filepath.Rel(parent, path)
+	if err != nil {
+		return false
+	}
+
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
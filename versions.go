@@ -0,0 +1,183 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/bool64/ctxd"
+	"github.com/google/go-github/v35/github"
+)
+
+// ErrListReleasesNotSupported indicates that the repository service could not list the releases.
+var ErrListReleasesNotSupported = errors.New("repository service could not list releases")
+
+// Version is an installable version of a plugin.
+type Version struct {
+	// Version is the normalized version, without the v prefix.
+	Version     string
+	Tag         string
+	Prerelease  bool
+	PublishedAt time.Time
+	// HasRuntimeAsset tells whether the release has an artifact for the target platform, that is the current runtime by
+	// default. It is only checked with WithVersionsRuntimeAssetCheck().
+	HasRuntimeAsset bool
+	// RuntimeAssetError is the error of the check, when the metadata of the release could not be loaded.
+	RuntimeAssetError error
+}
+
+// ListVersions lists the versions of a plugin, the newest first. The tags that are not semantic versions come last, in
+// the order of the github api.
+//
+// With WithVersionsRuntimeAssetCheck(), the metadata of every release is fetched to find out whether the release has an
+// artifact for the target platform.
+func (i *Installer) ListVersions(ctx context.Context, source string) ([]Version, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	owner, repository, _, err := parseURL(source)
+	if err != nil {
+		return nil, parseError(err, source)
+	}
+
+	ctx = withSource(ctx, source, owner, repository)
+
+	releases, err := i.listReleases(ctx, owner, repository)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]Version, 0, len(releases))
+
+	for _, r := range releases {
+		v := Version{
+			Version:     trimVersion(r.GetTagName()),
+			Tag:         r.GetTagName(),
+			Prerelease:  r.GetPrerelease(),
+			PublishedAt: r.GetPublishedAt().Time,
+		}
+
+		if i.versionsAssetCheck {
+			v.HasRuntimeAsset, v.RuntimeAssetError = i.hasRuntimeAsset(ctx, owner, repository, r)
+		}
+
+		versions = append(versions, v)
+	}
+
+	return versions, nil
+}
+
+// listReleases lists all the published releases of a repository, sorted by semantic version, the newest first.
+func (i *Installer) listReleases(ctx context.Context, owner, repository string) ([]*github.RepositoryRelease, error) {
+	s := i.releaseService()
+	if s == nil {
+		return nil, ctxd.WrapError(ctx, ErrListReleasesNotSupported, "could not list releases")
+	}
+
+	var releases []*github.RepositoryRelease
+
+	opts := &github.ListOptions{PerPage: 100}
+
+	for {
+		page, resp, err := i.listReleasesPage(ctx, s, owner, repository, opts)
+		if err != nil {
+			return nil, ctxd.WrapError(ctx, err, "could not list releases")
+		}
+
+		for _, r := range page {
+			if r.GetDraft() || r.GetTagName() == "" {
+				continue
+			}
+
+			releases = append(releases, r)
+		}
+
+		if resp == nil || resp.NextPage == 0 {
+			break
+		}
+
+		opts.Page = resp.NextPage
+	}
+
+	sortReleases(releases)
+
+	i.logger.Debug(ctx, "listed releases", "count", len(releases))
+
+	return releases, nil
+}
+
+func (i *Installer) listReleasesPage(
+	ctx context.Context,
+	s ReleaseService,
+	owner, repository string,
+	opts *github.ListOptions,
+) ([]*github.RepositoryRelease, *github.Response, error) {
+	apiCtx, cancel := i.phaseContext(ctx, PhaseAPI)
+	defer cancel()
+
+	page, resp, err := s.ListReleases(apiCtx, owner, repository, opts)
+
+	i.observeAPICall(EndpointListReleases, resp)
+
+	return page, resp, i.phaseError(ctx, apiCtx, PhaseAPI, err)
+}
+
+// releaseService returns the configured release service, or the repository service if it could list the releases.
+func (i *Installer) releaseService() ReleaseService {
+	if i.releases != nil {
+		return i.releases
+	}
+
+	if s, ok := i.service.(ReleaseService); ok {
+		return s
+	}
+
+	return nil
+}
+
+func (i *Installer) hasRuntimeAsset(ctx context.Context, owner, repository string, r *github.RepositoryRelease) (bool, error) {
+	p, _, err := i.loadReleaseMetadata(ctx, owner, repository, r)
+	if err != nil {
+		i.logger.Warn(ctx, "could not check runtime asset", "tag", r.GetTagName(), "error", err)
+
+		return false, err
+	}
+
+	if _, _, err := i.findTargetAsset(ctx, p, r); err != nil {
+		if errors.Is(err, ErrArtifactNotFound) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
+
+// sortReleases sorts the releases by semantic version, the newest first. The releases that do not have a semantic
+// version tag are moved to the end and keep their order.
+func sortReleases(releases []*github.RepositoryRelease) {
+	versions := make(map[*github.RepositoryRelease]*semver.Version, len(releases))
+
+	for _, r := range releases {
+		if v, err := semver.NewVersion(r.GetTagName()); err == nil {
+			versions[r] = v
+		}
+	}
+
+	sort.SliceStable(releases, func(i, j int) bool {
+		vi, vj := versions[releases[i]], versions[releases[j]]
+
+		switch {
+		case vi == nil:
+			return false
+
+		case vj == nil:
+			return true
+		}
+
+		return vi.GreaterThan(vj)
+	})
+}
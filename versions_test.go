@@ -0,0 +1,151 @@
+package github_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"testing"
+	"time"
+
+	goGitHub "github.com/google/go-github/v35/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	github "github.com/nhatthm/plugin-registry-github"
+	"github.com/nhatthm/plugin-registry-github/mock/service"
+)
+
+func TestInstaller_ListVersions(t *testing.T) {
+	t.Parallel()
+
+	day := func(d int) time.Time {
+		return time.Date(2021, time.January, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	metadata := withBody(fmt.Sprintf("```plugin-registry\nname: my-plugin\nartifacts:\n  %s/%s:\n    file: my-plugin-${version}.tar.gz\n```",
+		runtime.GOOS, runtime.GOARCH))
+
+	testCases := []struct {
+		scenario       string
+		source         string
+		mockService    service.ReleaseServiceMocker
+		options        []github.Option
+		expectedResult []github.Version
+		// expectedAssetErrors are the errors of the runtime asset check, by tag.
+		expectedAssetErrors map[string]string
+		expectedError       string
+	}{
+		{
+			scenario:      "could not parse url",
+			source:        "/tmp/plugin.zip",
+			expectedError: "could not parse url: not a github url",
+		},
+		{
+			scenario:      "service could not list releases",
+			source:        "github.com/owner/my-plugin",
+			expectedError: "could not list releases: repository service could not list releases",
+		},
+		{
+			scenario: "could not list releases",
+			source:   "github.com/owner/my-plugin",
+			mockService: service.MockReleaseService(func(s *service.ReleaseService) {
+				s.On("ListReleases", mock.Anything, "owner", "my-plugin", mock.Anything).
+					Return(nil, nil, errors.New("list error"))
+			}),
+			expectedError: "could not list releases: list error",
+		},
+		{
+			scenario: "success",
+			source:   "github.com/owner/my-plugin@latest",
+			mockService: service.MockReleaseService(func(s *service.ReleaseService) {
+				s.On("ListReleases", mock.Anything, "owner", "my-plugin", &goGitHub.ListOptions{PerPage: 100}).Once().
+					Return([]*goGitHub.RepositoryRelease{
+						newRelease("nightly", withPublishedAt(day(7)), metadata, withAssets("my-plugin-nightly.tar.gz")),
+						newRelease("v1.2.0", withPublishedAt(day(2)), metadata, withAssets("my-plugin-1.2.0.tar.gz")),
+						newRelease("v2.0.0", withPublishedAt(day(6)), metadata, withAssets("my-plugin-2.0.0.tar.gz"), withDraft()),
+					}, &goGitHub.Response{NextPage: 2}, nil)
+
+				s.On("ListReleases", mock.Anything, "owner", "my-plugin", &goGitHub.ListOptions{PerPage: 100, Page: 2}).Once().
+					Return([]*goGitHub.RepositoryRelease{
+						newRelease("v1.10.0", withPublishedAt(day(4)), metadata, withAssets("my-plugin.zip")),
+						newRelease("v1.10.0-rc.1", withPublishedAt(day(5)), metadata, withAssets("my-plugin-1.10.0-rc.1.tar.gz"), withPrerelease()),
+						newRelease("1.9.0", withPublishedAt(day(3)), metadata, withAssets("my-plugin-1.9.0.tar.gz")),
+					}, &goGitHub.Response{}, nil)
+			}),
+			expectedResult: []github.Version{
+				{Version: "1.10.0", Tag: "v1.10.0", PublishedAt: day(4)},
+				{Version: "1.10.0-rc.1", Tag: "v1.10.0-rc.1", Prerelease: true, PublishedAt: day(5)},
+				{Version: "1.9.0", Tag: "1.9.0", PublishedAt: day(3)},
+				{Version: "1.2.0", Tag: "v1.2.0", PublishedAt: day(2)},
+				{Version: "nightly", Tag: "nightly", PublishedAt: day(7)},
+			},
+		},
+		{
+			scenario: "runtime asset check",
+			source:   "github.com/owner/my-plugin",
+			mockService: service.MockReleaseService(func(s *service.ReleaseService) {
+				s.On("ListReleases", mock.Anything, "owner", "my-plugin", &goGitHub.ListOptions{PerPage: 100}).Once().
+					Return([]*goGitHub.RepositoryRelease{
+						newRelease("v1.2.0", withPublishedAt(day(2)), metadata, withAssets("my-plugin-1.2.0.tar.gz")),
+						newRelease("v1.1.0", withPublishedAt(day(1)), metadata, withAssets("my-plugin.zip")),
+						newRelease("v1.0.0", withPublishedAt(day(0)), withAssets("my-plugin-1.0.0.tar.gz")),
+					}, &goGitHub.Response{}, nil)
+			}),
+			options: []github.Option{github.WithVersionsRuntimeAssetCheck()},
+			expectedResult: []github.Version{
+				{Version: "1.2.0", Tag: "v1.2.0", PublishedAt: day(2), HasRuntimeAsset: true},
+				{Version: "1.1.0", Tag: "v1.1.0", PublishedAt: day(1)},
+				{Version: "1.0.0", Tag: "v1.0.0", PublishedAt: day(0)},
+			},
+			expectedAssetErrors: map[string]string{
+				"v1.0.0": "could not get plugin metadata: plugin metadata not found",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			options := []github.Option{
+				github.WithService(service.NoMockRepositoryService(t)),
+				github.WithMetadataSources(github.MetadataFromReleaseBody),
+			}
+
+			if tc.mockService != nil {
+				options = append(options, github.WithReleaseService(tc.mockService(t)))
+			}
+
+			options = append(options, tc.options...)
+
+			i := github.NewInstaller(options...)
+
+			result, err := i.ListVersions(context.Background(), tc.source)
+
+			assetErrors := make(map[string]string)
+
+			for k := range result {
+				if result[k].RuntimeAssetError != nil {
+					assetErrors[result[k].Tag] = result[k].RuntimeAssetError.Error()
+					result[k].RuntimeAssetError = nil
+				}
+			}
+
+			assert.Equal(t, tc.expectedResult, result)
+
+			if tc.expectedAssetErrors == nil {
+				assert.Empty(t, assetErrors)
+			} else {
+				assert.Equal(t, tc.expectedAssetErrors, assetErrors)
+			}
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}